@@ -8,9 +8,18 @@ package rkentry
 import (
 	"context"
 	"encoding/json"
+	"go.uber.org/zap"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
 )
 
+// cgroupRoot is mount point of cgroup file system in container
+var cgroupRoot = "/sys/fs/cgroup"
+
 // bootConfigAppInfo is config of application's basic information.
 type bootConfigAppInfo struct {
 	App struct {
@@ -21,6 +30,11 @@ type bootConfigAppInfo struct {
 		HomeUrl     string   `yaml:"homeUrl" json:"homeUrl"`
 		DocsUrl     []string `yaml:"docsUrl" json:"docsUrl"`
 		Maintainers []string `yaml:"maintainers" json:"maintainers"`
+		RequiredEnv []string `yaml:"requiredEnv" json:"requiredEnv"`
+		MaxProcs    struct {
+			Enabled bool `yaml:"enabled" json:"enabled"`
+			Min     int  `yaml:"min" json:"min"`
+		} `yaml:"maxProcs" json:"maxProcs"`
 	} `yaml:"app"`
 }
 
@@ -36,6 +50,8 @@ type appInfoEntry struct {
 	HomeUrl          string   `json:"-" yaml:"-"`
 	DocsUrl          []string `json:"-" yaml:"-"`
 	Maintainers      []string `json:"-" yaml:"-"`
+	maxProcs         bool     `json:"-" yaml:"-"`
+	maxProcsMin      int      `json:"-" yaml:"-"`
 }
 
 // appInfoEntryDefault generate a AppInfo entry with default fields.
@@ -78,6 +94,10 @@ func registerAppInfoEntryYAML(raw []byte) map[string]Entry {
 	entry.HomeUrl = config.App.HomeUrl
 	entry.DocsUrl = config.App.DocsUrl
 	entry.Maintainers = config.App.Maintainers
+	// checked by BootstrapAll
+	RequireEnv(config.App.RequiredEnv...)
+	entry.maxProcs = config.App.MaxProcs.Enabled
+	entry.maxProcsMin = config.App.MaxProcs.Min
 
 	if entry.Keywords == nil {
 		entry.Keywords = make([]string, 0)
@@ -100,8 +120,12 @@ func registerAppInfoEntryYAML(raw []byte) map[string]Entry {
 	return res
 }
 
-// Bootstrap is noop function.
-func (entry *appInfoEntry) Bootstrap(context.Context) {}
+// Bootstrap set GOMAXPROCS with CPU limit of cgroup if app.maxProcs.enabled is true.
+func (entry *appInfoEntry) Bootstrap(context.Context) {
+	if entry.maxProcs {
+		setMaxProcsFromCgroup(entry.maxProcsMin)
+	}
+}
 
 // Interrupt is noop function.
 func (entry *appInfoEntry) Interrupt(context.Context) {}
@@ -148,3 +172,74 @@ func (entry *appInfoEntry) MarshalJSON() ([]byte, error) {
 func (entry *appInfoEntry) UnmarshalJSON([]byte) error {
 	return nil
 }
+
+// setMaxProcsFromCgroup set GOMAXPROCS with CPU limit of cgroup rounded down, at least min and 1.
+// Environment variable of GOMAXPROCS takes precedence.
+func setMaxProcsFromCgroup(min int) {
+	logger := GlobalAppCtx.GetLoggerEntryDefault()
+
+	if v, ok := os.LookupEnv("GOMAXPROCS"); ok {
+		logger.Info("GOMAXPROCS is set with environment variable, skip cgroup", zap.String("maxProcs", v))
+		return
+	}
+
+	quota, ok := getCgroupCPUQuota()
+	if !ok {
+		logger.Info("CPU limit of cgroup is missing, keep GOMAXPROCS", zap.Int("maxProcs", runtime.GOMAXPROCS(0)))
+		return
+	}
+
+	procs := int(math.Floor(quota))
+	if procs < min {
+		procs = min
+	}
+	if procs < 1 {
+		procs = 1
+	}
+
+	prev := runtime.GOMAXPROCS(procs)
+	logger.Info("Set GOMAXPROCS with CPU limit of cgroup",
+		zap.Int("maxProcs", procs),
+		zap.Int("prevMaxProcs", prev),
+		zap.Float64("cpuQuota", quota))
+}
+
+// getCgroupCPUQuota returns CPU limit of cgroup v2 or v1, false if unlimited or missing
+func getCgroupCPUQuota() (float64, bool) {
+	// cgroup v2, format of "$MAX $PERIOD", $MAX is "max" if unlimited
+	if raw, err := os.ReadFile(filepath.Join(cgroupRoot, "cpu.max")); err == nil {
+		fields := strings.Fields(string(raw))
+		if len(fields) != 2 || fields[0] == "max" {
+			return 0, false
+		}
+
+		return getCPUQuota(fields[0], fields[1])
+	}
+
+	// cgroup v1, quota is -1 if unlimited
+	quota, err := os.ReadFile(filepath.Join(cgroupRoot, "cpu", "cpu.cfs_quota_us"))
+	if err != nil {
+		return 0, false
+	}
+	period, err := os.ReadFile(filepath.Join(cgroupRoot, "cpu", "cpu.cfs_period_us"))
+	if err != nil {
+		return 0, false
+	}
+
+	return getCPUQuota(strings.TrimSpace(string(quota)), strings.TrimSpace(string(period)))
+}
+
+// getCPUQuota returns quota divided by period, false if either of them is invalid
+func getCPUQuota(quota, period string) (float64, bool) {
+	q, err := strconv.ParseFloat(quota, 64)
+	if err != nil || q <= 0 {
+		return 0, false
+	}
+
+	p, err := strconv.ParseFloat(period, 64)
+	if err != nil || p <= 0 {
+		return 0, false
+	}
+
+	return q / p, true
+}
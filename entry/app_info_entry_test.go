@@ -6,7 +6,11 @@
 package rkentry
 
 import (
+	"context"
 	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 )
 
@@ -79,3 +83,39 @@ func assertNotPanic(t *testing.T) {
 		assert.True(t, true)
 	}
 }
+
+func TestAppInfoEntry_Bootstrap_WithMaxProcs(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+	defer func(root string) { cgroupRoot = root }(cgroupRoot)
+	defer func() { GlobalAppCtx.appInfoEntry = appInfoEntryDefault() }()
+
+	bootStr := `
+---
+app:
+  maxProcs:
+    enabled: true
+    min: 2
+`
+	entry := registerAppInfoEntryYAML([]byte(bootStr))[appInfoEntryName].(*appInfoEntry)
+	assert.True(t, entry.maxProcs)
+
+	// cgroup v2
+	cgroupRoot = t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(cgroupRoot, "cpu.max"), []byte("300000 100000\n"), os.ModePerm))
+	entry.Bootstrap(context.TODO())
+	assert.Equal(t, 3, runtime.GOMAXPROCS(0))
+
+	// cgroup v1 with quota lower than min
+	cgroupRoot = t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Join(cgroupRoot, "cpu"), os.ModePerm))
+	assert.Nil(t, os.WriteFile(filepath.Join(cgroupRoot, "cpu", "cpu.cfs_quota_us"), []byte("50000\n"), os.ModePerm))
+	assert.Nil(t, os.WriteFile(filepath.Join(cgroupRoot, "cpu", "cpu.cfs_period_us"), []byte("100000\n"), os.ModePerm))
+	entry.Bootstrap(context.TODO())
+	assert.Equal(t, 2, runtime.GOMAXPROCS(0))
+
+	// unlimited
+	cgroupRoot = t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(cgroupRoot, "cpu.max"), []byte("max 100000\n"), os.ModePerm))
+	entry.Bootstrap(context.TODO())
+	assert.Equal(t, 2, runtime.GOMAXPROCS(0))
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// BaseEntry implements methods of Entry with standard fields, custom entry could embed it
+// and override only what's custom.
+//
+// MarshalJSON and String output name, type and description of entry, followed by fields returned by function
+// provided with SetMarshalFields. Values of sensitive keys like password, secret and token will be redacted.
+//
+// Example:
+//
+//	type MyEntry struct {
+//	    rkentry.BaseEntry
+//	    Key string
+//	}
+//
+//	func RegisterMyEntry() *MyEntry {
+//	    entry := &MyEntry{
+//	        BaseEntry: rkentry.NewBaseEntry("my-entry", "MyEntry", "Description of my entry."),
+//	    }
+//	    entry.SetMarshalFields(func() map[string]interface{} {
+//	        return map[string]interface{}{
+//	            "key": entry.Key,
+//	        }
+//	    })
+//	    return entry
+//	}
+type BaseEntry struct {
+	EntryName        string                        `json:"-" yaml:"-"`
+	EntryType        string                        `json:"-" yaml:"-"`
+	EntryDescription string                        `json:"-" yaml:"-"`
+	marshalFields    func() map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// NewBaseEntry creates BaseEntry with name, type and description
+func NewBaseEntry(name, entryType, description string) BaseEntry {
+	return BaseEntry{
+		EntryName:        name,
+		EntryType:        entryType,
+		EntryDescription: description,
+	}
+}
+
+// Bootstrap noop, override it if needed
+func (entry *BaseEntry) Bootstrap(context.Context) {}
+
+// Interrupt noop, override it if needed
+func (entry *BaseEntry) Interrupt(context.Context) {}
+
+// GetName returns name of entry
+func (entry *BaseEntry) GetName() string {
+	return entry.EntryName
+}
+
+// GetType returns type of entry
+func (entry *BaseEntry) GetType() string {
+	return entry.EntryType
+}
+
+// GetDescription returns description of entry
+func (entry *BaseEntry) GetDescription() string {
+	return entry.EntryDescription
+}
+
+// SetMarshalFields provide function which returns custom fields of entry for MarshalJSON and String
+func (entry *BaseEntry) SetMarshalFields(f func() map[string]interface{}) {
+	entry.marshalFields = f
+}
+
+// String returns string value of entry
+func (entry *BaseEntry) String() string {
+	bytes, _ := entry.MarshalJSON()
+	return string(bytes)
+}
+
+// MarshalJSON marshal entry with standard fields and custom fields, sensitive values are redacted
+func (entry *BaseEntry) MarshalJSON() ([]byte, error) {
+	var fields map[string]interface{}
+	if entry.marshalFields != nil {
+		fields = entry.marshalFields()
+	}
+
+	return MarshalEntryJSON(entry, fields)
+}
+
+// UnmarshalJSON not supported
+func (entry *BaseEntry) UnmarshalJSON([]byte) error {
+	return nil
+}
+
+// MarshalEntryJSON marshal name, type and description of entry with fields, values of sensitive keys
+// in fields will be redacted recursively. Standard fields could not be overridden by fields.
+//
+// It could be used in MarshalJSON of entries which don't embed BaseEntry.
+func MarshalEntryJSON(entry Entry, fields map[string]interface{}) ([]byte, error) {
+	m := make(map[string]interface{})
+
+	if len(fields) > 0 {
+		// values like structs are converted into maps so that they could be redacted
+		raw, err := json.Marshal(fields)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(raw, &m); err != nil {
+			return nil, err
+		}
+		m = redactSecrets(m).(map[string]interface{})
+	}
+
+	m["name"] = entry.GetName()
+	m["type"] = entry.GetType()
+	m["description"] = entry.GetDescription()
+
+	return json.Marshal(m)
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type baseEntryMock struct {
+	BaseEntry
+	Key      string
+	Password string
+}
+
+func TestBaseEntry(t *testing.T) {
+	entry := &baseEntryMock{
+		BaseEntry: NewBaseEntry("ut-base", "ut-type", "ut-description"),
+		Key:       "ut-key",
+		Password:  "ut-password",
+	}
+
+	var _ Entry = entry
+	entry.Bootstrap(context.TODO())
+	entry.Interrupt(context.TODO())
+	assert.Equal(t, "ut-base", entry.GetName())
+	assert.Equal(t, "ut-type", entry.GetType())
+	assert.Equal(t, "ut-description", entry.GetDescription())
+	assert.Nil(t, entry.UnmarshalJSON(nil))
+
+	// without custom fields
+	m := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal([]byte(entry.String()), &m))
+	assert.Equal(t, map[string]interface{}{
+		"name":        "ut-base",
+		"type":        "ut-type",
+		"description": "ut-description",
+	}, m)
+
+	// with custom fields
+	entry.SetMarshalFields(func() map[string]interface{} {
+		return map[string]interface{}{
+			"key":  entry.Key,
+			"name": "ut-override",
+			"auth": map[string]interface{}{
+				"password": entry.Password,
+			},
+		}
+	})
+	bytes, err := json.Marshal(entry)
+	assert.Nil(t, err)
+	m = map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(bytes, &m))
+	assert.Equal(t, map[string]interface{}{
+		"name":        "ut-base",
+		"type":        "ut-type",
+		"description": "ut-description",
+		"key":         "ut-key",
+		"auth": map[string]interface{}{
+			"password": "******",
+		},
+	}, m)
+	assert.JSONEq(t, string(bytes), entry.String())
+}
+
+func TestMarshalEntryJSON(t *testing.T) {
+	bytes, err := MarshalEntryJSON(&EntryMock{Name: "ut-mock"}, map[string]interface{}{
+		"apiKey": "ut-api-key",
+	})
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"name":"ut-mock","type":"mock","description":"","apiKey":"******"}`, string(bytes))
+
+	// unsupported value
+	_, err = MarshalEntryJSON(&EntryMock{Name: "ut-mock"}, map[string]interface{}{
+		"ch": make(chan int),
+	})
+	assert.NotNil(t, err)
+}
@@ -7,16 +7,63 @@ package rkentry
 
 import (
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
 	"embed"
+	"encoding/hex"
 	"encoding/json"
 	"encoding/pem"
+	"fmt"
+	"strings"
 	"sync"
 )
 
+// CertEntryOption option for CertEntry
+type CertEntryOption func(entry *CertEntry)
+
+// WithCertPEM provide server cert as PEM block in memory.
+// It takes precedence over certPemPath and certPem in boot config.
+func WithCertPEM(certPem []byte) CertEntryOption {
+	return func(entry *CertEntry) {
+		entry.certPem = certPem
+	}
+}
+
+// WithKeyPEM provide server key as PEM block in memory.
+// It takes precedence over keyPemPath and keyPem in boot config.
+func WithKeyPEM(keyPem []byte) CertEntryOption {
+	return func(entry *CertEntry) {
+		entry.keyPem = keyPem
+	}
+}
+
+// WithMinTLSVersion provide min TLS version of tls.Config returned by GetTLSConfig, like tls.VersionTLS12.
+// It takes precedence over minVersion in boot config.
+func WithMinTLSVersion(version uint16) CertEntryOption {
+	return func(entry *CertEntry) {
+		entry.minVersion = version
+	}
+}
+
+// WithMaxTLSVersion provide max TLS version of tls.Config returned by GetTLSConfig, like tls.VersionTLS13.
+// It takes precedence over maxVersion in boot config.
+func WithMaxTLSVersion(version uint16) CertEntryOption {
+	return func(entry *CertEntry) {
+		entry.maxVersion = version
+	}
+}
+
+// WithCipherSuites provide cipher suites of tls.Config returned by GetTLSConfig, like tls.TLS_AES_128_GCM_SHA256.
+// It takes precedence over cipherSuites in boot config.
+func WithCipherSuites(suites ...uint16) CertEntryOption {
+	return func(entry *CertEntry) {
+		entry.cipherSuites = suites
+	}
+}
+
 // RegisterCertEntry create cert entry with options.
-func RegisterCertEntry(boot *BootCert) []*CertEntry {
+func RegisterCertEntry(boot *BootCert, opts ...CertEntryOption) []*CertEntry {
 	res := make([]*CertEntry, 0)
 
 	// filter out based domain
@@ -54,9 +101,62 @@ func RegisterCertEntry(boot *BootCert) []*CertEntry {
 			caPath:           cert.CAPath,
 			keyPemPath:       cert.KeyPemPath,
 			certPemPath:      cert.CertPemPath,
+			checksum:         cert.Checksum,
 			embedFS:          GlobalAppCtx.GetEmbedFS(CertEntryType, cert.Name),
 		}
 
+		if len(cert.CertPem) > 0 {
+			entry.certPem = []byte(cert.CertPem)
+		}
+
+		if len(cert.KeyPem) > 0 {
+			entry.keyPem = []byte(cert.KeyPem)
+		}
+
+		// parse TLS versions and cipher suites, options will override them
+		var err error
+		if entry.minVersion, err = parseTLSVersion(cert.MinVersion); err != nil {
+			ShutdownWithError(fmt.Errorf("invalid minVersion, entry:%s, %v", entry.entryName, err))
+		}
+		if entry.maxVersion, err = parseTLSVersion(cert.MaxVersion); err != nil {
+			ShutdownWithError(fmt.Errorf("invalid maxVersion, entry:%s, %v", entry.entryName, err))
+		}
+		if entry.cipherSuites, err = parseCipherSuites(cert.CipherSuites); err != nil {
+			ShutdownWithError(fmt.Errorf("invalid cipherSuites, entry:%s, %v", entry.entryName, err))
+		}
+
+		for i := range opts {
+			opts[i](entry)
+		}
+
+		// parse key pair from PEM in memory, so that mismatch could be found at registration
+		if len(entry.certPem) > 0 || len(entry.keyPem) > 0 {
+			if len(entry.certPem) < 1 || len(entry.keyPem) < 1 {
+				ShutdownWithError(fmt.Errorf("both cert and key PEM are required, entry:%s", entry.entryName))
+			}
+
+			keyPair, err := tls.X509KeyPair(entry.certPem, entry.keyPem)
+			if err != nil {
+				ShutdownWithError(fmt.Errorf("failed to parse key pair from PEM, entry:%s, %v", entry.entryName, err))
+			}
+
+			entry.Certificate = &keyPair
+		}
+
+		// verify files early, they will be verified again while bootstrapping
+		for _, f := range entry.checksumFiles() {
+			if len(f.expected) > 0 && len(f.path) > 0 {
+				entry.readFileWithChecksum(f.path, f.expected)
+			}
+		}
+
+		if err := GlobalAppCtx.SetEntryTimeout(CertEntryType, cert.Name, &cert.BootTimeout); err != nil {
+			ShutdownWithError(err)
+		}
+		if err := GlobalAppCtx.SetEntryFailurePolicy(CertEntryType, cert.Name, &cert.BootFailurePolicy); err != nil {
+			ShutdownWithError(err)
+		}
+
 		GlobalAppCtx.AddEntry(entry)
 		res = append(res, entry)
 	}
@@ -93,6 +193,27 @@ type BootCertE struct {
 	CAPath      string `yaml:"caPath" json:"caPath"`
 	CertPemPath string `yaml:"certPemPath" json:"certPemPath"`
 	KeyPemPath  string `yaml:"keyPemPath" json:"keyPemPath"`
+	CertPem     string `yaml:"certPem" json:"certPem"`
+	KeyPem      string `yaml:"keyPem" json:"keyPem"`
+	// MinVersion and MaxVersion are TLS versions like 1.2 or TLS1.2
+	MinVersion string `yaml:"minVersion" json:"minVersion"`
+	MaxVersion string `yaml:"maxVersion" json:"maxVersion"`
+	// CipherSuites are names of cipher suites like TLS_AES_128_GCM_SHA256
+	CipherSuites []string `yaml:"cipherSuites" json:"cipherSuites"`
+	// Checksum are expected SHA-256 checksums of files
+	Checksum          BootCertChecksum `yaml:"checksum" json:"checksum"`
+	BootTimeout       `yaml:",inline" json:",inline" mapstructure:",squash"`
+	BootFailurePolicy `yaml:",inline" json:",inline" mapstructure:",squash"`
+}
+
+// BootCertChecksum expected SHA-256 checksums of files in CertEntry, as hex string with optional prefix of sha256:.
+//
+// Files with checksum are verified at registration and at bootstrap, so that partially written or
+// corrupted files are rejected. Files without checksum are not verified.
+type BootCertChecksum struct {
+	CA      string `yaml:"ca" json:"ca"`
+	CertPem string `yaml:"certPem" json:"certPem"`
+	KeyPem  string `yaml:"keyPem" json:"keyPem"`
 }
 
 // CertEntry contains bellow fields.
@@ -103,7 +224,13 @@ type CertEntry struct {
 	caPath           string            `json:"-" yaml:"-"`
 	keyPemPath       string            `json:"-" yaml:"-"`
 	certPemPath      string            `json:"-" yaml:"-"`
+	keyPem           []byte            `json:"-" yaml:"-"`
+	certPem          []byte            `json:"-" yaml:"-"`
 	embedFS          *embed.FS         `json:"-" yaml:"-"`
+	minVersion       uint16            `json:"-" yaml:"-"`
+	maxVersion       uint16            `json:"-" yaml:"-"`
+	cipherSuites     []uint16          `json:"-" yaml:"-"`
+	checksum         BootCertChecksum  `json:"-" yaml:"-"`
 	RootCA           *x509.Certificate `json:"-" json:"-"`
 	Certificate      *tls.Certificate  `json:"-" yaml:"-"`
 	bootstrapOnce    sync.Once         `yaml:"-" json:"-"`
@@ -112,11 +239,11 @@ type CertEntry struct {
 // Bootstrap iterate retrievers and call Retrieve() for each of them.
 func (entry *CertEntry) Bootstrap(ctx context.Context) {
 	entry.bootstrapOnce.Do(func() {
-		// server cert path
-		if len(entry.keyPemPath) > 0 && len(entry.certPemPath) > 0 {
+		// server cert path, skip it if key pair was parsed from PEM already
+		if entry.Certificate == nil && len(entry.keyPemPath) > 0 && len(entry.certPemPath) > 0 {
 			cert, err := tls.X509KeyPair(
-				readFile(entry.certPemPath, entry.embedFS, true),
-				readFile(entry.keyPemPath, entry.embedFS, true))
+				entry.readFileWithChecksum(entry.certPemPath, entry.checksum.CertPem),
+				entry.readFileWithChecksum(entry.keyPemPath, entry.checksum.KeyPem))
 			if err != nil {
 				ShutdownWithError(err)
 			}
@@ -125,7 +252,7 @@ func (entry *CertEntry) Bootstrap(ctx context.Context) {
 		}
 
 		if len(entry.caPath) > 0 {
-			block, _ := pem.Decode(readFile(entry.caPath, entry.embedFS, true))
+			block, _ := pem.Decode(entry.readFileWithChecksum(entry.caPath, entry.checksum.CA))
 			if block == nil || block.Type != "CERTIFICATE" || len(block.Headers) != 0 {
 				return
 			}
@@ -140,6 +267,39 @@ func (entry *CertEntry) Bootstrap(ctx context.Context) {
 	})
 }
 
+// checksumFile is a file of entry with expected checksum
+type checksumFile struct {
+	path     string
+	expected string
+}
+
+// checksumFiles returns files of entry with expected checksums
+func (entry *CertEntry) checksumFiles() []checksumFile {
+	return []checksumFile{
+		{path: entry.caPath, expected: entry.checksum.CA},
+		{path: entry.certPemPath, expected: entry.checksum.CertPem},
+		{path: entry.keyPemPath, expected: entry.checksum.KeyPem},
+	}
+}
+
+// readFileWithChecksum reads file and shutdown if SHA-256 checksum doesn't match expected one,
+// file is not verified if expected is empty.
+func (entry *CertEntry) readFileWithChecksum(filePath, expected string) []byte {
+	data := readFile(filePath, entry.embedFS, true)
+	if len(expected) < 1 {
+		return data
+	}
+
+	expected = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(expected), "sha256:"))
+	sum := sha256.Sum256(data)
+	if actual := hex.EncodeToString(sum[:]); actual != expected {
+		ShutdownWithError(fmt.Errorf("checksum mismatch, entry:%s, file:%s, expected:%s, actual:%s",
+			entry.entryName, filePath, expected, actual))
+	}
+
+	return data
+}
+
 // Interrupt entry.
 func (entry *CertEntry) Interrupt(context.Context) {}
 
@@ -158,7 +318,15 @@ func (entry *CertEntry) MarshalJSON() ([]byte, error) {
 		"caPath":      entry.caPath,
 		"keyPemPath":  entry.keyPemPath,
 		"certPemPath": entry.certPemPath,
+		"minVersion":  tlsVersionName(entry.minVersion),
+		"maxVersion":  tlsVersionName(entry.maxVersion),
+	}
+
+	suites := make([]string, 0)
+	for i := range entry.cipherSuites {
+		suites = append(suites, tls.CipherSuiteName(entry.cipherSuites[i]))
 	}
+	m["cipherSuites"] = suites
 
 	return json.Marshal(&m)
 }
@@ -182,3 +350,108 @@ func (entry *CertEntry) GetType() string {
 func (entry *CertEntry) GetDescription() string {
 	return entry.entryDescription
 }
+
+// GetTLSConfig returns tls.Config with server cert, root CA, TLS versions and cipher suites.
+// Call it after Bootstrap, since cert and root CA are loaded from files while bootstrapping.
+func (entry *CertEntry) GetTLSConfig() *tls.Config {
+	conf := &tls.Config{
+		MinVersion:   entry.minVersion,
+		MaxVersion:   entry.maxVersion,
+		CipherSuites: entry.cipherSuites,
+	}
+
+	if entry.Certificate != nil {
+		conf.Certificates = []tls.Certificate{*entry.Certificate}
+	}
+
+	if entry.RootCA != nil {
+		conf.RootCAs = x509.NewCertPool()
+		conf.RootCAs.AddCert(entry.RootCA)
+	}
+
+	return conf
+}
+
+// GetMTLSConfig returns tls.Config of GetTLSConfig with client CA pool and client auth type for mutual TLS.
+// clientCA is PEM encoded CA bundle, root CA of entry will be used if clientCA is empty.
+func (entry *CertEntry) GetMTLSConfig(clientCA []byte, auth tls.ClientAuthType) (*tls.Config, error) {
+	conf := entry.GetTLSConfig()
+	conf.ClientAuth = auth
+	conf.ClientCAs = x509.NewCertPool()
+
+	if len(clientCA) < 1 {
+		if entry.RootCA == nil {
+			return nil, fmt.Errorf("client CA is missing, entryName:%s", entry.entryName)
+		}
+		conf.ClientCAs.AddCert(entry.RootCA)
+		return conf, nil
+	}
+
+	if !conf.ClientCAs.AppendCertsFromPEM(clientCA) {
+		return nil, fmt.Errorf("failed to parse client CA, entryName:%s", entry.entryName)
+	}
+
+	return conf, nil
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// parseTLSVersion parse TLS version like 1.2, TLS1.2 or TLS12, returns 0 if empty
+func parseTLSVersion(version string) (uint16, error) {
+	if len(version) < 1 {
+		return 0, nil
+	}
+
+	key := strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(version)), "TLS")
+	key = strings.TrimPrefix(key, "V")
+	if len(key) == 2 && !strings.Contains(key, ".") {
+		key = key[:1] + "." + key[1:]
+	}
+
+	if v, ok := tlsVersions[key]; ok {
+		return v, nil
+	}
+
+	return 0, fmt.Errorf("unknown TLS version:%s, valid versions:[1.0 1.1 1.2 1.3]", version)
+}
+
+// tlsVersionName returns name of TLS version, empty if 0
+func tlsVersionName(version uint16) string {
+	for k, v := range tlsVersions {
+		if v == version {
+			return k
+		}
+	}
+
+	return ""
+}
+
+// parseCipherSuites parse names of cipher suites, insecure cipher suites are accepted as well
+func parseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) < 1 {
+		return nil, nil
+	}
+
+	suites := make(map[string]uint16)
+	valid := make([]string, 0)
+	for _, v := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		suites[v.Name] = v.ID
+		valid = append(valid, v.Name)
+	}
+
+	res := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := suites[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown cipher suite:%s, valid cipher suites:%v", name, valid)
+		}
+		res = append(res, id)
+	}
+
+	return res, nil
+}
@@ -4,13 +4,18 @@ import (
 	"context"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/hex"
 	"encoding/pem"
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"math/big"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -96,6 +101,86 @@ func TestCertEntry_Bootstrap(t *testing.T) {
 	entry.Interrupt(context.TODO())
 }
 
+func TestRegisterCertEntry_WithChecksum(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(CertEntryType)
+
+	caPem, _ := generateCerts(t)
+	certPem, keyPem := generateCerts(t)
+
+	dir := t.TempDir()
+	caPath := filepath.Join(dir, "ca.pem")
+	certPemPath := filepath.Join(dir, "cert.pem")
+	keyPemPath := filepath.Join(dir, "key.pem")
+	assert.Nil(t, os.WriteFile(caPath, caPem, os.ModePerm))
+	assert.Nil(t, os.WriteFile(certPemPath, certPem, os.ModePerm))
+	assert.Nil(t, os.WriteFile(keyPemPath, keyPem, os.ModePerm))
+
+	checksum := func(data []byte) string {
+		sum := sha256.Sum256(data)
+		return hex.EncodeToString(sum[:])
+	}
+
+	// matched checksums
+	entries := RegisterCertEntry(&BootCert{
+		Cert: []*BootCertE{
+			{
+				Name:        "ut-cert",
+				CAPath:      caPath,
+				CertPemPath: certPemPath,
+				KeyPemPath:  keyPemPath,
+				Checksum: BootCertChecksum{
+					CA:      "sha256:" + checksum(caPem),
+					CertPem: strings.ToUpper(checksum(certPem)),
+					KeyPem:  checksum(keyPem),
+				},
+			},
+		},
+	})
+	assert.Len(t, entries, 1)
+	entries[0].Bootstrap(context.TODO())
+	assert.NotNil(t, entries[0].Certificate)
+	assert.NotNil(t, entries[0].RootCA)
+
+	// mismatched checksum at registration
+	func() {
+		defer func() {
+			recovered := recover()
+			assert.NotNil(t, recovered)
+			assert.Contains(t, fmt.Sprint(recovered), "expected:"+checksum(caPem))
+			assert.Contains(t, fmt.Sprint(recovered), "actual:"+checksum(keyPem))
+		}()
+
+		RegisterCertEntry(&BootCert{
+			Cert: []*BootCertE{
+				{
+					Name:       "ut-cert-mismatch",
+					KeyPemPath: keyPemPath,
+					Checksum: BootCertChecksum{
+						KeyPem: checksum(caPem),
+					},
+				},
+			},
+		})
+	}()
+
+	// file corrupted after registration
+	entries = RegisterCertEntry(&BootCert{
+		Cert: []*BootCertE{
+			{
+				Name:        "ut-cert-corrupted",
+				CertPemPath: certPemPath,
+				KeyPemPath:  keyPemPath,
+				Checksum: BootCertChecksum{
+					CertPem: checksum(certPem),
+				},
+			},
+		},
+	})
+	assert.Nil(t, os.WriteFile(certPemPath, certPem[:len(certPem)/2], os.ModePerm))
+	defer assertPanic(t)
+	entries[0].Bootstrap(context.TODO())
+}
+
 func TestCertEntry_UnmarshalJSON(t *testing.T) {
 	entries := RegisterCertEntry(&BootCert{
 		Cert: []*BootCertE{
@@ -140,3 +225,164 @@ func generateCerts(t *testing.T) ([]byte, []byte) {
 
 	return pem.EncodeToMemory(c), pem.EncodeToMemory(k)
 }
+
+func TestRegisterCertEntry_WithPEM(t *testing.T) {
+	certPem, keyPem := generateCerts(t)
+
+	// with boot config
+	entries := RegisterCertEntry(&BootCert{
+		Cert: []*BootCertE{
+			{
+				Name:    "ut-cert",
+				CertPem: string(certPem),
+				KeyPem:  string(keyPem),
+			},
+		},
+	})
+	assert.Len(t, entries, 1)
+	assert.NotNil(t, entries[0].Certificate)
+
+	// with options
+	entries = RegisterCertEntry(&BootCert{
+		Cert: []*BootCertE{
+			{
+				Name: "ut-cert",
+			},
+		},
+	}, WithCertPEM(certPem), WithKeyPEM(keyPem))
+	assert.Len(t, entries, 1)
+	assert.NotNil(t, entries[0].Certificate)
+	entries[0].Bootstrap(context.TODO())
+	assert.NotNil(t, entries[0].Certificate)
+}
+
+func TestRegisterCertEntry_WithMissingKeyPEM(t *testing.T) {
+	certPem, _ := generateCerts(t)
+
+	defer assertPanic(t)
+	RegisterCertEntry(&BootCert{
+		Cert: []*BootCertE{
+			{
+				Name:    "ut-cert",
+				CertPem: string(certPem),
+			},
+		},
+	})
+}
+
+func TestRegisterCertEntry_WithMismatchPEM(t *testing.T) {
+	certPem, _ := generateCerts(t)
+	_, otherKeyPem := generateCerts(t)
+
+	defer assertPanic(t)
+	RegisterCertEntry(&BootCert{
+		Cert: []*BootCertE{
+			{
+				Name: "ut-cert",
+			},
+		},
+	}, WithCertPEM(certPem), WithKeyPEM(otherKeyPem))
+}
+
+func TestCertEntry_GetMTLSConfig(t *testing.T) {
+	certPem, keyPem := generateCerts(t)
+
+	entries := RegisterCertEntry(&BootCert{
+		Cert: []*BootCertE{
+			{
+				Name:    "ut-cert",
+				CertPem: string(certPem),
+				KeyPem:  string(keyPem),
+			},
+		},
+	})
+	assert.Len(t, entries, 1)
+	defer GlobalAppCtx.RemoveEntry(entries[0])
+
+	// with client CA
+	conf, err := entries[0].GetMTLSConfig(certPem, tls.RequireAndVerifyClientCert)
+	assert.Nil(t, err)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, conf.ClientAuth)
+	assert.NotNil(t, conf.ClientCAs)
+	assert.Len(t, conf.Certificates, 1)
+
+	// with invalid client CA
+	conf, err = entries[0].GetMTLSConfig([]byte("invalid"), tls.RequireAndVerifyClientCert)
+	assert.NotNil(t, err)
+	assert.Nil(t, conf)
+
+	// without client CA and root CA
+	conf, err = entries[0].GetMTLSConfig(nil, tls.RequireAndVerifyClientCert)
+	assert.NotNil(t, err)
+	assert.Nil(t, conf)
+}
+
+func TestRegisterCertEntry_WithTLSConfig(t *testing.T) {
+	certPem, keyPem := generateCerts(t)
+
+	// with boot config
+	entries := RegisterCertEntry(&BootCert{
+		Cert: []*BootCertE{
+			{
+				Name:         "ut-cert",
+				CertPem:      string(certPem),
+				KeyPem:       string(keyPem),
+				MinVersion:   "1.2",
+				MaxVersion:   "TLS1.3",
+				CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+			},
+		},
+	})
+	assert.Len(t, entries, 1)
+
+	conf := entries[0].GetTLSConfig()
+	assert.Equal(t, uint16(tls.VersionTLS12), conf.MinVersion)
+	assert.Equal(t, uint16(tls.VersionTLS13), conf.MaxVersion)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}, conf.CipherSuites)
+	assert.Len(t, conf.Certificates, 1)
+	assert.Contains(t, entries[0].String(), "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256")
+
+	// with options
+	entries = RegisterCertEntry(&BootCert{
+		Cert: []*BootCertE{
+			{
+				Name:       "ut-cert",
+				MinVersion: "1.0",
+			},
+		},
+	}, WithMinTLSVersion(tls.VersionTLS13), WithCipherSuites(tls.TLS_AES_128_GCM_SHA256))
+	conf = entries[0].GetTLSConfig()
+	assert.Equal(t, uint16(tls.VersionTLS13), conf.MinVersion)
+	assert.Equal(t, []uint16{tls.TLS_AES_128_GCM_SHA256}, conf.CipherSuites)
+	assert.Empty(t, conf.Certificates)
+}
+
+func TestRegisterCertEntry_WithInvalidCipherSuite(t *testing.T) {
+	defer func() {
+		recv := recover()
+		assert.NotNil(t, recv)
+		assert.Contains(t, recv.(error).Error(), "TLS_AES_128_GCM_SHA256")
+	}()
+
+	RegisterCertEntry(&BootCert{
+		Cert: []*BootCertE{
+			{
+				Name:         "ut-cert",
+				CipherSuites: []string{"invalid"},
+			},
+		},
+	})
+}
+
+func TestRegisterCertEntry_WithInvalidTLSVersion(t *testing.T) {
+	defer assertPanic(t)
+
+	RegisterCertEntry(&BootCert{
+		Cert: []*BootCertE{
+			{
+				Name:       "ut-cert",
+				MinVersion: "1.4",
+			},
+		},
+	})
+}
@@ -7,13 +7,20 @@ package rkentry
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/rookie-ninja/rk-entry/v2"
+	"github.com/rookie-ninja/rk-entry/v2/middleware"
 	"github.com/rookie-ninja/rk-entry/v2/os"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	"net/http"
 	"path"
 	"runtime"
+	"sort"
+	"strings"
 )
 
 var swAssetsFile []byte
@@ -37,11 +44,34 @@ var swAssetsFile []byte
 
 // BootCommonService Bootstrap config of common service.
 type BootCommonService struct {
-	Enabled    bool   `yaml:"enabled" json:"enabled"`
-	PathPrefix string `yaml:"pathPrefix" json:"pathPrefix"`
+	Enabled     bool                    `yaml:"enabled" json:"enabled"`
+	PathPrefix  string                  `yaml:"pathPrefix" json:"pathPrefix"`
+	AdminToken  string                  `yaml:"adminToken" json:"adminToken"`
+	AdminPrefix string                  `yaml:"adminPrefix" json:"adminPrefix"`
+	Routes      BootCommonServiceRoutes `yaml:"routes" json:"routes"`
+}
+
+// BootCommonServiceRoutes Bootstrap config of routes in common service.
+type BootCommonServiceRoutes struct {
+	Ready         BootCommonServiceRoute `yaml:"ready" json:"ready"`
+	Alive         BootCommonServiceRoute `yaml:"alive" json:"alive"`
+	Gc            BootCommonServiceRoute `yaml:"gc" json:"gc"`
+	Info          BootCommonServiceRoute `yaml:"info" json:"info"`
+	AdminConfig   BootCommonServiceRoute `yaml:"adminConfig" json:"adminConfig"`
+	Version       BootCommonServiceRoute `yaml:"version" json:"version"`
+	AdminLogLevel BootCommonServiceRoute `yaml:"adminLogLevel" json:"adminLogLevel"`
+	OpenAPI       BootCommonServiceRoute `yaml:"openAPI" json:"openAPI"`
+}
+
+// BootCommonServiceRoute Bootstrap config of a route in common service.
+// Path is relative to pathPrefix, default path will be used if empty.
+type BootCommonServiceRoute struct {
+	Disabled bool   `yaml:"disabled" json:"disabled"`
+	Path     string `yaml:"path" json:"path"`
 }
 
 // CommonServiceEntry RK common service which contains commonly used APIs
+// Path of route is empty if route is disabled, web frameworks should skip registering it.
 type CommonServiceEntry struct {
 	entryName        string `json:"-" yaml:"-"`
 	entryType        string `json:"-" yaml:"-"`
@@ -51,6 +81,13 @@ type CommonServiceEntry struct {
 	AlivePath        string `json:"-" yaml:"-"`
 	GcPath           string `json:"-" yaml:"-"`
 	InfoPath         string `json:"-" yaml:"-"`
+	AdminConfigPath  string `json:"-" yaml:"-"`
+	VersionPath      string `json:"-" yaml:"-"`
+	// AdminLogLevelPath is prefix of path, name of LoggerEntry follows it, like /rk/v1/admin/loglevel/{name}
+	AdminLogLevelPath string `json:"-" yaml:"-"`
+	OpenAPIPath       string `json:"-" yaml:"-"`
+	adminToken        string `json:"-" yaml:"-"`
+	AdminPrefix       string `json:"-" yaml:"-"`
 }
 
 // CommonServiceEntryOption option for CommonServiceEntry
@@ -63,18 +100,30 @@ func WithNameCommonServiceEntry(name string) CommonServiceEntryOption {
 	}
 }
 
+// WithAdminTokenCommonServiceEntry provide bearer token required by AdminConfig handler
+func WithAdminTokenCommonServiceEntry(token string) CommonServiceEntryOption {
+	return func(entry *CommonServiceEntry) {
+		entry.adminToken = token
+	}
+}
+
 // RegisterCommonServiceEntry Create new common service entry with options.
 func RegisterCommonServiceEntry(boot *BootCommonService, opts ...CommonServiceEntryOption) *CommonServiceEntry {
 	if boot.Enabled {
 		entry := &CommonServiceEntry{
-			entryName:        "CommonServiceEntry",
-			entryType:        CommonServiceEntryType,
-			entryDescription: "Internal RK entry which implements commonly used API.",
-			ReadyPath:        "ready",
-			AlivePath:        "alive",
-			GcPath:           "gc",
-			InfoPath:         "info",
-			pathPrefix:       boot.PathPrefix,
+			entryName:         "CommonServiceEntry",
+			entryType:         CommonServiceEntryType,
+			entryDescription:  "Internal RK entry which implements commonly used API.",
+			ReadyPath:         "ready",
+			AlivePath:         "alive",
+			GcPath:            "gc",
+			InfoPath:          "info",
+			AdminConfigPath:   "admin/config",
+			VersionPath:       "version",
+			AdminLogLevelPath: "admin/loglevel",
+			OpenAPIPath:       "admin/openapi.json",
+			pathPrefix:        boot.PathPrefix,
+			adminToken:        boot.AdminToken,
 		}
 
 		for i := range opts {
@@ -85,11 +134,48 @@ func RegisterCommonServiceEntry(boot *BootCommonService, opts ...CommonServiceEn
 			entry.pathPrefix = "/rk/v1"
 		}
 
-		// append prefix
-		entry.ReadyPath = path.Join("/", entry.pathPrefix, entry.ReadyPath)
-		entry.AlivePath = path.Join("/", entry.pathPrefix, entry.AlivePath)
-		entry.GcPath = path.Join("/", entry.pathPrefix, entry.GcPath)
-		entry.InfoPath = path.Join("/", entry.pathPrefix, entry.InfoPath)
+		// append prefix, path of disabled route will be empty
+		entry.ReadyPath = joinCommonServiceRoute(entry.pathPrefix, entry.ReadyPath, &boot.Routes.Ready)
+		entry.AlivePath = joinCommonServiceRoute(entry.pathPrefix, entry.AlivePath, &boot.Routes.Alive)
+		entry.GcPath = joinCommonServiceRoute(entry.pathPrefix, entry.GcPath, &boot.Routes.Gc)
+		entry.InfoPath = joinCommonServiceRoute(entry.pathPrefix, entry.InfoPath, &boot.Routes.Info)
+		entry.AdminConfigPath = joinCommonServiceRoute(entry.pathPrefix, entry.AdminConfigPath, &boot.Routes.AdminConfig)
+		entry.VersionPath = joinCommonServiceRoute(entry.pathPrefix, entry.VersionPath, &boot.Routes.Version)
+		entry.AdminLogLevelPath = joinCommonServiceRoute(entry.pathPrefix, entry.AdminLogLevelPath, &boot.Routes.AdminLogLevel)
+		entry.OpenAPIPath = joinCommonServiceRoute(entry.pathPrefix, entry.OpenAPIPath, &boot.Routes.OpenAPI)
+
+		// routes of entries are mounted under admin prefix, pathPrefix/admin by default
+		entry.AdminPrefix = path.Join("/", boot.AdminPrefix)
+		if len(boot.AdminPrefix) < 1 {
+			entry.AdminPrefix = path.Join("/", entry.pathPrefix, "admin")
+		}
+
+		// validate collision of paths
+		paths := map[string]string{}
+		for _, route := range []struct{ name, path string }{
+			{"ready", entry.ReadyPath},
+			{"alive", entry.AlivePath},
+			{"gc", entry.GcPath},
+			{"info", entry.InfoPath},
+			{"adminConfig", entry.AdminConfigPath},
+			{"version", entry.VersionPath},
+			{"adminLogLevel", entry.AdminLogLevelPath},
+			{"openAPI", entry.OpenAPIPath},
+		} {
+			if len(route.path) < 1 {
+				continue
+			}
+
+			if exist, ok := paths[route.path]; ok {
+				ShutdownWithError(fmt.Errorf("path of common service route %s collides with %s, path:%s",
+					route.name, exist, route.path))
+			}
+			if entry.underAdminLogLevelPath(route.path) {
+				ShutdownWithError(fmt.Errorf("path of common service route %s collides with adminLogLevel, path:%s",
+					route.name, route.path))
+			}
+			paths[route.path] = route.name
+		}
 
 		// change swagger config file
 		oldSwAssets := readFile("assets/sw/config/swagger.json", &rkembed.AssetsFS, true)
@@ -108,25 +194,13 @@ func RegisterCommonServiceEntry(boot *BootCommonService, opts ...CommonServiceEn
 			for p, v := range inner {
 				switch p {
 				case "/rk/v1/ready":
-					if p != entry.ReadyPath {
-						inner[entry.ReadyPath] = v
-						delete(inner, p)
-					}
+					replaceSwaggerPath(inner, p, entry.ReadyPath, v)
 				case "/rk/v1/alive":
-					if p != entry.AlivePath {
-						inner[entry.AlivePath] = v
-						delete(inner, p)
-					}
+					replaceSwaggerPath(inner, p, entry.AlivePath, v)
 				case "/rk/v1/gc":
-					if p != entry.GcPath {
-						inner[entry.GcPath] = v
-						delete(inner, p)
-					}
+					replaceSwaggerPath(inner, p, entry.GcPath, v)
 				case "/rk/v1/info":
-					if p != entry.InfoPath {
-						inner[entry.InfoPath] = v
-						delete(inner, p)
-					}
+					replaceSwaggerPath(inner, p, entry.InfoPath, v)
 				}
 			}
 		}
@@ -143,6 +217,128 @@ func RegisterCommonServiceEntry(boot *BootCommonService, opts ...CommonServiceEn
 	return nil
 }
 
+// joinCommonServiceRoute returns path of route with prefix, empty if route is disabled
+func joinCommonServiceRoute(prefix, defaultPath string, route *BootCommonServiceRoute) string {
+	if route.Disabled {
+		return ""
+	}
+
+	if len(route.Path) > 0 {
+		defaultPath = route.Path
+	}
+
+	return path.Join("/", prefix, defaultPath)
+}
+
+// replaceSwaggerPath move swagger path to new path, remove it if new path is empty
+func replaceSwaggerPath(paths map[string]interface{}, oldPath, newPath string, v interface{}) {
+	if oldPath == newPath {
+		return
+	}
+
+	delete(paths, oldPath)
+	if len(newPath) > 0 {
+		paths[newPath] = v
+	}
+}
+
+// builtinPaths returns paths of enabled builtin routes with name of route
+func (entry *CommonServiceEntry) builtinPaths() map[string]string {
+	res := make(map[string]string)
+	for name, p := range map[string]string{
+		"ready":         entry.ReadyPath,
+		"alive":         entry.AlivePath,
+		"gc":            entry.GcPath,
+		"info":          entry.InfoPath,
+		"adminConfig":   entry.AdminConfigPath,
+		"version":       entry.VersionPath,
+		"adminLogLevel": entry.AdminLogLevelPath,
+		"openAPI":       entry.OpenAPIPath,
+	} {
+		if len(p) > 0 {
+			res[p] = name
+		}
+	}
+
+	return res
+}
+
+// underAdminLogLevelPath returns true if p is served by wildcard after AdminLogLevelPath
+func (entry *CommonServiceEntry) underAdminLogLevelPath(p string) bool {
+	if len(entry.AdminLogLevelPath) < 1 {
+		return false
+	}
+
+	return strings.HasPrefix(p, strings.TrimSuffix(entry.AdminLogLevelPath, "/")+"/")
+}
+
+// ListEntryRoutes returns routes of entries in GlobalAppCtx implementing RouteProvider, sorted by type and name of entry.
+// Path of routes is joined with AdminPrefix.
+//
+// An error will be returned if a path is used by more than one route or collides with builtin routes,
+// including paths under AdminLogLevelPath.
+func (entry *CommonServiceEntry) ListEntryRoutes() ([]Route, error) {
+	providers := make(map[string]RouteProvider)
+	names := make([]string, 0)
+	for entryType, v := range GlobalAppCtx.ListEntries() {
+		for entryName, e := range v {
+			if provider, ok := e.(RouteProvider); ok {
+				name := entryType + "/" + entryName
+				providers[name] = provider
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	owners := entry.builtinPaths()
+	res := make([]Route, 0)
+	for _, name := range names {
+		for _, route := range providers[name].Routes() {
+			if route.Handler == nil {
+				continue
+			}
+
+			route.Path = path.Join(entry.AdminPrefix, route.Path)
+			if owner, ok := owners[route.Path]; ok {
+				return nil, fmt.Errorf("path of route collides, path:%s, entry:%s, collides with:%s", route.Path, name, owner)
+			}
+			if entry.underAdminLogLevelPath(route.Path) {
+				return nil, fmt.Errorf("path of route collides, path:%s, entry:%s, collides with:adminLogLevel", route.Path, name)
+			}
+			owners[route.Path] = name
+			res = append(res, route)
+		}
+	}
+
+	return res, nil
+}
+
+// MountEntryRoutes registers routes of entries returned by ListEntryRoutes to mux.
+// Requests with unexpected method will be rejected with 405.
+func (entry *CommonServiceEntry) MountEntryRoutes(mux *http.ServeMux) error {
+	routes, err := entry.ListEntryRoutes()
+	if err != nil {
+		return err
+	}
+
+	for i := range routes {
+		route := routes[i]
+		mux.HandleFunc(route.Path, func(writer http.ResponseWriter, request *http.Request) {
+			if len(route.Method) > 0 && request.Method != route.Method {
+				writer.WriteHeader(http.StatusMethodNotAllowed)
+				bytes, _ := json.Marshal(rkmid.GetErrorBuilder().New(http.StatusMethodNotAllowed, "Method is not allowed"))
+				writer.Write(bytes)
+				return
+			}
+
+			route.Handler(writer, request)
+		})
+	}
+
+	return nil
+}
+
 // Bootstrap common service entry.
 func (entry *CommonServiceEntry) Bootstrap(context.Context) {}
 
@@ -173,13 +369,18 @@ func (entry *CommonServiceEntry) String() string {
 // MarshalJSON Marshal entry.
 func (entry *CommonServiceEntry) MarshalJSON() ([]byte, error) {
 	m := map[string]interface{}{
-		"name":        entry.GetName(),
-		"type":        entry.GetType(),
-		"description": entry.GetDescription(),
-		"readyPath":   entry.ReadyPath,
-		"alivePath":   entry.AlivePath,
-		"gcPath":      entry.GcPath,
-		"infoPath":    entry.InfoPath,
+		"name":              entry.GetName(),
+		"type":              entry.GetType(),
+		"description":       entry.GetDescription(),
+		"readyPath":         entry.ReadyPath,
+		"alivePath":         entry.AlivePath,
+		"gcPath":            entry.GcPath,
+		"infoPath":          entry.InfoPath,
+		"adminConfigPath":   entry.AdminConfigPath,
+		"versionPath":       entry.VersionPath,
+		"adminLogLevelPath": entry.AdminLogLevelPath,
+		"openAPIPath":       entry.OpenAPIPath,
+		"adminPrefix":       entry.AdminPrefix,
 	}
 
 	return json.Marshal(m)
@@ -198,14 +399,54 @@ func (entry *CommonServiceEntry) UnmarshalJSON([]byte) error {
 // @Security BasicAuth
 // @Security JWT
 // @produce application/json
+// @Param nocache query bool false "Bypass cached results of readiness probes"
+// @Param verbose query bool false "Return status, latency and error of each readiness probe"
 // @Success 200 {object} readyResp
 // @Failure 500 {object} rkerror.ErrorInterface
+// @Failure 503 {object} rkerror.ErrorInterface
 // @Router /rk/v1/ready [get]
 func (entry *CommonServiceEntry) Ready(writer http.ResponseWriter, request *http.Request) {
+	verbose := request != nil && request.URL.Query().Get("verbose") == "true"
+
+	if GlobalAppCtx.IsDraining() && !verbose {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+		bytes, _ := json.MarshalIndent(rkmid.GetErrorBuilder().New(http.StatusServiceUnavailable, "Application is draining"), "", "  ")
+		writer.Write(bytes)
+		return
+	}
+
 	if GlobalAppCtx.readinessCheck != nil && !GlobalAppCtx.readinessCheck(request, writer) {
 		return
 	}
 
+	ctx := context.Background()
+	bypassCache := false
+	if request != nil {
+		ctx = request.Context()
+		bypassCache = request.URL.Query().Get("nocache") == "true"
+	}
+
+	results := GlobalAppCtx.CheckReadiness(ctx, bypassCache)
+
+	if verbose {
+		entry.writeReadyDetail(writer, results)
+		return
+	}
+
+	details := make([]interface{}, 0)
+	for _, res := range results {
+		if res.Err != nil {
+			details = append(details, fmt.Sprintf("%s: %v", res.Name, res.Err))
+		}
+	}
+
+	if len(details) > 0 {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+		bytes, _ := json.MarshalIndent(rkmid.GetErrorBuilder().New(http.StatusServiceUnavailable, "Readiness probe failed", details...), "", "  ")
+		writer.Write(bytes)
+		return
+	}
+
 	writer.WriteHeader(http.StatusOK)
 	bytes, _ := json.MarshalIndent(&readyResp{
 		Ready: true,
@@ -213,6 +454,47 @@ func (entry *CommonServiceEntry) Ready(writer http.ResponseWriter, request *http
 	writer.Write(bytes)
 }
 
+// writeReadyDetail writes name, status, latency and error of each probe result,
+// status code is the same as plain readiness check.
+func (entry *CommonServiceEntry) writeReadyDetail(writer http.ResponseWriter, results []*ProbeResult) {
+	resp := &readyDetailResp{
+		Ready:    !GlobalAppCtx.IsDraining(),
+		Draining: GlobalAppCtx.IsDraining(),
+		Probes:   make([]*readyProbeResp, 0, len(results)),
+	}
+
+	for _, res := range results {
+		probe := &readyProbeResp{
+			Name:      res.Name,
+			Status:    readyProbeStatusReady,
+			Latency:   res.Latency.String(),
+			CheckedAt: res.CheckedAt,
+			Cached:    res.Cached,
+			RootCause: res.RootCause,
+		}
+
+		if res.Err != nil {
+			resp.Ready = false
+			probe.Status = readyProbeStatusNotReady
+			probe.Error = res.Err.Error()
+		}
+		if res.Skipped {
+			probe.Status = readyProbeStatusSkipped
+		}
+
+		resp.Probes = append(resp.Probes, probe)
+	}
+
+	if resp.Ready {
+		writer.WriteHeader(http.StatusOK)
+	} else {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	bytes, _ := json.MarshalIndent(resp, "", "  ")
+	writer.Write(bytes)
+}
+
 // Alive handler
 // @Summary Get application liveness status
 // @Id 8002
@@ -273,3 +555,200 @@ func (entry *CommonServiceEntry) Info(writer http.ResponseWriter, request *http.
 	bytes, _ := json.MarshalIndent(NewProcessInfo(), "", "  ")
 	writer.Write(bytes)
 }
+
+// Version handler
+// @Summary Get application name and version
+// @Id 8006
+// @version 1.0
+// @produce application/json
+// @Success 200 {object} versionResp
+// @Router /rk/v1/version [get]
+func (entry *CommonServiceEntry) Version(writer http.ResponseWriter, request *http.Request) {
+	resp := &versionResp{}
+	if appInfo := GlobalAppCtx.GetAppInfoEntry(); appInfo != nil {
+		resp.Name = appInfo.AppName
+		resp.Version = appInfo.Version
+	}
+
+	writer.WriteHeader(http.StatusOK)
+	bytes, _ := json.Marshal(resp)
+	writer.Write(bytes)
+}
+
+// AdminConfig handler
+// @Summary Get redacted config of all entries
+// @Id 8005
+// @version 1.0
+// @Security JWT
+// @produce application/json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} rkerror.ErrorInterface
+// @Router /rk/v1/admin/config [get]
+func (entry *CommonServiceEntry) AdminConfig(writer http.ResponseWriter, request *http.Request) {
+	if !entry.authorizeAdmin(writer, request) {
+		return
+	}
+
+	raw, err := json.Marshal(GlobalAppCtx)
+	if err != nil {
+		writer.WriteHeader(http.StatusInternalServerError)
+		bytes, _ := json.Marshal(rkmid.GetErrorBuilder().New(http.StatusInternalServerError, "Failed to marshal entries", err))
+		writer.Write(bytes)
+		return
+	}
+
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		entry.writeError(writer, http.StatusInternalServerError, "Failed to unmarshal entries", err)
+		return
+	}
+
+	writer.WriteHeader(http.StatusOK)
+	bytes, _ := json.MarshalIndent(redactSecrets(m), "", "  ")
+	writer.Write(bytes)
+}
+
+// AdminLogLevel handler
+// @Summary Get or change level of LoggerEntry at runtime
+// @Id 8007
+// @version 1.0
+// @Security JWT
+// @accept application/json
+// @produce application/json
+// @Param name path string false "Name of LoggerEntry, levels of all entries are returned with GET if empty"
+// @Param level body logLevelReq false "New level, required with PUT"
+// @Success 200 {object} logLevelResp
+// @Failure 400 {object} rkerror.ErrorInterface
+// @Failure 401 {object} rkerror.ErrorInterface
+// @Failure 404 {object} rkerror.ErrorInterface
+// @Router /rk/v1/admin/loglevel/{name} [put]
+//
+// Web frameworks should mount it with wildcard after AdminLogLevelPath, name of LoggerEntry is parsed from path.
+func (entry *CommonServiceEntry) AdminLogLevel(writer http.ResponseWriter, request *http.Request) {
+	if !entry.authorizeAdmin(writer, request) {
+		return
+	}
+
+	name := strings.Trim(strings.TrimPrefix(request.URL.Path, entry.AdminLogLevelPath), "/")
+
+	// list levels of all entries
+	if len(name) < 1 && request.Method == http.MethodGet {
+		levels := make(map[string]string)
+		for entryName, e := range GlobalAppCtx.ListEntriesByType(LoggerEntryType) {
+			if level, err := e.(*LoggerEntry).GetLevel(); err == nil {
+				levels[entryName] = level.String()
+			}
+		}
+
+		writer.WriteHeader(http.StatusOK)
+		bytes, _ := json.Marshal(levels)
+		writer.Write(bytes)
+		return
+	}
+
+	loggerEntry := GlobalAppCtx.getLoggerEntryStrict(name)
+	if loggerEntry == nil {
+		entry.writeError(writer, http.StatusNotFound, "LoggerEntry is missing", name)
+		return
+	}
+
+	switch request.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		req := &logLevelReq{}
+		if err := json.NewDecoder(request.Body).Decode(req); err != nil {
+			entry.writeError(writer, http.StatusBadRequest, "Invalid request body", err)
+			return
+		}
+
+		level := zapcore.InfoLevel
+		if err := level.UnmarshalText([]byte(strings.ToLower(req.Level))); err != nil || len(req.Level) < 1 {
+			entry.writeError(writer, http.StatusBadRequest, "Invalid level", req.Level)
+			return
+		}
+
+		if err := loggerEntry.SetLevel(level); err != nil {
+			entry.writeError(writer, http.StatusBadRequest, "Failed to change level", err)
+			return
+		}
+
+		GlobalAppCtx.GetLoggerEntryDefault().Info("Changed level of logger entry",
+			zap.String("entryName", loggerEntry.GetName()),
+			zap.String("level", level.String()))
+	default:
+		entry.writeError(writer, http.StatusMethodNotAllowed, "Method is not allowed", request.Method)
+		return
+	}
+
+	level, err := loggerEntry.GetLevel()
+	if err != nil {
+		entry.writeError(writer, http.StatusBadRequest, "Failed to get level", err)
+		return
+	}
+
+	writer.WriteHeader(http.StatusOK)
+	bytes, _ := json.Marshal(&logLevelResp{
+		Name:  loggerEntry.GetName(),
+		Level: level.String(),
+	})
+	writer.Write(bytes)
+}
+
+// OpenAPI handler
+// @Summary Get OpenAPI spec of common service routes and routes of entries
+// @Id 8008
+// @version 1.0
+// @produce application/json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} rkerror.ErrorInterface
+// @Router /rk/v1/admin/openapi.json [get]
+func (entry *CommonServiceEntry) OpenAPI(writer http.ResponseWriter, request *http.Request) {
+	spec, err := entry.OpenAPISpec()
+	if err != nil {
+		entry.writeError(writer, http.StatusInternalServerError, "Failed to generate OpenAPI spec", err)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(http.StatusOK)
+	bytes, _ := json.MarshalIndent(spec, "", "  ")
+	writer.Write(bytes)
+}
+
+// authorizeAdmin writes 401 response and returns false if bearer token doesn't match admin token,
+// all requests are rejected if admin token is not configured.
+func (entry *CommonServiceEntry) authorizeAdmin(writer http.ResponseWriter, request *http.Request) bool {
+	if len(entry.adminToken) < 1 || request == nil {
+		entry.writeUnauthorized(writer, "Admin token is not configured")
+		return false
+	}
+
+	header := request.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		entry.writeUnauthorized(writer, "Missing bearer token")
+		return false
+	}
+
+	token := strings.TrimPrefix(header, "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(entry.adminToken)) != 1 {
+		entry.writeUnauthorized(writer, "Invalid bearer token")
+		return false
+	}
+
+	return true
+}
+
+// writeError write response with error
+func (entry *CommonServiceEntry) writeError(writer http.ResponseWriter, code int, msg string, details ...interface{}) {
+	writer.WriteHeader(code)
+	bytes, _ := json.Marshal(rkmid.GetErrorBuilder().New(code, msg, details...))
+	writer.Write(bytes)
+}
+
+// writeUnauthorized write 401 response
+func (entry *CommonServiceEntry) writeUnauthorized(writer http.ResponseWriter, msg string) {
+	writer.Header().Set("WWW-Authenticate", "Bearer")
+	writer.WriteHeader(http.StatusUnauthorized)
+	bytes, _ := json.Marshal(rkmid.GetErrorBuilder().New(http.StatusUnauthorized, msg))
+	writer.Write(bytes)
+}
@@ -7,9 +7,15 @@ package rkentry
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestRegisterCommonServiceEntry(t *testing.T) {
@@ -29,6 +35,52 @@ func TestRegisterCommonServiceEntry(t *testing.T) {
 	assert.NotEmpty(t, entry.String())
 }
 
+func TestRegisterCommonServiceEntry_WithRoutes(t *testing.T) {
+	// reset swagger config
+	defer RegisterCommonServiceEntry(&BootCommonService{Enabled: true})
+
+	entry := RegisterCommonServiceEntry(&BootCommonService{
+		Enabled:    true,
+		PathPrefix: "/internal/v1",
+		Routes: BootCommonServiceRoutes{
+			Ready: BootCommonServiceRoute{Path: "readyz"},
+			Alive: BootCommonServiceRoute{Path: "healthz"},
+			Gc:    BootCommonServiceRoute{Disabled: true},
+		},
+	})
+
+	assert.Equal(t, "/internal/v1/readyz", entry.ReadyPath)
+	assert.Equal(t, "/internal/v1/healthz", entry.AlivePath)
+	assert.Empty(t, entry.GcPath)
+	assert.Equal(t, "/internal/v1/info", entry.InfoPath)
+	assert.Contains(t, string(swAssetsFile), "/internal/v1/readyz")
+	assert.NotContains(t, string(swAssetsFile), "/rk/v1/gc")
+}
+
+func TestRegisterCommonServiceEntry_WithCollidedRoutes(t *testing.T) {
+	defer assertPanic(t)
+
+	RegisterCommonServiceEntry(&BootCommonService{
+		Enabled: true,
+		Routes: BootCommonServiceRoutes{
+			Ready: BootCommonServiceRoute{Path: "health"},
+			Alive: BootCommonServiceRoute{Path: "health"},
+		},
+	})
+}
+
+func TestRegisterCommonServiceEntry_WithRouteUnderAdminLogLevel(t *testing.T) {
+	defer assertPanic(t)
+
+	RegisterCommonServiceEntry(&BootCommonService{
+		Enabled: true,
+		Routes: BootCommonServiceRoutes{
+			AdminConfig:   BootCommonServiceRoute{Path: "admin/level/config"},
+			AdminLogLevel: BootCommonServiceRoute{Path: "admin/level"},
+		},
+	})
+}
+
 func TestCommonServiceEntry_Bootstrap(t *testing.T) {
 	defer assertNotPanic(t)
 
@@ -62,6 +114,288 @@ func TestCommonServiceEntry_Healthy(t *testing.T) {
 	assert.Contains(t, writer.Body.String(), "true")
 }
 
+func TestCommonServiceEntry_Ready_WithDraining(t *testing.T) {
+	defer GlobalAppCtx.setDraining(false)
+
+	entry := RegisterCommonServiceEntry(&BootCommonService{
+		Enabled: true,
+	})
+
+	GlobalAppCtx.setDraining(true)
+	writer := httptest.NewRecorder()
+	entry.Ready(writer, httptest.NewRequest(http.MethodGet, entry.ReadyPath, nil))
+	assert.Equal(t, http.StatusServiceUnavailable, writer.Code)
+	assert.Contains(t, writer.Body.String(), "Application is draining")
+}
+
+type routeEntryMock struct {
+	EntryMock
+	routes []Route
+}
+
+func (m *routeEntryMock) Routes() []Route {
+	return m.routes
+}
+
+func TestCommonServiceEntry_MountEntryRoutes(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType("mock")
+
+	entry := RegisterCommonServiceEntry(&BootCommonService{
+		Enabled: true,
+	})
+	assert.Equal(t, "/rk/v1/admin", entry.AdminPrefix)
+
+	handler := func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+		writer.Write([]byte("ut-route"))
+	}
+	GlobalAppCtx.AddEntry(&routeEntryMock{
+		EntryMock: EntryMock{Name: "ut-route-a"},
+		routes:    []Route{{Method: http.MethodGet, Path: "debug", Handler: handler}},
+	})
+	GlobalAppCtx.AddEntry(&routeEntryMock{
+		EntryMock: EntryMock{Name: "ut-route-b"},
+		routes:    []Route{{Path: "/stats", Handler: handler}, {Path: "nil-handler"}},
+	})
+
+	routes, err := entry.ListEntryRoutes()
+	assert.Nil(t, err)
+	assert.Len(t, routes, 2)
+	assert.Equal(t, "/rk/v1/admin/debug", routes[0].Path)
+	assert.Equal(t, "/rk/v1/admin/stats", routes[1].Path)
+
+	mux := http.NewServeMux()
+	assert.Nil(t, entry.MountEntryRoutes(mux))
+
+	writer := httptest.NewRecorder()
+	mux.ServeHTTP(writer, httptest.NewRequest(http.MethodGet, "/rk/v1/admin/debug", nil))
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, "ut-route", writer.Body.String())
+
+	writer = httptest.NewRecorder()
+	mux.ServeHTTP(writer, httptest.NewRequest(http.MethodPost, "/rk/v1/admin/debug", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, writer.Code)
+
+	// collision across entries
+	GlobalAppCtx.AddEntry(&routeEntryMock{
+		EntryMock: EntryMock{Name: "ut-route-c"},
+		routes:    []Route{{Path: "stats", Handler: handler}},
+	})
+	_, err = entry.ListEntryRoutes()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "mock/ut-route-b")
+	assert.NotNil(t, entry.MountEntryRoutes(http.NewServeMux()))
+
+	// collision with builtin route
+	GlobalAppCtx.RemoveEntry(&EntryMock{Name: "ut-route-c"})
+	GlobalAppCtx.AddEntry(&routeEntryMock{
+		EntryMock: EntryMock{Name: "ut-route-d"},
+		routes:    []Route{{Path: "config", Handler: handler}},
+	})
+	_, err = entry.ListEntryRoutes()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "adminConfig")
+
+	// collision with wildcard of builtin log level route
+	GlobalAppCtx.RemoveEntry(&EntryMock{Name: "ut-route-d"})
+	GlobalAppCtx.AddEntry(&routeEntryMock{
+		EntryMock: EntryMock{Name: "ut-route-e"},
+		routes:    []Route{{Path: "loglevel/ut-logger", Handler: handler}},
+	})
+	_, err = entry.ListEntryRoutes()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "adminLogLevel")
+
+	// path sharing prefix only is fine
+	GlobalAppCtx.RemoveEntry(&EntryMock{Name: "ut-route-e"})
+	GlobalAppCtx.AddEntry(&routeEntryMock{
+		EntryMock: EntryMock{Name: "ut-route-f"},
+		routes:    []Route{{Path: "loglevels", Handler: handler}},
+	})
+	_, err = entry.ListEntryRoutes()
+	assert.Nil(t, err)
+}
+
+type healthEntryMock struct {
+	EntryMock
+	err error
+}
+
+func (m *healthEntryMock) Health(context.Context) error {
+	return m.err
+}
+
+func TestCommonServiceEntry_Ready_WithHealthReporter(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType("mock")
+
+	entry := RegisterCommonServiceEntry(&BootCommonService{
+		Enabled: true,
+	})
+
+	reporter := &healthEntryMock{
+		EntryMock: EntryMock{Name: "ut-health"},
+		err:       errors.New("ut-error"),
+	}
+	GlobalAppCtx.AddEntry(reporter)
+	GlobalAppCtx.AddEntry(&EntryMock{Name: "ut-no-health"})
+	GlobalAppCtx.AddEntry(&healthEntryMock{
+		EntryMock: EntryMock{Name: "ut-health-stopped"},
+		err:       errors.New("ut-error-stopped"),
+	})
+
+	// entries which are not running are skipped
+	writer := httptest.NewRecorder()
+	entry.Ready(writer, httptest.NewRequest(http.MethodGet, entry.ReadyPath, nil))
+	assert.Equal(t, http.StatusOK, writer.Code)
+
+	GlobalAppCtx.lifecycle.lock.Lock()
+	GlobalAppCtx.lifecycle.setState("mock", "ut-health", EntryStateRunning)
+	GlobalAppCtx.lifecycle.setState("mock", "ut-health-stopped", EntryStateStopped)
+	GlobalAppCtx.lifecycle.lock.Unlock()
+
+	writer = httptest.NewRecorder()
+	entry.Ready(writer, httptest.NewRequest(http.MethodGet, entry.ReadyPath, nil))
+	assert.Equal(t, http.StatusServiceUnavailable, writer.Code)
+	assert.Contains(t, writer.Body.String(), "mock/ut-health: ut-error")
+	assert.NotContains(t, writer.Body.String(), "ut-error-stopped")
+
+	// health of entry is never cached
+	reporter.err = nil
+	writer = httptest.NewRecorder()
+	entry.Ready(writer, httptest.NewRequest(http.MethodGet, entry.ReadyPath, nil))
+	assert.Equal(t, http.StatusOK, writer.Code)
+}
+
+func TestCommonServiceEntry_Ready_WithProbe(t *testing.T) {
+	defer GlobalAppCtx.RemoveReadinessProbe("ut-probe")
+
+	entry := RegisterCommonServiceEntry(&BootCommonService{
+		Enabled: true,
+	})
+
+	healthy := false
+	GlobalAppCtx.AddReadinessProbe("ut-probe", func(context.Context) error {
+		if !healthy {
+			return errors.New("ut-error")
+		}
+		return nil
+	}, time.Minute)
+
+	writer := httptest.NewRecorder()
+	entry.Ready(writer, httptest.NewRequest(http.MethodGet, entry.ReadyPath, nil))
+	assert.Equal(t, http.StatusServiceUnavailable, writer.Code)
+	assert.Contains(t, writer.Body.String(), "ut-probe: ut-error")
+
+	// cached result is still failing
+	healthy = true
+	writer = httptest.NewRecorder()
+	entry.Ready(writer, httptest.NewRequest(http.MethodGet, entry.ReadyPath, nil))
+	assert.Equal(t, http.StatusServiceUnavailable, writer.Code)
+
+	// bypass cache
+	writer = httptest.NewRecorder()
+	entry.Ready(writer, httptest.NewRequest(http.MethodGet, entry.ReadyPath+"?nocache=true", nil))
+	assert.Equal(t, http.StatusOK, writer.Code)
+}
+
+func TestCommonServiceEntry_Ready_WithVerbose(t *testing.T) {
+	defer GlobalAppCtx.RemoveReadinessProbe("ut-probe-db")
+	defer GlobalAppCtx.RemoveReadinessProbe("ut-probe-cache")
+
+	entry := RegisterCommonServiceEntry(&BootCommonService{
+		Enabled: true,
+	})
+
+	GlobalAppCtx.AddReadinessProbe("ut-probe-db", func(context.Context) error {
+		return errors.New("ut-error")
+	}, 0)
+	GlobalAppCtx.AddReadinessProbeWithDependencies("ut-probe-cache", func(context.Context) error {
+		return nil
+	}, 0, "ut-probe-db")
+
+	writer := httptest.NewRecorder()
+	entry.Ready(writer, httptest.NewRequest(http.MethodGet, entry.ReadyPath+"?verbose=true", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, writer.Code)
+
+	resp := &readyDetailResp{}
+	assert.Nil(t, json.Unmarshal(writer.Body.Bytes(), resp))
+	assert.False(t, resp.Ready)
+	assert.False(t, resp.Draining)
+	assert.Len(t, resp.Probes, 2)
+	assert.Equal(t, "ut-probe-db", resp.Probes[0].Name)
+	assert.Equal(t, readyProbeStatusNotReady, resp.Probes[0].Status)
+	assert.Equal(t, "ut-error", resp.Probes[0].Error)
+	assert.NotEmpty(t, resp.Probes[0].Latency)
+	assert.Equal(t, "ut-probe-cache", resp.Probes[1].Name)
+	assert.Equal(t, readyProbeStatusSkipped, resp.Probes[1].Status)
+	assert.Equal(t, "ut-probe-db", resp.Probes[1].RootCause)
+
+	// all probes are ready
+	GlobalAppCtx.RemoveReadinessProbe("ut-probe-cache")
+	GlobalAppCtx.AddReadinessProbe("ut-probe-db", func(context.Context) error {
+		return nil
+	}, 0)
+	writer = httptest.NewRecorder()
+	entry.Ready(writer, httptest.NewRequest(http.MethodGet, entry.ReadyPath+"?verbose=true", nil))
+	assert.Equal(t, http.StatusOK, writer.Code)
+
+	resp = &readyDetailResp{}
+	assert.Nil(t, json.Unmarshal(writer.Body.Bytes(), resp))
+	assert.True(t, resp.Ready)
+	assert.Len(t, resp.Probes, 1)
+	assert.Equal(t, readyProbeStatusReady, resp.Probes[0].Status)
+	assert.Empty(t, resp.Probes[0].Error)
+}
+
+func TestCommonServiceEntry_OpenAPI(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntry(GlobalAppCtx.GetEntry("mock", "ut-route-entry"))
+
+	entry := RegisterCommonServiceEntry(&BootCommonService{
+		Enabled: true,
+		Routes: BootCommonServiceRoutes{
+			Gc: BootCommonServiceRoute{Disabled: true},
+		},
+	})
+	assert.Equal(t, "/rk/v1/admin/openapi.json", entry.OpenAPIPath)
+
+	GlobalAppCtx.AddEntry(&routeEntryMock{
+		EntryMock: EntryMock{Name: "ut-route-entry"},
+		routes: []Route{
+			{Method: http.MethodPost, Path: "ut-post", Handler: func(http.ResponseWriter, *http.Request) {}, Summary: "ut-summary"},
+			{Path: "ut-any", Handler: func(http.ResponseWriter, *http.Request) {}},
+		},
+	})
+
+	writer := httptest.NewRecorder()
+	entry.OpenAPI(writer, httptest.NewRequest(http.MethodGet, entry.OpenAPIPath, nil))
+	assert.Equal(t, http.StatusOK, writer.Code)
+
+	spec := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(writer.Body.Bytes(), &spec))
+	assert.Equal(t, OpenAPIVersion, spec["openapi"])
+
+	paths := spec["paths"].(map[string]interface{})
+	assert.Contains(t, paths, entry.ReadyPath)
+	assert.Contains(t, paths, entry.OpenAPIPath)
+	assert.Contains(t, paths, entry.AdminLogLevelPath+"/{name}")
+	assert.NotContains(t, paths, "/rk/v1/gc")
+
+	// admin routes require bearer token
+	adminConfig := paths[entry.AdminConfigPath].(map[string]interface{})["get"].(map[string]interface{})
+	assert.NotEmpty(t, adminConfig["security"])
+
+	// routes of entries
+	post := paths["/rk/v1/admin/ut-post"].(map[string]interface{})["post"].(map[string]interface{})
+	assert.Equal(t, "ut-summary", post["summary"])
+	assert.Equal(t, "postRkV1AdminUtPost", post["operationId"])
+	anyMethod := paths["/rk/v1/admin/ut-any"].(map[string]interface{})["get"].(map[string]interface{})
+	assert.Equal(t, "Any method is accepted.", anyMethod["description"])
+	assert.Equal(t, "getRkV1AdminUtAny", anyMethod["operationId"])
+
+	// operation id of routes with same path and different methods are unique
+	assert.Equal(t, "putRkV1AdminUtPost", openAPIOperationId(http.MethodPut, "/rk/v1/admin/ut-post"))
+}
+
 func TestCommonServiceEntry_GC(t *testing.T) {
 	defer assertNotPanic(t)
 
@@ -96,3 +430,145 @@ func TestCommonServiceEntry_UnmarshalJSON(t *testing.T) {
 	})
 	assert.Nil(t, entry.UnmarshalJSON(nil))
 }
+
+func TestCommonServiceEntry_AdminConfig(t *testing.T) {
+	defer assertNotPanic(t)
+	defer GlobalAppCtx.RemoveEntryByType(EventEntryType)
+
+	RegisterEventEntry(&BootEvent{
+		Event: []*BootEventE{
+			{
+				Name: "ut-event",
+				Loki: BootLoki{
+					Enabled:  true,
+					Username: "ut-user",
+					Password: "ut-password",
+				},
+			},
+		},
+	})
+
+	// without token configured
+	entry := RegisterCommonServiceEntry(&BootCommonService{
+		Enabled: true,
+	})
+	assert.Equal(t, "/rk/v1/admin/config", entry.AdminConfigPath)
+	writer := httptest.NewRecorder()
+	entry.AdminConfig(writer, httptest.NewRequest(http.MethodGet, entry.AdminConfigPath, nil))
+	assert.Equal(t, http.StatusUnauthorized, writer.Code)
+
+	entry = RegisterCommonServiceEntry(&BootCommonService{
+		Enabled:    true,
+		AdminToken: "ut-token",
+	})
+
+	// with invalid token
+	writer = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, entry.AdminConfigPath, nil)
+	req.Header.Set("Authorization", "Bearer invalid")
+	entry.AdminConfig(writer, req)
+	assert.Equal(t, http.StatusUnauthorized, writer.Code)
+
+	// with token but without bearer scheme
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, entry.AdminConfigPath, nil)
+	req.Header.Set("Authorization", "ut-token")
+	entry.AdminConfig(writer, req)
+	assert.Equal(t, http.StatusUnauthorized, writer.Code)
+
+	// with valid token
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, entry.AdminConfigPath, nil)
+	req.Header.Set("Authorization", "Bearer ut-token")
+	entry.AdminConfig(writer, req)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Contains(t, writer.Body.String(), "ut-event")
+	assert.NotContains(t, writer.Body.String(), "ut-password")
+
+	// with option
+	entry = RegisterCommonServiceEntry(&BootCommonService{
+		Enabled: true,
+	}, WithAdminTokenCommonServiceEntry("ut-token"))
+	writer = httptest.NewRecorder()
+	entry.AdminConfig(writer, req)
+	assert.Equal(t, http.StatusOK, writer.Code)
+}
+
+func TestCommonServiceEntry_AdminLogLevel(t *testing.T) {
+	defer assertNotPanic(t)
+	defer GlobalAppCtx.RemoveEntryByType(LoggerEntryType)
+
+	RegisterLoggerEntry(&BootLogger{
+		Logger: []*BootLoggerE{
+			{Name: "ut-logger-a"},
+			{Name: "ut-logger-b"},
+		},
+	})
+	loggerA := GlobalAppCtx.GetLoggerEntry("ut-logger-a")
+	loggerB := GlobalAppCtx.GetLoggerEntry("ut-logger-b")
+
+	entry := RegisterCommonServiceEntry(&BootCommonService{
+		Enabled:    true,
+		AdminToken: "ut-token",
+	})
+	assert.Equal(t, "/rk/v1/admin/loglevel", entry.AdminLogLevelPath)
+
+	do := func(method, p, body string) *httptest.ResponseRecorder {
+		writer := httptest.NewRecorder()
+		req := httptest.NewRequest(method, p, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer ut-token")
+		entry.AdminLogLevel(writer, req)
+		return writer
+	}
+
+	// without token
+	writer := httptest.NewRecorder()
+	entry.AdminLogLevel(writer, httptest.NewRequest(http.MethodGet, entry.AdminLogLevelPath, nil))
+	assert.Equal(t, http.StatusUnauthorized, writer.Code)
+
+	// list levels
+	writer = do(http.MethodGet, entry.AdminLogLevelPath, "")
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Contains(t, writer.Body.String(), `"ut-logger-a":"info"`)
+
+	// change level of one entry only
+	writer = do(http.MethodPut, entry.AdminLogLevelPath+"/ut-logger-a", `{"level":"DEBUG"}`)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.JSONEq(t, `{"name":"ut-logger-a","level":"debug"}`, writer.Body.String())
+	assert.True(t, loggerA.Core().Enabled(zapcore.DebugLevel))
+	assert.False(t, loggerB.Core().Enabled(zapcore.DebugLevel))
+
+	writer = do(http.MethodGet, entry.AdminLogLevelPath+"/ut-logger-b", "")
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.JSONEq(t, `{"name":"ut-logger-b","level":"info"}`, writer.Body.String())
+
+	// unknown entry
+	writer = do(http.MethodPut, entry.AdminLogLevelPath+"/ut-unknown", `{"level":"debug"}`)
+	assert.Equal(t, http.StatusNotFound, writer.Code)
+
+	// invalid level
+	writer = do(http.MethodPut, entry.AdminLogLevelPath+"/ut-logger-a", `{"level":"verbose"}`)
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+	writer = do(http.MethodPut, entry.AdminLogLevelPath+"/ut-logger-a", `{`)
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+
+	// invalid method
+	writer = do(http.MethodPost, entry.AdminLogLevelPath+"/ut-logger-a", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, writer.Code)
+}
+
+func TestCommonServiceEntry_Version(t *testing.T) {
+	defer assertNotPanic(t)
+
+	entry := RegisterCommonServiceEntry(&BootCommonService{
+		Enabled: true,
+	})
+	assert.Equal(t, "/rk/v1/version", entry.VersionPath)
+
+	writer := httptest.NewRecorder()
+
+	entry.Version(writer, nil)
+	assert.Equal(t, 200, writer.Code)
+	assert.Contains(t, writer.Body.String(), `"name":"`+GlobalAppCtx.GetAppInfoEntry().AppName+`"`)
+	assert.Contains(t, writer.Body.String(), `"version":"`+GlobalAppCtx.GetAppInfoEntry().Version+`"`)
+}
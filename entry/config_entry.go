@@ -6,12 +6,23 @@
 package rkentry
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"github.com/fsnotify/fsnotify"
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
 )
 
 // RegisterConfigEntry create ConfigEntry with BootConfigConfig.
@@ -54,6 +65,8 @@ func RegisterConfigEntry(boot *BootConfig) []*ConfigEntry {
 			Viper:            viper.New(),
 			Path:             config.Path,
 			EnvPrefix:        config.EnvPrefix,
+			IsDefault:        config.Default,
+			watch:            config.Watch,
 		}
 
 		// if file path was provided
@@ -67,10 +80,9 @@ func RegisterConfigEntry(boot *BootConfig) []*ConfigEntry {
 			}
 
 			// skip this element if path is not valid
-			if fileExists(entry.Path) {
-				entry.Viper.SetConfigFile(entry.Path)
-				if err := entry.Viper.ReadInConfig(); err != nil {
-					ShutdownWithError(fmt.Errorf("failed to read file, path:%s", entry.Path))
+			if fileExists(entry.Path) || dirExists(entry.Path) {
+				if err := entry.readConfig(); err != nil {
+					ShutdownWithError(err)
 				}
 			}
 		}
@@ -85,6 +97,21 @@ func RegisterConfigEntry(boot *BootConfig) []*ConfigEntry {
 		entry.Viper.AutomaticEnv()
 		entry.Viper.SetEnvPrefix(entry.EnvPrefix)
 
+		// redact resolved values of secret keys in logs and events
+		entry.MarkSecret(config.Secrets...)
+
+		GlobalAppCtx.GetLoggerEntryDefault().Info("Config loaded",
+			zap.String("entryName", entry.entryName),
+			zap.String("checksum", entry.Checksum()),
+			zap.String("version", entry.Version()))
+
+		if err := GlobalAppCtx.SetEntryTimeout(ConfigEntryType, config.Name, &config.BootTimeout); err != nil {
+			ShutdownWithError(err)
+		}
+		if err := GlobalAppCtx.SetEntryFailurePolicy(ConfigEntryType, config.Name, &config.BootFailurePolicy); err != nil {
+			ShutdownWithError(err)
+		}
+
 		GlobalAppCtx.AddEntry(entry)
 		res = append(res, entry)
 	}
@@ -113,17 +140,28 @@ type BootConfig struct {
 	Config []*BootConfigE `yaml:"config" json:"config"`
 }
 
-// BootConfigE element of ConfigEntry
+// BootConfigE element of ConfigEntry.
+//
+// Path could be a directory like ConfigMap of Kubernetes mounted as volume, each file in it is a key.
+// Config will be reloaded on change of file or ConfigMap if Watch is enabled.
 type BootConfigE struct {
-	Name        string                 `yaml:"name" json:"name"`
-	Description string                 `yaml:"description" json:"description"`
-	Domain      string                 `yaml:"domain" json:"domain"`
-	Path        string                 `yaml:"path" json:"name"`
-	EnvPrefix   string                 `yaml:"envPrefix" json:"envPrefix"`
-	Content     map[string]interface{} `yaml:"content" json:"content"`
+	Name              string                 `yaml:"name" json:"name"`
+	Description       string                 `yaml:"description" json:"description"`
+	Domain            string                 `yaml:"domain" json:"domain"`
+	Path              string                 `yaml:"path" json:"path"`
+	EnvPrefix         string                 `yaml:"envPrefix" json:"envPrefix"`
+	Default           bool                   `yaml:"default" json:"default"`
+	Content           map[string]interface{} `yaml:"content" json:"content"`
+	Secrets           []string               `yaml:"secrets" json:"secrets"`
+	Watch             bool                   `yaml:"watch" json:"watch"`
+	BootTimeout       `yaml:",inline" json:",inline" mapstructure:",squash"`
+	BootFailurePolicy `yaml:",inline" json:",inline" mapstructure:",squash"`
 }
 
 // ConfigEntry contains bellow fields.
+//
+// Getters like GetString and Set of viper are overridden to be safe to call while config is reloading,
+// other methods of embedded Viper are not synchronized with Reload.
 type ConfigEntry struct {
 	*viper.Viper
 
@@ -133,14 +171,59 @@ type ConfigEntry struct {
 	Locale           string                 `yaml:"-" json:"-"`
 	Path             string                 `yaml:"-" json:"-"`
 	EnvPrefix        string                 `yaml:"-" json:"-"`
+	IsDefault        bool                   `yaml:"-" json:"-"`
 	content          map[string]interface{} `yaml:"-" json:"-"`
+	deprecatedKeys   map[string]string      `yaml:"-" json:"-"`
+	deprecatedInUse  map[string]bool        `yaml:"-" json:"-"`
+	deprecatedLock   sync.Mutex             `yaml:"-" json:"-"`
+	fileKeys         map[string]bool        `yaml:"-" json:"-"`
+	secretKeys       []string               `yaml:"-" json:"-"`
+	watch            bool                   `yaml:"-" json:"-"`
+	watcher          *fsnotify.Watcher      `yaml:"-" json:"-"`
+	watchWait        sync.WaitGroup         `yaml:"-" json:"-"`
+	watchLock        sync.Mutex             `yaml:"-" json:"-"`
+	watchReloads     atomic.Int64           `yaml:"-" json:"-"`
+	viperLock        sync.RWMutex           `yaml:"-" json:"-"`
+	reloadLock       sync.Mutex             `yaml:"-" json:"-"`
+	changeLock       sync.Mutex             `yaml:"-" json:"-"`
+	changeHandlers   []ConfigChangeHandler  `yaml:"-" json:"-"`
+	changeCancel     context.CancelFunc     `yaml:"-" json:"-"`
 }
 
-// Bootstrap entry.
-func (entry *ConfigEntry) Bootstrap(context.Context) {}
+// ConfigChangeHandler is called after ConfigEntry reloaded, ctx is canceled if a newer reload supersedes it.
+type ConfigChangeHandler func(ctx context.Context, entry *ConfigEntry)
+
+const (
+	// ConfigSourceContent value comes from content in boot config
+	ConfigSourceContent = "content"
+	// ConfigSourceEnv value comes from environment variable
+	ConfigSourceEnv = "env"
+	// ConfigSourceFile value comes from config file
+	ConfigSourceFile = "file"
+	// ConfigSourceRuntime value was set with Set or SetDefault of viper at runtime
+	ConfigSourceRuntime = "runtime"
+	// ConfigSourceMissing key is missing
+	ConfigSourceMissing = "missing"
+)
 
-// Interrupt entry.
-func (entry *ConfigEntry) Interrupt(context.Context) {}
+// Bootstrap entry, config will be watched and reloaded on change if watch is enabled.
+func (entry *ConfigEntry) Bootstrap(context.Context) {
+	if !entry.watch {
+		return
+	}
+
+	if err := entry.startWatch(); err != nil {
+		GlobalAppCtx.GetLoggerEntryDefault().Warn("Failed to watch config",
+			zap.String("entryName", entry.entryName),
+			zap.String("path", entry.Path),
+			zap.Error(err))
+	}
+}
+
+// Interrupt entry, watcher of config will be stopped.
+func (entry *ConfigEntry) Interrupt(context.Context) {
+	entry.stopWatch()
+}
 
 // GetName returns name of entry.
 func (entry *ConfigEntry) GetName() string {
@@ -167,6 +250,10 @@ func (entry *ConfigEntry) MarshalJSON() ([]byte, error) {
 		"locale":      entry.Locale,
 		"path":        entry.Path,
 		"envPrefix":   entry.EnvPrefix,
+		"default":     entry.IsDefault,
+		"watch":       entry.watch,
+		"checksum":    entry.Checksum(),
+		"version":     entry.Version(),
 	}
 
 	return json.Marshal(m)
@@ -181,3 +268,697 @@ func (entry *ConfigEntry) UnmarshalJSON([]byte) error {
 func (entry *ConfigEntry) GetDescription() string {
 	return entry.entryDescription
 }
+
+// Checksum returns sha256 of effective settings in hex, it is stable for the same settings.
+func (entry *ConfigEntry) Checksum() string {
+	// keys of map are sorted by json.Marshal
+	bytes, err := json.Marshal(entry.AllSettings())
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(bytes)
+	return hex.EncodeToString(sum[:])
+}
+
+// Version returns value of version key, empty if missing.
+func (entry *ConfigEntry) Version() string {
+	return entry.GetString("version")
+}
+
+// Reload read config file or directory again and log transition of checksum, handlers registered
+// with OnChange are called in order of registration after that.
+//
+// Content in boot config will be applied again after reading file.
+//
+// Reloads of entry are serialized, a new reload cancels context passed to handlers of the running one,
+// and reloads waiting behind it are skipped, so that only the latest change is applied.
+func (entry *ConfigEntry) Reload() error {
+	c := entry.supersedeReload()
+
+	entry.reloadLock.Lock()
+	defer entry.reloadLock.Unlock()
+
+	// superseded while waiting for previous reload
+	if c.Err() != nil {
+		return nil
+	}
+
+	if len(entry.Path) < 1 || (!fileExists(entry.Path) && !dirExists(entry.Path)) {
+		return fmt.Errorf("config file is missing, entry:%s, path:%s", entry.entryName, entry.Path)
+	}
+
+	before := entry.Checksum()
+	entry.viperLock.Lock()
+	if err := entry.readConfig(); err != nil {
+		entry.viperLock.Unlock()
+		return err
+	}
+
+	for k, v := range entry.content {
+		entry.Viper.Set(k, v)
+	}
+	entry.viperLock.Unlock()
+
+	entry.refreshSecrets()
+
+	GlobalAppCtx.GetLoggerEntryDefault().Info("Config reloaded",
+		zap.String("entryName", entry.entryName),
+		zap.String("checksum", before+"->"+entry.Checksum()),
+		zap.String("version", entry.Version()))
+
+	entry.changeLock.Lock()
+	handlers := append([]ConfigChangeHandler{}, entry.changeHandlers...)
+	entry.changeLock.Unlock()
+
+	for i := range handlers {
+		if c.Err() != nil {
+			break
+		}
+		handlers[i](c, entry)
+	}
+
+	return nil
+}
+
+// OnChange register handler called after config reloaded, handlers of the same entry never run concurrently.
+//
+// Expensive handler should stop once ctx is canceled, which means a newer reload is waiting.
+func (entry *ConfigEntry) OnChange(handler ConfigChangeHandler) {
+	if handler == nil {
+		return
+	}
+
+	entry.changeLock.Lock()
+	defer entry.changeLock.Unlock()
+
+	entry.changeHandlers = append(entry.changeHandlers, handler)
+}
+
+// supersedeReload cancels context of previous reload and returns context of a new one
+func (entry *ConfigEntry) supersedeReload() context.Context {
+	entry.changeLock.Lock()
+	defer entry.changeLock.Unlock()
+
+	if entry.changeCancel != nil {
+		entry.changeCancel()
+	}
+
+	c, cancel := context.WithCancel(context.Background())
+	entry.changeCancel = cancel
+
+	return c
+}
+
+// cancelReload cancels context of running reload
+func (entry *ConfigEntry) cancelReload() {
+	entry.changeLock.Lock()
+	defer entry.changeLock.Unlock()
+
+	if entry.changeCancel != nil {
+		entry.changeCancel()
+		entry.changeCancel = nil
+	}
+}
+
+// Unmarshal decodes value of key into target and validates it with ValidateStruct if target is a struct.
+// The whole config is decoded if key is empty, use Viper.Unmarshal for decoder options of viper.
+//
+// Values are converted weakly like viper, for example, "1" into int and "1s" into time.Duration.
+func (entry *ConfigEntry) Unmarshal(key string, target interface{}) error {
+	return entry.unmarshal(key, target, false)
+}
+
+// UnmarshalExact works like Unmarshal and returns error if there are keys missing in target.
+func (entry *ConfigEntry) UnmarshalExact(key string, target interface{}) error {
+	return entry.unmarshal(key, target, true)
+}
+
+// unmarshal decodes value of key into target with mapstructure
+func (entry *ConfigEntry) unmarshal(key string, target interface{}, exact bool) error {
+	var raw interface{}
+	if len(key) < 1 {
+		raw = entry.AllSettings()
+	} else {
+		if !entry.IsSet(key) {
+			return fmt.Errorf("key is missing in config entry, entry:%s, key:%s", entry.entryName, key)
+		}
+		raw = entry.Get(key)
+	}
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           target,
+		WeaklyTypedInput: true,
+		ErrorUnused:      exact,
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToSliceHookFunc(",")),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := decoder.Decode(raw); err != nil {
+		return fmt.Errorf("failed to unmarshal config entry, entry:%s, key:%s, %v", entry.entryName, key, err)
+	}
+
+	if v := reflect.Indirect(reflect.ValueOf(target)); v.Kind() == reflect.Struct {
+		errs := ValidateStruct(target)
+		if len(errs) > 0 {
+			msgs := make([]string, 0, len(errs))
+			for i := range errs {
+				msgs = append(msgs, errs[i].Error())
+			}
+			return fmt.Errorf("invalid config entry, entry:%s, key:%s, [%s]", entry.entryName, key, strings.Join(msgs, "; "))
+		}
+	}
+
+	return nil
+}
+
+// MarkSecret marks keys as secret, resolved values of them will be redacted in fields of every
+// LoggerEntry and EventEntry. Values are resolved again on Reload, values shorter than 4 characters are ignored.
+func (entry *ConfigEntry) MarkSecret(keys ...string) {
+	for _, key := range keys {
+		if len(key) > 0 {
+			entry.secretKeys = append(entry.secretKeys, strings.ToLower(key))
+		}
+	}
+
+	entry.refreshSecrets()
+}
+
+// refreshSecrets resolves values of secret keys into registry of secret values
+func (entry *ConfigEntry) refreshSecrets() {
+	for _, key := range entry.secretKeys {
+		secretValues.set(entry.entryName+"/"+key, entry.GetString(key))
+	}
+}
+
+// ExplainKey returns effective value of key and the source it came from.
+//
+// Sources are checked with the same precedence as viper, content in boot config first,
+// then environment variable with EnvPrefix, then config file.
+// ConfigSourceRuntime is returned for values set with viper directly, like defaults.
+func (entry *ConfigEntry) ExplainKey(key string) (interface{}, string) {
+	key = strings.ToLower(key)
+	value := entry.Get(key)
+
+	// nested key like a.b is also from content if a is in content
+	for k := range entry.content {
+		if k = strings.ToLower(k); k == key || strings.HasPrefix(key, k+".") {
+			return value, ConfigSourceContent
+		}
+	}
+
+	envKey := strings.ToUpper(key)
+	if len(entry.EnvPrefix) > 0 {
+		envKey = strings.ToUpper(entry.EnvPrefix + "_" + key)
+	}
+	if v, ok := os.LookupEnv(envKey); ok && len(v) > 0 {
+		return value, ConfigSourceEnv
+	}
+
+	entry.viperLock.RLock()
+	fromFile := entry.fileKeys[key]
+	entry.viperLock.RUnlock()
+	if fromFile {
+		return value, ConfigSourceFile
+	}
+
+	if entry.IsSet(key) {
+		return value, ConfigSourceRuntime
+	}
+
+	return nil, ConfigSourceMissing
+}
+
+// readConfig read config file of path into viper, caller should hold viperLock if entry is in use.
+//
+// If path is a directory like ConfigMap of Kubernetes mounted as volume, each file in it is a key with
+// content of file as value. Hidden files like ..data which are maintained by kubelet are skipped.
+func (entry *ConfigEntry) readConfig() error {
+	if !dirExists(entry.Path) {
+		entry.Viper.SetConfigFile(entry.Path)
+		if err := entry.Viper.ReadInConfig(); err != nil {
+			return fmt.Errorf("failed to read file, path:%s, %v", entry.Path, err)
+		}
+		entry.fileKeys = listKeysOfFile(entry.Path)
+		return nil
+	}
+
+	values, err := readConfigMapDir(entry.Path)
+	if err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	entry.Viper.SetConfigType("json")
+	if err := entry.Viper.ReadConfig(bytes.NewReader(raw)); err != nil {
+		return fmt.Errorf("failed to read directory, path:%s, %v", entry.Path, err)
+	}
+
+	entry.fileKeys = make(map[string]bool)
+	for k := range values {
+		entry.fileKeys[strings.ToLower(k)] = true
+	}
+
+	return nil
+}
+
+// ****************************************
+// ****** Synchronized viper related ******
+// ****************************************
+
+// Get calls Get of viper with read lock of entry.
+func (entry *ConfigEntry) Get(key string) interface{} {
+	entry.viperLock.RLock()
+	defer entry.viperLock.RUnlock()
+
+	return entry.Viper.Get(key)
+}
+
+// GetString calls GetString of viper with read lock of entry.
+func (entry *ConfigEntry) GetString(key string) string {
+	entry.viperLock.RLock()
+	defer entry.viperLock.RUnlock()
+
+	return entry.Viper.GetString(key)
+}
+
+// GetBool calls GetBool of viper with read lock of entry.
+func (entry *ConfigEntry) GetBool(key string) bool {
+	entry.viperLock.RLock()
+	defer entry.viperLock.RUnlock()
+
+	return entry.Viper.GetBool(key)
+}
+
+// GetInt calls GetInt of viper with read lock of entry.
+func (entry *ConfigEntry) GetInt(key string) int {
+	entry.viperLock.RLock()
+	defer entry.viperLock.RUnlock()
+
+	return entry.Viper.GetInt(key)
+}
+
+// GetInt32 calls GetInt32 of viper with read lock of entry.
+func (entry *ConfigEntry) GetInt32(key string) int32 {
+	entry.viperLock.RLock()
+	defer entry.viperLock.RUnlock()
+
+	return entry.Viper.GetInt32(key)
+}
+
+// GetInt64 calls GetInt64 of viper with read lock of entry.
+func (entry *ConfigEntry) GetInt64(key string) int64 {
+	entry.viperLock.RLock()
+	defer entry.viperLock.RUnlock()
+
+	return entry.Viper.GetInt64(key)
+}
+
+// GetUint calls GetUint of viper with read lock of entry.
+func (entry *ConfigEntry) GetUint(key string) uint {
+	entry.viperLock.RLock()
+	defer entry.viperLock.RUnlock()
+
+	return entry.Viper.GetUint(key)
+}
+
+// GetUint32 calls GetUint32 of viper with read lock of entry.
+func (entry *ConfigEntry) GetUint32(key string) uint32 {
+	entry.viperLock.RLock()
+	defer entry.viperLock.RUnlock()
+
+	return entry.Viper.GetUint32(key)
+}
+
+// GetUint64 calls GetUint64 of viper with read lock of entry.
+func (entry *ConfigEntry) GetUint64(key string) uint64 {
+	entry.viperLock.RLock()
+	defer entry.viperLock.RUnlock()
+
+	return entry.Viper.GetUint64(key)
+}
+
+// GetFloat64 calls GetFloat64 of viper with read lock of entry.
+func (entry *ConfigEntry) GetFloat64(key string) float64 {
+	entry.viperLock.RLock()
+	defer entry.viperLock.RUnlock()
+
+	return entry.Viper.GetFloat64(key)
+}
+
+// GetTime calls GetTime of viper with read lock of entry.
+func (entry *ConfigEntry) GetTime(key string) time.Time {
+	entry.viperLock.RLock()
+	defer entry.viperLock.RUnlock()
+
+	return entry.Viper.GetTime(key)
+}
+
+// GetDuration calls GetDuration of viper with read lock of entry.
+func (entry *ConfigEntry) GetDuration(key string) time.Duration {
+	entry.viperLock.RLock()
+	defer entry.viperLock.RUnlock()
+
+	return entry.Viper.GetDuration(key)
+}
+
+// GetIntSlice calls GetIntSlice of viper with read lock of entry.
+func (entry *ConfigEntry) GetIntSlice(key string) []int {
+	entry.viperLock.RLock()
+	defer entry.viperLock.RUnlock()
+
+	return entry.Viper.GetIntSlice(key)
+}
+
+// GetStringSlice calls GetStringSlice of viper with read lock of entry.
+func (entry *ConfigEntry) GetStringSlice(key string) []string {
+	entry.viperLock.RLock()
+	defer entry.viperLock.RUnlock()
+
+	return entry.Viper.GetStringSlice(key)
+}
+
+// GetStringMap calls GetStringMap of viper with read lock of entry.
+func (entry *ConfigEntry) GetStringMap(key string) map[string]interface{} {
+	entry.viperLock.RLock()
+	defer entry.viperLock.RUnlock()
+
+	return entry.Viper.GetStringMap(key)
+}
+
+// GetStringMapString calls GetStringMapString of viper with read lock of entry.
+func (entry *ConfigEntry) GetStringMapString(key string) map[string]string {
+	entry.viperLock.RLock()
+	defer entry.viperLock.RUnlock()
+
+	return entry.Viper.GetStringMapString(key)
+}
+
+// GetStringMapStringSlice calls GetStringMapStringSlice of viper with read lock of entry.
+func (entry *ConfigEntry) GetStringMapStringSlice(key string) map[string][]string {
+	entry.viperLock.RLock()
+	defer entry.viperLock.RUnlock()
+
+	return entry.Viper.GetStringMapStringSlice(key)
+}
+
+// GetSizeInBytes calls GetSizeInBytes of viper with read lock of entry.
+func (entry *ConfigEntry) GetSizeInBytes(key string) uint {
+	entry.viperLock.RLock()
+	defer entry.viperLock.RUnlock()
+
+	return entry.Viper.GetSizeInBytes(key)
+}
+
+// IsSet calls IsSet of viper with read lock of entry.
+func (entry *ConfigEntry) IsSet(key string) bool {
+	entry.viperLock.RLock()
+	defer entry.viperLock.RUnlock()
+
+	return entry.Viper.IsSet(key)
+}
+
+// InConfig calls InConfig of viper with read lock of entry.
+func (entry *ConfigEntry) InConfig(key string) bool {
+	entry.viperLock.RLock()
+	defer entry.viperLock.RUnlock()
+
+	return entry.Viper.InConfig(key)
+}
+
+// AllKeys calls AllKeys of viper with read lock of entry.
+func (entry *ConfigEntry) AllKeys() []string {
+	entry.viperLock.RLock()
+	defer entry.viperLock.RUnlock()
+
+	return entry.Viper.AllKeys()
+}
+
+// AllSettings calls AllSettings of viper with read lock of entry.
+func (entry *ConfigEntry) AllSettings() map[string]interface{} {
+	entry.viperLock.RLock()
+	defer entry.viperLock.RUnlock()
+
+	return entry.Viper.AllSettings()
+}
+
+// Set calls Set of viper with write lock of entry.
+func (entry *ConfigEntry) Set(key string, value interface{}) {
+	entry.viperLock.Lock()
+	defer entry.viperLock.Unlock()
+
+	entry.Viper.Set(key, value)
+}
+
+// SetDefault calls SetDefault of viper with write lock of entry.
+func (entry *ConfigEntry) SetDefault(key string, value interface{}) {
+	entry.viperLock.Lock()
+	defer entry.viperLock.Unlock()
+
+	entry.Viper.SetDefault(key, value)
+}
+
+// readConfigMapDir read files in directory as key value pairs, trailing newline of value is trimmed
+func readConfigMapDir(dir string) (map[string]interface{}, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory, path:%s, %v", dir, err)
+	}
+
+	res := make(map[string]interface{})
+	for _, file := range files {
+		if strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+
+		filePath := filepath.Join(dir, file.Name())
+		if !fileExists(filePath) {
+			continue
+		}
+
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file, path:%s, %v", filePath, err)
+		}
+		res[file.Name()] = strings.TrimRight(string(content), "\r\n")
+	}
+
+	return res, nil
+}
+
+// startWatch watches directory of config, either config file or ConfigMap directory.
+// Getters of ConfigEntry are safe to call while reloading, refer to ConfigEntry.
+// Reload runs in background, so that a new change could cancel handlers of the running one.
+//
+// Directory is watched instead of file, since Kubernetes updates ConfigMap by swapping ..data symlink atomically,
+// which never emits event of file itself. Config is reloaded if file was written or target of symlink changed.
+func (entry *ConfigEntry) startWatch() error {
+	entry.watchLock.Lock()
+	defer entry.watchLock.Unlock()
+
+	if entry.watcher != nil {
+		return nil
+	}
+
+	if len(entry.Path) < 1 {
+		return fmt.Errorf("config file is missing, entry:%s", entry.entryName)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	// symlink which would be swapped, file itself or ..data of ConfigMap directory
+	isDir := dirExists(entry.Path)
+	dir, link := filepath.Dir(entry.Path), entry.Path
+	if isDir {
+		dir, link = entry.Path, filepath.Join(entry.Path, "..data")
+	}
+
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return err
+	}
+
+	realPath, _ := filepath.EvalSymlinks(link)
+
+	entry.watcher = watcher
+	entry.watchWait.Add(1)
+	go func() {
+		defer entry.watchWait.Done()
+
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				currRealPath, _ := filepath.EvalSymlinks(link)
+				changed := len(currRealPath) > 0 && currRealPath != realPath
+				realPath = currRealPath
+
+				written := event.Op&(fsnotify.Write|fsnotify.Create) != 0 && filepath.Clean(event.Name) == filepath.Clean(entry.Path)
+				if isDir {
+					written = event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) != 0 &&
+						!strings.HasPrefix(filepath.Base(event.Name), ".")
+				}
+
+				if !changed && !written {
+					continue
+				}
+
+				entry.watchWait.Add(1)
+				go func() {
+					defer entry.watchWait.Done()
+
+					if err := entry.Reload(); err != nil {
+						GlobalAppCtx.GetLoggerEntryDefault().Warn("Failed to reload watched config",
+							zap.String("entryName", entry.entryName),
+							zap.String("path", entry.Path),
+							zap.Error(err))
+						return
+					}
+					entry.watchReloads.Inc()
+				}()
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				GlobalAppCtx.GetLoggerEntryDefault().Warn("Error occurs while watching config",
+					zap.String("entryName", entry.entryName),
+					zap.Error(err))
+			}
+		}
+	}()
+
+	return nil
+}
+
+// stopWatch stops watcher, cancels handlers of running reload and waits for it
+func (entry *ConfigEntry) stopWatch() {
+	entry.watchLock.Lock()
+	watcher := entry.watcher
+	entry.watcher = nil
+	entry.watchLock.Unlock()
+
+	if watcher != nil {
+		watcher.Close()
+		entry.cancelReload()
+		entry.watchWait.Wait()
+	}
+}
+
+// listKeysOfFile returns keys in config file, empty if failed to read
+func listKeysOfFile(filePath string) map[string]bool {
+	res := make(map[string]bool)
+
+	v := viper.New()
+	v.SetConfigFile(filePath)
+	if err := v.ReadInConfig(); err != nil {
+		return res
+	}
+
+	for _, k := range v.AllKeys() {
+		res[k] = true
+	}
+
+	return res
+}
+
+// DeprecateKey mark key as deprecated and replaced by newKey.
+//
+// If old key is present in config, a warning will be logged once through default LoggerEntry.
+// If migrate is true and newKey is not set, value of old key will be copied to newKey.
+func (entry *ConfigEntry) DeprecateKey(old, newKey string, migrate bool) {
+	entry.deprecatedLock.Lock()
+	defer entry.deprecatedLock.Unlock()
+
+	if entry.deprecatedKeys == nil {
+		entry.deprecatedKeys = make(map[string]string)
+		entry.deprecatedInUse = make(map[string]bool)
+	}
+
+	entry.deprecatedKeys[old] = newKey
+
+	if !entry.IsSet(old) {
+		return
+	}
+
+	// warn only once for each key
+	if !entry.deprecatedInUse[old] {
+		entry.deprecatedInUse[old] = true
+		GlobalAppCtx.GetLoggerEntryDefault().Warn("Deprecated config key is in use",
+			zap.String("entryName", entry.GetName()),
+			zap.String("key", old),
+			zap.String("replacement", newKey))
+	}
+
+	if migrate && len(newKey) > 0 {
+		entry.viperLock.Lock()
+		if !entry.Viper.IsSet(newKey) {
+			entry.Viper.Set(newKey, entry.Viper.Get(old))
+		}
+		entry.viperLock.Unlock()
+	}
+}
+
+// ListDeprecatedKeysInUse returns deprecated keys which are still present in config, mapped to replacement keys.
+func (entry *ConfigEntry) ListDeprecatedKeysInUse() map[string]string {
+	entry.deprecatedLock.Lock()
+	defer entry.deprecatedLock.Unlock()
+
+	res := make(map[string]string)
+	for k := range entry.deprecatedInUse {
+		res[k] = entry.deprecatedKeys[k]
+	}
+
+	return res
+}
+
+// FlagSet resolves feature flags from flags.* keys of ConfigEntry.
+//
+// Values are read from ConfigEntry on every call, so changes on the underlying viper instance
+// will flip flags live.
+type FlagSet struct {
+	config *ConfigEntry
+}
+
+// NewFlagSet create FlagSet backed by ConfigEntry.
+func NewFlagSet(config *ConfigEntry) *FlagSet {
+	return &FlagSet{
+		config: config,
+	}
+}
+
+// IsEnabled returns value of flags.<name>, false if ConfigEntry or flag is missing.
+func (f *FlagSet) IsEnabled(name string) bool {
+	if f == nil || f.config == nil || f.config.Viper == nil {
+		return false
+	}
+
+	return f.config.GetBool("flags." + name)
+}
+
+// List returns all flags in ConfigEntry.
+func (f *FlagSet) List() map[string]bool {
+	res := make(map[string]bool)
+	if f == nil || f.config == nil || f.config.Viper == nil {
+		return res
+	}
+
+	for k := range f.config.GetStringMap("flags") {
+		res[k] = f.config.GetBool("flags." + k)
+	}
+
+	return res
+}
@@ -6,10 +6,15 @@ package rkentry
 
 import (
 	"context"
+	"errors"
+	"github.com/rookie-ninja/rk-logger"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestRegisterConfigEntry(t *testing.T) {
@@ -292,3 +297,436 @@ func TestConfigEntry_Interrupt(t *testing.T) {
 	})
 	entry[0].Interrupt(context.Background())
 }
+
+func TestConfigEntry_DeprecateKey(t *testing.T) {
+	defer assertNotPanic(t)
+
+	entries := RegisterConfigEntry(&BootConfig{
+		Config: []*BootConfigE{
+			{
+				Name: "ut-config",
+				Content: map[string]interface{}{
+					"oldKey":   "value",
+					"otherKey": "other",
+				},
+			},
+		},
+	})
+	entry := entries[0]
+
+	// key not present
+	entry.DeprecateKey("missing", "newMissing", true)
+	assert.False(t, entry.IsSet("newMissing"))
+
+	// key present without migration
+	entry.DeprecateKey("otherKey", "newOtherKey", false)
+	assert.False(t, entry.IsSet("newOtherKey"))
+
+	// key present with migration
+	entry.DeprecateKey("oldKey", "newKey", true)
+	assert.Equal(t, "value", entry.GetString("newKey"))
+
+	// call again
+	entry.DeprecateKey("oldKey", "newKey", true)
+
+	assert.Equal(t, map[string]string{
+		"oldKey":   "newKey",
+		"otherKey": "newOtherKey",
+	}, entry.ListDeprecatedKeysInUse())
+}
+
+func TestFlagSet(t *testing.T) {
+	GlobalAppCtx.RemoveEntryByType(ConfigEntryType)
+	defer GlobalAppCtx.RemoveEntryByType(ConfigEntryType)
+
+	// without config entry
+	assert.False(t, GlobalAppCtx.IsFeatureEnabled("ut-flag"))
+	assert.Empty(t, NewFlagSet(nil).List())
+
+	entries := RegisterConfigEntry(&BootConfig{
+		Config: []*BootConfigE{
+			{
+				Name: "ut-config",
+				Content: map[string]interface{}{
+					"flags": map[string]interface{}{
+						"enabled":  true,
+						"disabled": false,
+					},
+				},
+			},
+		},
+	})
+
+	assert.Equal(t, entries[0], GlobalAppCtx.GetConfigEntryDefault())
+	assert.True(t, GlobalAppCtx.IsFeatureEnabled("enabled"))
+	assert.False(t, GlobalAppCtx.IsFeatureEnabled("disabled"))
+	assert.False(t, GlobalAppCtx.IsFeatureEnabled("missing"))
+	assert.Equal(t, map[string]bool{"enabled": true, "disabled": false}, NewFlagSet(entries[0]).List())
+
+	// flip flag live
+	entries[0].Set("flags.disabled", true)
+	assert.True(t, GlobalAppCtx.IsFeatureEnabled("disabled"))
+
+	// with more than one config entry, default one will be used
+	RegisterConfigEntry(&BootConfig{
+		Config: []*BootConfigE{
+			{
+				Name:    "ut-config-default",
+				Default: true,
+			},
+		},
+	})
+	assert.Equal(t, "ut-config-default", GlobalAppCtx.GetConfigEntryDefault().GetName())
+	assert.False(t, GlobalAppCtx.IsFeatureEnabled("enabled"))
+}
+
+func TestConfigEntry_Checksum(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(ConfigEntryType)
+
+	filePath := filepath.Join(t.TempDir(), "ut-config.yaml")
+	assert.Nil(t, os.WriteFile(filePath, []byte("version: v1\nkey: value"), os.ModePerm))
+
+	entries := RegisterConfigEntry(&BootConfig{
+		Config: []*BootConfigE{
+			{
+				Name: "ut-config",
+				Path: filePath,
+			},
+			{
+				Name:    "ut-config-same",
+				Content: map[string]interface{}{"key": "value", "version": "v1"},
+			},
+		},
+	})
+	assert.Len(t, entries, 2)
+
+	entry := GlobalAppCtx.GetConfigEntry("ut-config")
+	checksum := entry.Checksum()
+	assert.Len(t, checksum, 64)
+	assert.Equal(t, checksum, GlobalAppCtx.GetConfigEntry("ut-config-same").Checksum())
+	assert.Equal(t, "v1", entry.Version())
+	assert.Equal(t, checksum, NewProcessInfo().ConfigChecksums["ut-config"])
+
+	// reload
+	assert.Nil(t, os.WriteFile(filePath, []byte("version: v2\nkey: value"), os.ModePerm))
+	assert.Nil(t, entry.Reload())
+	assert.Equal(t, "v2", entry.Version())
+	assert.NotEqual(t, checksum, entry.Checksum())
+
+	// reload without file
+	assert.NotNil(t, GlobalAppCtx.GetConfigEntry("ut-config-same").Reload())
+}
+
+func TestConfigEntry_Reload_WithConcurrentRead(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(ConfigEntryType)
+
+	filePath := filepath.Join(t.TempDir(), "ut-config.yaml")
+	assert.Nil(t, os.WriteFile(filePath, []byte("flags:\n  ut-flag: true"), os.ModePerm))
+
+	RegisterConfigEntry(&BootConfig{
+		Config: []*BootConfigE{
+			{
+				Name: "ut-config",
+				Path: filePath,
+			},
+		},
+	})
+	entry := GlobalAppCtx.GetConfigEntry("ut-config")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			assert.Nil(t, entry.Reload())
+		}
+	}()
+
+	for {
+		select {
+		case <-done:
+			assert.True(t, GlobalAppCtx.IsFeatureEnabled("ut-flag"))
+			return
+		default:
+			GlobalAppCtx.IsFeatureEnabled("ut-flag")
+			entry.ExplainKey("flags.ut-flag")
+			entry.Checksum()
+		}
+	}
+}
+
+func TestConfigEntry_OnChange(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(ConfigEntryType)
+
+	filePath := filepath.Join(t.TempDir(), "ut-config.yaml")
+	assert.Nil(t, os.WriteFile(filePath, []byte("version: v1"), os.ModePerm))
+
+	entries := RegisterConfigEntry(&BootConfig{
+		Config: []*BootConfigE{
+			{
+				Name: "ut-config-change",
+				Path: filePath,
+			},
+		},
+	})
+	assert.Len(t, entries, 1)
+	entry := entries[0]
+
+	started := make(chan struct{})
+	canceled := make(chan struct{})
+	var calls, active, maxActive atomic.Int64
+	entry.OnChange(nil)
+	entry.OnChange(func(ctx context.Context, entry *ConfigEntry) {
+		if n := active.Inc(); n > maxActive.Load() {
+			maxActive.Store(n)
+		}
+		defer active.Dec()
+
+		// first reload waits until superseded
+		if calls.Inc() == 1 {
+			close(started)
+			select {
+			case <-ctx.Done():
+				close(canceled)
+			case <-time.After(5 * time.Second):
+			}
+		}
+	})
+	var followed atomic.Int64
+	entry.OnChange(func(ctx context.Context, entry *ConfigEntry) {
+		followed.Inc()
+	})
+
+	done := make(chan error)
+	go func() {
+		done <- entry.Reload()
+	}()
+	<-started
+
+	assert.Nil(t, os.WriteFile(filePath, []byte("version: v2"), os.ModePerm))
+	assert.Nil(t, entry.Reload())
+	assert.Nil(t, <-done)
+
+	select {
+	case <-canceled:
+	default:
+		assert.Fail(t, "context of superseded reload is not canceled")
+	}
+	assert.Equal(t, int64(2), calls.Load())
+	assert.Equal(t, int64(1), maxActive.Load())
+	// handlers after canceled one are skipped
+	assert.Equal(t, int64(1), followed.Load())
+	assert.Equal(t, "v2", entry.Version())
+}
+
+func TestConfigEntry_ExplainKey(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(ConfigEntryType)
+	defer os.Unsetenv("UT_ENV_KEY")
+
+	filePath := filepath.Join(t.TempDir(), "ut-config.yaml")
+	assert.Nil(t, os.WriteFile(filePath, []byte("file:\n  key: file-value\ncontent-key: file-value\nenv_key: file-value"), os.ModePerm))
+	assert.Nil(t, os.Setenv("UT_ENV_KEY", "env-value"))
+
+	entries := RegisterConfigEntry(&BootConfig{
+		Config: []*BootConfigE{
+			{
+				Name:      "ut-config-explain",
+				Path:      filePath,
+				EnvPrefix: "ut",
+				Content:   map[string]interface{}{"content-key": "content-value"},
+			},
+		},
+	})
+	assert.Len(t, entries, 1)
+	entry := entries[0]
+	entry.SetDefault("default-key", "default-value")
+
+	value, source := entry.ExplainKey("file.key")
+	assert.Equal(t, "file-value", value)
+	assert.Equal(t, ConfigSourceFile, source)
+
+	value, source = entry.ExplainKey("content-key")
+	assert.Equal(t, "content-value", value)
+	assert.Equal(t, ConfigSourceContent, source)
+
+	value, source = entry.ExplainKey("ENV_KEY")
+	assert.Equal(t, "env-value", value)
+	assert.Equal(t, ConfigSourceEnv, source)
+
+	value, source = entry.ExplainKey("default-key")
+	assert.Equal(t, "default-value", value)
+	assert.Equal(t, ConfigSourceRuntime, source)
+
+	value, source = entry.ExplainKey("missing-key")
+	assert.Nil(t, value)
+	assert.Equal(t, ConfigSourceMissing, source)
+}
+
+func TestConfigEntry_MarkSecret(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(ConfigEntryType)
+	defer GlobalAppCtx.RemoveEntryByType(LoggerEntryType)
+	defer secretValues.clear()
+
+	filePath := filepath.Join(t.TempDir(), "ut-config.yaml")
+	assert.Nil(t, os.WriteFile(filePath, []byte("db:\n  password: ut-file-secret"), os.ModePerm))
+
+	entries := RegisterConfigEntry(&BootConfig{
+		Config: []*BootConfigE{
+			{
+				Name:    "ut-config-secret",
+				Path:    filePath,
+				Content: map[string]interface{}{"token": "ut-content-secret", "short": "abc"},
+				Secrets: []string{"db.password", "token"},
+			},
+		},
+	})
+	assert.Len(t, entries, 1)
+	entry := entries[0]
+	entry.MarkSecret("short")
+
+	// short values are ignored
+	assert.Equal(t, 2, secretValues.len())
+
+	logPath := filepath.Join(t.TempDir(), "ut.log")
+	loggers := RegisterLoggerEntry(&BootLogger{
+		Logger: []*BootLoggerE{
+			{
+				Name: "ut-logger-secret",
+				Zap: &rklogger.ZapConfigWrap{
+					OutputPaths: []string{logPath},
+				},
+			},
+		},
+	})
+	loggers[0].Info("token is ut-content-secret",
+		zap.String("dsn", "user:ut-file-secret@localhost"),
+		zap.Error(errors.New("invalid token ut-content-secret")),
+		zap.String("short", "abc"))
+	loggers[0].Sync()
+
+	content, err := os.ReadFile(logPath)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(content), "ut-content-secret")
+	assert.NotContains(t, string(content), "ut-file-secret")
+	assert.Contains(t, string(content), "user:***@localhost")
+	assert.Contains(t, string(content), "abc")
+
+	// values are resolved again on reload
+	assert.Nil(t, os.WriteFile(filePath, []byte("db:\n  password: ut-reloaded-secret"), os.ModePerm))
+	assert.Nil(t, entry.Reload())
+	assert.Equal(t, "***", secretValues.redact("ut-reloaded-secret"))
+	assert.Equal(t, "ut-file-secret", secretValues.redact("ut-file-secret"))
+}
+
+func TestConfigEntry_Unmarshal(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(ConfigEntryType)
+
+	filePath := filepath.Join(t.TempDir(), "ut-config.yaml")
+	assert.Nil(t, os.WriteFile(filePath, []byte("db:\n  addr: localhost\n  port: \"3306\"\n  timeout: 1s\n  extra: value"), os.ModePerm))
+
+	entry := RegisterConfigEntry(&BootConfig{
+		Config: []*BootConfigE{
+			{
+				Name: "ut-config-unmarshal",
+				Path: filePath,
+			},
+		},
+	})[0]
+
+	type db struct {
+		Addr    string        `yaml:"addr" validate:"required"`
+		Port    int           `yaml:"port" validate:"min=1"`
+		Timeout time.Duration `yaml:"timeout"`
+	}
+
+	// weakly typed values
+	res := &db{}
+	assert.Nil(t, entry.Unmarshal("db", res))
+	assert.Equal(t, "localhost", res.Addr)
+	assert.Equal(t, 3306, res.Port)
+	assert.Equal(t, time.Second, res.Timeout)
+
+	// whole config
+	whole := &struct {
+		DB db `yaml:"db"`
+	}{}
+	assert.Nil(t, entry.Unmarshal("", whole))
+	assert.Equal(t, 3306, whole.DB.Port)
+
+	// exact with unused key
+	err := entry.UnmarshalExact("db", &db{})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "extra")
+
+	// missing key
+	assert.NotNil(t, entry.Unmarshal("missing", &db{}))
+
+	// validation
+	entry.Set("db.port", 0)
+	err = entry.Unmarshal("db", &db{})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "min")
+}
+
+func TestConfigEntry_WatchConfigMap(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(ConfigEntryType)
+
+	// layout of ConfigMap mounted by kubelet, keys are symlinks to ..data which links to timestamped directory
+	dir := t.TempDir()
+	writeVersion := func(version, value string) {
+		assert.Nil(t, os.MkdirAll(filepath.Join(dir, version), os.ModePerm))
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, version, "db-host"), []byte(value+"\n"), os.ModePerm))
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, version, "config.yaml"), []byte("key: "+value), os.ModePerm))
+
+		// swap ..data atomically
+		assert.Nil(t, os.Symlink(version, filepath.Join(dir, "..data_tmp")))
+		assert.Nil(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+	}
+	writeVersion("..v1", "v1")
+	assert.Nil(t, os.Symlink(filepath.Join("..data", "db-host"), filepath.Join(dir, "db-host")))
+	assert.Nil(t, os.Symlink(filepath.Join("..data", "config.yaml"), filepath.Join(dir, "config.yaml")))
+
+	entries := RegisterConfigEntry(&BootConfig{
+		Config: []*BootConfigE{
+			{
+				Name:  "ut-configmap-dir",
+				Path:  dir,
+				Watch: true,
+			},
+			{
+				Name:  "ut-configmap-file",
+				Path:  filepath.Join(dir, "config.yaml"),
+				Watch: true,
+			},
+		},
+	})
+	assert.Len(t, entries, 2)
+
+	dirEntry := GlobalAppCtx.GetConfigEntry("ut-configmap-dir")
+	fileEntry := GlobalAppCtx.GetConfigEntry("ut-configmap-file")
+	assert.Equal(t, "v1", dirEntry.GetString("db-host"))
+	_, source := dirEntry.ExplainKey("db-host")
+	assert.Equal(t, ConfigSourceFile, source)
+	assert.False(t, dirEntry.IsSet("..data"))
+	assert.Equal(t, "v1", fileEntry.GetString("key"))
+
+	dirEntry.Bootstrap(context.TODO())
+	fileEntry.Bootstrap(context.TODO())
+	defer dirEntry.Interrupt(context.TODO())
+	defer fileEntry.Interrupt(context.TODO())
+
+	// files are never written in place, only ..data is swapped
+	writeVersion("..v2", "v2")
+	assert.Eventually(t, func() bool {
+		return dirEntry.watchReloads.Load() > 0 && fileEntry.watchReloads.Load() > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// stop watchers so that no reload is running while asserting
+	dirEntry.Interrupt(context.TODO())
+	fileEntry.Interrupt(context.TODO())
+	assert.Equal(t, "v2", dirEntry.GetString("db-host"))
+	assert.Equal(t, "v2", fileEntry.GetString("key"))
+
+	// stopped watcher
+	dirEntry.Interrupt(context.TODO())
+	assert.Nil(t, dirEntry.watcher)
+}
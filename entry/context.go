@@ -7,10 +7,22 @@ package rkentry
 
 import (
 	"context"
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/rookie-ninja/rk-query"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v2"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 )
@@ -26,17 +38,21 @@ var (
 		},
 		embedFS:       map[string]map[string]*embed.FS{},
 		appInfoEntry:  appInfoEntryDefault(),
-		shutdownSig:   make(chan os.Signal),
+		shutdownSig:   make(chan os.Signal, 1),
 		shutdownHooks: make(map[string]ShutdownHook),
 		userValues:    make(map[string]interface{}),
+		lifecycle:     newLifecycleState(),
+		limitsWatcher: newLimitsWatcher(),
 	}
 
 	builtinRegFuncList = []RegFunc{
 		registerAppInfoEntryYAML,
+		registerAliasesYAML,
 		RegisterLoggerEntryYAML,
 		RegisterEventEntryYAML,
 		RegisterConfigEntryYAML,
 		RegisterCertEntryYAML,
+		registerEntriesFromFactoryYAML,
 	}
 	pluginRegFuncList   = make([]RegFunc, 0)
 	webFrameRegFuncList = make([]RegFunc, 0)
@@ -54,28 +70,92 @@ type ShutdownHook func()
 type ReadinessCheck func(req *http.Request, resp http.ResponseWriter) bool
 type LivenessCheck func(req *http.Request, resp http.ResponseWriter) bool
 
+// TraceIDExtractor extracts trace id from context, returns empty string if missing.
+type TraceIDExtractor func(ctx context.Context) string
+
+// ReadinessProbe checks whether a dependency is ready, returns error if not.
+type ReadinessProbe func(ctx context.Context) error
+
+// ProbeResult is result of a ReadinessProbe.
+type ProbeResult struct {
+	Name      string        `json:"name" yaml:"name"`
+	Err       error         `json:"-" yaml:"-"`
+	Latency   time.Duration `json:"latency" yaml:"latency"`
+	CheckedAt time.Time     `json:"checkedAt" yaml:"checkedAt"`
+	Cached    bool          `json:"cached" yaml:"cached"`
+	// Skipped is true if probe was not run since one of its dependencies is not ready
+	Skipped bool `json:"skipped" yaml:"skipped"`
+	// RootCause is name of the failed probe which caused this probe to be skipped
+	RootCause string `json:"rootCause,omitempty" yaml:"rootCause,omitempty"`
+}
+
+// readinessProbe wraps ReadinessProbe with cached result.
+type readinessProbe struct {
+	name   string
+	probe  ReadinessProbe
+	ttl    time.Duration
+	deps   []string
+	lock   sync.Mutex
+	result *ProbeResult
+}
+
 // Init global app context with bellow fields.
 func init() {
-	signal.Notify(GlobalAppCtx.shutdownSig,
+	syncMiddlewareDomain()
+
+	osSig := make(chan os.Signal, 1)
+	signal.Notify(osSig,
 		syscall.SIGHUP,
 		syscall.SIGINT,
 		syscall.SIGTERM,
 		syscall.SIGQUIT)
+
+	go func() {
+		for sig := range osSig {
+			GlobalAppCtx.triggerShutdown(sig)
+		}
+	}()
 }
 
 // Application context which contains bellow fields.
 // It is not recommended override this value since StartTime would be assigned to current time
 // at beginning of go process in init() function.
 type appContext struct {
-	startTime      time.Time                       `json:"-" yaml:"-"`
-	appInfoEntry   *appInfoEntry                   `json:"-" yaml:"-"`
-	readinessCheck ReadinessCheck                  `json:"-" yaml:"-"`
-	livenessCheck  LivenessCheck                   `json:"-" yaml:"-"`
-	entries        map[string]map[string]Entry     `json:"-" yaml:"-"`
-	embedFS        map[string]map[string]*embed.FS `json:"-" yaml:"-"`
-	userValues     map[string]interface{}          `json:"-" yaml:"-"`
-	shutdownSig    chan os.Signal                  `json:"-" yaml:"-"`
-	shutdownHooks  map[string]ShutdownHook         `json:"-" yaml:"-"`
+	startTime        time.Time                       `json:"-" yaml:"-"`
+	appInfoEntry     *appInfoEntry                   `json:"-" yaml:"-"`
+	readinessCheck   ReadinessCheck                  `json:"-" yaml:"-"`
+	livenessCheck    LivenessCheck                   `json:"-" yaml:"-"`
+	entries          map[string]map[string]Entry     `json:"-" yaml:"-"`
+	entriesLock      sync.RWMutex                    `json:"-" yaml:"-"`
+	embedFS          map[string]map[string]*embed.FS `json:"-" yaml:"-"`
+	userValues       map[string]interface{}          `json:"-" yaml:"-"`
+	shutdownSig      chan os.Signal                  `json:"-" yaml:"-"`
+	shutdownHooks    map[string]ShutdownHook         `json:"-" yaml:"-"`
+	probes           []*readinessProbe               `json:"-" yaml:"-"`
+	probeLock        sync.Mutex                      `json:"-" yaml:"-"`
+	traceIDExtract   TraceIDExtractor                `json:"-" yaml:"-"`
+	loggerStrictMode bool                            `json:"-" yaml:"-"`
+	missingLoggers   sync.Map                        `json:"-" yaml:"-"`
+	aliases          sync.Map                        `json:"-" yaml:"-"`
+	aliasesInUse     sync.Map                        `json:"-" yaml:"-"`
+	loggerReopenSig  chan os.Signal                  `json:"-" yaml:"-"`
+	loggerReopenLock sync.Mutex                      `json:"-" yaml:"-"`
+	lazyEntries      map[string]*lazyEntry           `json:"-" yaml:"-"`
+	lazyLock         sync.Mutex                      `json:"-" yaml:"-"`
+	shutdownTime     time.Time                       `json:"-" yaml:"-"`
+	shutdownLock     sync.RWMutex                    `json:"-" yaml:"-"`
+	lifecycle        *lifecycleState                 `json:"-" yaml:"-"`
+	limitsWatcher    *limitsWatcher                  `json:"-" yaml:"-"`
+	shutdownSigRecv  os.Signal                       `json:"-" yaml:"-"`
+	shutdownCtx      context.Context                 `json:"-" yaml:"-"`
+}
+
+// lazyEntry is entry registered with RegisterLazy
+type lazyEntry struct {
+	once      sync.Once
+	entryType string
+	factory   func() Entry
+	entry     Entry
 }
 
 // RegisterPluginRegFunc register rk plugins registration function.
@@ -138,6 +218,31 @@ func ListUserEntryRegFunc() []RegFunc {
 	return res
 }
 
+// ChainRegFuncs compose registration functions into one which runs them in order and merges returned entries.
+//
+// Nil functions are ignored. ShutdownWithError will be called if two functions returned different entries
+// with the same name.
+func ChainRegFuncs(funcs ...RegFunc) RegFunc {
+	return func(raw []byte) map[string]Entry {
+		res := make(map[string]Entry)
+		for i := range funcs {
+			if funcs[i] == nil {
+				continue
+			}
+
+			for name, entry := range funcs[i](raw) {
+				if prev, ok := res[name]; ok && prev != entry {
+					ShutdownWithError(fmt.Errorf("duplicate entry name returned by chained registration functions, name:%s, types:[%s,%s]",
+						name, prev.GetType(), entry.GetType()))
+				}
+				res[name] = entry
+			}
+		}
+
+		return res
+	}
+}
+
 // BootstrapBuiltInEntryFromYAML register and bootstrap builtin entries first
 func BootstrapBuiltInEntryFromYAML(raw []byte) {
 	ctx := context.Background()
@@ -186,6 +291,89 @@ func BootstrapUserEntryFromYAML(raw []byte) {
 	}
 }
 
+// RegisterInternalEntriesFromDir register builtin entries from every .yaml, .yml and .json file in dir.
+//
+// Files are read in lexical order and merged into one boot config before registration.
+// Entry lists like logger, event, config and cert are concatenated, other sections like app
+// are overridden by later files.
+// Imports of each file are resolved as well, keep imported fragments out of dir to avoid loading them twice.
+//
+// An error will be returned if the same entry name and domain was defined in more than one file.
+func RegisterInternalEntriesFromDir(dir string) (map[string]Entry, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := map[interface{}]interface{}{}
+	owners := map[string]string{}
+
+	for i := range files {
+		if files[i].IsDir() {
+			continue
+		}
+
+		filePath := filepath.Join(dir, files[i].Name())
+
+		switch strings.ToLower(filepath.Ext(filePath)) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+
+		bootM, err := readBootConfigWithImports(filePath, map[string]bool{}, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := mergeBootConfig(merged, bootM, filePath, owners); err != nil {
+			return nil, err
+		}
+	}
+
+	raw, err := yaml.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+
+	res := map[string]Entry{}
+	for i := range builtinRegFuncList {
+		for k, v := range builtinRegFuncList[i](raw) {
+			res[k] = v
+		}
+	}
+
+	return res, nil
+}
+
+// RequireEntries verifies each named entry was registered into GlobalAppCtx with any type.
+//
+// Call it after registration functions and before bootstrap, an error listing all missing
+// entries will be returned, so that a typo in boot config won't drop a critical entry silently.
+func RequireEntries(names ...string) error {
+	missing := make([]string, 0)
+
+	for i := range names {
+		found := false
+		for _, entries := range GlobalAppCtx.ListEntries() {
+			if _, ok := entries[names[i]]; ok {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			missing = append(missing, names[i])
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("required entries are missing, names:[%s]", strings.Join(missing, ","))
+	}
+
+	return nil
+}
+
 // AddEmbedFS add embed.FS based on name and type of Entry
 func (ctx *appContext) AddEmbedFS(entryType, entryName string, fs *embed.FS) {
 	if len(entryType) < 1 || len(entryName) < 1 || fs == nil {
@@ -218,6 +406,251 @@ func (ctx *appContext) SetLivenessCheck(f LivenessCheck) {
 	ctx.livenessCheck = f
 }
 
+// *************************************
+// ****** Readiness probe related ******
+// *************************************
+
+// AddReadinessProbe add readiness probe with name, probe with the same name will be replaced.
+//
+// Result of probe will be cached for ttl, repeated checks within ttl reuse the last result.
+// Zero ttl disables caching.
+func (ctx *appContext) AddReadinessProbe(name string, probe ReadinessProbe, ttl time.Duration) {
+	ctx.AddReadinessProbeWithDependencies(name, probe, ttl)
+}
+
+// AddReadinessProbeWithDependencies add readiness probe with name like AddReadinessProbe,
+// probe will be checked after probes it depends on, and skipped if any of them is not ready.
+func (ctx *appContext) AddReadinessProbeWithDependencies(name string, probe ReadinessProbe, ttl time.Duration, dependencies ...string) {
+	if len(name) < 1 || probe == nil {
+		return
+	}
+
+	ctx.probeLock.Lock()
+	defer ctx.probeLock.Unlock()
+
+	p := &readinessProbe{
+		name:  name,
+		probe: probe,
+		ttl:   ttl,
+		deps:  append([]string{}, dependencies...),
+	}
+
+	for i := range ctx.probes {
+		if ctx.probes[i].name == name {
+			ctx.probes[i] = p
+			return
+		}
+	}
+
+	ctx.probes = append(ctx.probes, p)
+}
+
+// RemoveReadinessProbe remove readiness probe with name.
+func (ctx *appContext) RemoveReadinessProbe(name string) bool {
+	ctx.probeLock.Lock()
+	defer ctx.probeLock.Unlock()
+
+	for i := range ctx.probes {
+		if ctx.probes[i].name == name {
+			ctx.probes = append(ctx.probes[:i], ctx.probes[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// ListReadinessProbes list names of readiness probes in order of registration.
+func (ctx *appContext) ListReadinessProbes() []string {
+	ctx.probeLock.Lock()
+	defer ctx.probeLock.Unlock()
+
+	res := make([]string, 0, len(ctx.probes))
+	for i := range ctx.probes {
+		res = append(res, ctx.probes[i].name)
+	}
+
+	return res
+}
+
+// CheckReadiness run readiness probes in order of registration, followed by Health of entries
+// implementing HealthReporter sorted by type/name.
+//
+// Dependencies of a probe are checked before it. Probe is skipped with RootCause of the first failed
+// probe in its dependencies, so that only the prerequisite is reported as failure.
+// Missing dependency or dependency cycle is reported as failure of probe.
+//
+// Cached results within TTL will be reused unless bypassCache is true, health of entries is never cached.
+// Concurrent checks of the same probe wait for the running one and share its result.
+func (ctx *appContext) CheckReadiness(c context.Context, bypassCache bool) []*ProbeResult {
+	ctx.probeLock.Lock()
+	probes := make([]*readinessProbe, len(ctx.probes))
+	copy(probes, ctx.probes)
+	ctx.probeLock.Unlock()
+
+	checker := &probeChecker{
+		probes:  make(map[string]*readinessProbe),
+		results: make(map[string]*ProbeResult),
+		visited: make(map[string]bool),
+		res:     make([]*ProbeResult, 0, len(probes)),
+	}
+	for i := range probes {
+		checker.probes[probes[i].name] = probes[i]
+	}
+	for i := range probes {
+		checker.check(c, probes[i], bypassCache)
+	}
+
+	return append(checker.res, ctx.checkEntryHealth(c)...)
+}
+
+// probeChecker checks probes after their dependencies, results are appended in order of checking
+type probeChecker struct {
+	probes  map[string]*readinessProbe
+	results map[string]*ProbeResult
+	visited map[string]bool
+	res     []*ProbeResult
+}
+
+// check dependencies of probe first, then run probe or skip it if any dependency failed
+func (pc *probeChecker) check(c context.Context, p *readinessProbe, bypassCache bool) *ProbeResult {
+	if res, ok := pc.results[p.name]; ok {
+		return res
+	}
+
+	if pc.visited[p.name] {
+		return pc.finish(&ProbeResult{
+			Name:      p.name,
+			Err:       fmt.Errorf("dependency cycle detected, probe:%s", p.name),
+			CheckedAt: time.Now(),
+		})
+	}
+	pc.visited[p.name] = true
+
+	for _, dep := range p.deps {
+		depProbe, ok := pc.probes[dep]
+		if !ok {
+			return pc.finish(&ProbeResult{
+				Name:      p.name,
+				Err:       fmt.Errorf("dependency of probe is missing, dependency:%s", dep),
+				CheckedAt: time.Now(),
+			})
+		}
+
+		if depRes := pc.check(c, depProbe, bypassCache); depRes.Err != nil {
+			rootCause := depRes.RootCause
+			if len(rootCause) < 1 {
+				rootCause = depRes.Name
+			}
+
+			return pc.finish(&ProbeResult{
+				Name:      p.name,
+				Err:       fmt.Errorf("skipped since prerequisite %s is not ready", rootCause),
+				CheckedAt: time.Now(),
+				Skipped:   true,
+				RootCause: rootCause,
+			})
+		}
+	}
+
+	// result of probe in the middle of a cycle may be recorded already
+	if res, ok := pc.results[p.name]; ok {
+		return res
+	}
+
+	return pc.finish(p.check(c, bypassCache))
+}
+
+// finish records result of probe
+func (pc *probeChecker) finish(res *ProbeResult) *ProbeResult {
+	if prev, ok := pc.results[res.Name]; ok {
+		return prev
+	}
+
+	pc.results[res.Name] = res
+	pc.res = append(pc.res, res)
+	return res
+}
+
+// checkEntryHealth calls Health of running entries implementing HealthReporter, result is named as type/name.
+func (ctx *appContext) checkEntryHealth(c context.Context) []*ProbeResult {
+	reporters := make(map[string]HealthReporter)
+	names := make([]string, 0)
+	for entryType, v := range ctx.ListEntries() {
+		for entryName, entry := range v {
+			if reporter, ok := entry.(HealthReporter); ok {
+				name := entryType + "/" + entryName
+				reporters[name] = reporter
+				names = append(names, name)
+			}
+		}
+	}
+
+	// entries which are not running yet or stopped already are not checked
+	ctx.lifecycle.lock.Lock()
+	running := names[:0]
+	for _, name := range names {
+		if ctx.lifecycle.states[name] == EntryStateRunning {
+			running = append(running, name)
+		}
+	}
+	names = running
+	ctx.lifecycle.lock.Unlock()
+	sort.Strings(names)
+
+	res := make([]*ProbeResult, 0, len(names))
+	for _, name := range names {
+		start := time.Now()
+		err := reporters[name].Health(c)
+		res = append(res, &ProbeResult{
+			Name:      name,
+			Err:       err,
+			Latency:   time.Since(start),
+			CheckedAt: start,
+		})
+	}
+
+	return res
+}
+
+// check run probe or returns cached result.
+func (p *readinessProbe) check(ctx context.Context, bypassCache bool) *ProbeResult {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if !bypassCache && p.result != nil && p.ttl > 0 && time.Since(p.result.CheckedAt) < p.ttl {
+		cached := *p.result
+		cached.Cached = true
+		return &cached
+	}
+
+	start := time.Now()
+	err := p.probe(ctx)
+	p.result = &ProbeResult{
+		Name:      p.name,
+		Err:       err,
+		Latency:   time.Since(start),
+		CheckedAt: start,
+	}
+
+	res := *p.result
+	return &res
+}
+
+// *********************************
+// ****** Event trace related ******
+// *********************************
+
+// SetTraceIDExtractor set extractor used by EventEntry.StartWithContext to fill trace id of event.
+func (ctx *appContext) SetTraceIDExtractor(f TraceIDExtractor) {
+	ctx.traceIDExtract = f
+}
+
+// GetTraceIDExtractor returns TraceIDExtractor, nil if not set.
+func (ctx *appContext) GetTraceIDExtractor() TraceIDExtractor {
+	return ctx.traceIDExtract
+}
+
 // ********************************
 // ****** User value related ******
 // ********************************
@@ -249,28 +682,185 @@ func (ctx *appContext) ClearValues() {
 	}
 }
 
+// SaveValues serialize values to file with path as JSON.
+// Values which can not be serialized to JSON will be skipped with warning.
+func (ctx *appContext) SaveValues(filePath string) error {
+	values := make(map[string]json.RawMessage)
+	for k, v := range ctx.userValues {
+		bytes, err := json.Marshal(v)
+		if err != nil {
+			ctx.GetLoggerEntryDefault().Warn("Skip value which is not JSON serializable",
+				zap.String("key", k), zap.Error(err))
+			continue
+		}
+		values[k] = bytes
+	}
+
+	bytes, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	// write to temp file and rename, in order to prevent broken file
+	tmpPath := filePath + ".tmp"
+	if err := os.WriteFile(tmpPath, bytes, 0644); err != nil {
+		return fmt.Errorf("failed to write values, path:%s, %v", filePath, err)
+	}
+
+	return os.Rename(tmpPath, filePath)
+}
+
+// LoadValues load values from file saved by SaveValues, nothing will be loaded if file is missing.
+// Values are decoded as generic JSON types, like float64 for numbers and map[string]interface{} for objects.
+func (ctx *appContext) LoadValues(filePath string) error {
+	bytes, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read values, path:%s, %v", filePath, err)
+	}
+
+	values := make(map[string]interface{})
+	if err := json.Unmarshal(bytes, &values); err != nil {
+		return fmt.Errorf("failed to unmarshal values, path:%s, %v", filePath, err)
+	}
+
+	for k, v := range values {
+		ctx.AddValue(k, v)
+	}
+
+	return nil
+}
+
 // ************************************
 // ****** App info Entry related ******
 // ************************************
 
+// GetAppInfoEntry returns the singleton appInfoEntry which contains metadata of application.
 func (ctx *appContext) GetAppInfoEntry() *appInfoEntry {
 	return ctx.appInfoEntry
 }
 
 func (ctx *appContext) GetConfigEntry(entryName string) *ConfigEntry {
-	entries := ctx.entries[ConfigEntryType]
-
-	if v, ok := entries[entryName]; ok {
+	if v, ok := ctx.lookupEntry(ConfigEntryType, entryName); ok {
 		return v.(*ConfigEntry)
 	}
 
 	return nil
 }
 
+// GetConfigEntryDefault returns ConfigEntry marked as default.
+// Return the only ConfigEntry if there is exactly one, otherwise nil.
+func (ctx *appContext) GetConfigEntryDefault() *ConfigEntry {
+	entries := ctx.ListEntriesByType(ConfigEntryType)
+
+	for _, v := range entries {
+		if v.(*ConfigEntry).IsDefault {
+			return v.(*ConfigEntry)
+		}
+	}
+
+	if len(entries) == 1 {
+		for _, v := range entries {
+			return v.(*ConfigEntry)
+		}
+	}
+
+	return nil
+}
+
+// IsFeatureEnabled returns value of flags.<name> in default ConfigEntry.
+// Return false if there is no default ConfigEntry or flag is missing.
+func (ctx *appContext) IsFeatureEnabled(name string) bool {
+	return NewFlagSet(ctx.GetConfigEntryDefault()).IsEnabled(name)
+}
+
+// GetLoggerEntry returns LoggerEntry with name.
+//
+// Default LoggerEntry will be returned if it is missing, a warning will be logged once for each
+// missing name. Call SetLoggerEntryStrictMode(true) to return nil instead.
 func (ctx *appContext) GetLoggerEntry(entryName string) *LoggerEntry {
-	entries := ctx.entries[LoggerEntryType]
+	if entry := ctx.getLoggerEntryStrict(entryName); entry != nil {
+		return entry
+	}
+
+	if ctx.loggerStrictMode {
+		return nil
+	}
+
+	res := ctx.GetLoggerEntryDefault()
+	if _, loaded := ctx.missingLoggers.LoadOrStore(entryName, true); !loaded && len(entryName) > 0 {
+		res.Warn("LoggerEntry is missing, fallback to default",
+			zap.String("entryName", entryName),
+			zap.String("fallback", res.GetName()))
+	}
+
+	return res
+}
+
+// SetLoggerEntryStrictMode makes GetLoggerEntry returns nil if LoggerEntry is missing.
+func (ctx *appContext) SetLoggerEntryStrictMode(strict bool) {
+	ctx.loggerStrictMode = strict
+}
+
+// ReopenLoggerEntries reopen files of all LoggerEntry, refer to LoggerEntry.Reopen.
+func (ctx *appContext) ReopenLoggerEntries() error {
+	errs := make([]string, 0)
+	for _, v := range ctx.ListEntriesByType(LoggerEntryType) {
+		if entry, ok := v.(*LoggerEntry); ok {
+			if err := entry.Reopen(); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+	}
 
-	if v, ok := entries[entryName]; ok {
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return fmt.Errorf("[%s]", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// SetLoggerReopenOnSignal reopen files of all LoggerEntry while SIGUSR1 is received if enabled,
+// which is sent by log rotation tools like logrotate. Disabled by default and not supported on windows.
+func (ctx *appContext) SetLoggerReopenOnSignal(enabled bool) {
+	ctx.loggerReopenLock.Lock()
+	defer ctx.loggerReopenLock.Unlock()
+
+	if !enabled {
+		if ctx.loggerReopenSig != nil {
+			signal.Stop(ctx.loggerReopenSig)
+			close(ctx.loggerReopenSig)
+			ctx.loggerReopenSig = nil
+		}
+		return
+	}
+
+	if ctx.loggerReopenSig != nil || len(loggerReopenSignals) < 1 {
+		return
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, loggerReopenSignals...)
+	ctx.loggerReopenSig = sig
+
+	go func() {
+		for recv := range sig {
+			if err := ctx.ReopenLoggerEntries(); err != nil {
+				ctx.GetLoggerEntryDefault().Warn("Failed to reopen files of logger entries",
+					zap.String("signal", recv.String()), zap.Error(err))
+				continue
+			}
+			ctx.GetLoggerEntryDefault().Info("Reopened files of logger entries", zap.String("signal", recv.String()))
+		}
+	}()
+}
+
+// getLoggerEntryStrict returns LoggerEntry with name, nil if missing
+func (ctx *appContext) getLoggerEntryStrict(entryName string) *LoggerEntry {
+	if v, ok := ctx.lookupEntry(LoggerEntryType, entryName); ok {
 		return v.(*LoggerEntry)
 	}
 
@@ -282,7 +872,7 @@ func (ctx *appContext) GetLoggerEntry(entryName string) *LoggerEntry {
 func (ctx *appContext) GetLoggerEntryDefault() *LoggerEntry {
 	res := LoggerEntryStdout
 
-	entries := ctx.entries[LoggerEntryType]
+	entries := ctx.ListEntriesByType(LoggerEntryType)
 
 	for _, v := range entries {
 		if v.(*LoggerEntry).IsDefault {
@@ -295,9 +885,7 @@ func (ctx *appContext) GetLoggerEntryDefault() *LoggerEntry {
 }
 
 func (ctx *appContext) GetEventEntry(entryName string) *EventEntry {
-	entries := ctx.entries[EventEntryType]
-
-	if v, ok := entries[entryName]; ok {
+	if v, ok := ctx.lookupEntry(EventEntryType, entryName); ok {
 		return v.(*EventEntry)
 	}
 
@@ -309,7 +897,7 @@ func (ctx *appContext) GetEventEntry(entryName string) *EventEntry {
 func (ctx *appContext) GetEventEntryDefault() *EventEntry {
 	res := EventEntryStdout
 
-	entries := ctx.entries[EventEntryType]
+	entries := ctx.ListEntriesByType(EventEntryType)
 
 	for _, v := range entries {
 		if v.(*EventEntry).IsDefault {
@@ -321,21 +909,142 @@ func (ctx *appContext) GetEventEntryDefault() *EventEntry {
 	return res
 }
 
-func (ctx *appContext) GetCertEntry(entryName string) *CertEntry {
-	entries := ctx.entries[CertEntryType]
+// GetEventHelperDefault returns rkquery.EventHelper of default EventEntry, refer to GetEventEntryDefault.
+//
+// Events started with helper directly bypass sinks and limits of EventEntry, use StartEvent instead for them.
+func (ctx *appContext) GetEventHelperDefault() *rkquery.EventHelper {
+	return ctx.GetEventEntryDefault().EventHelper
+}
 
-	if v, ok := entries[entryName]; ok {
+func (ctx *appContext) GetCertEntry(entryName string) *CertEntry {
+	if v, ok := ctx.lookupEntry(CertEntryType, entryName); ok {
 		return v.(*CertEntry)
 	}
 
 	return nil
 }
 
+// GetPromEntry returns PromEntry registered with name, refer to RegisterPromEntry.
+func (ctx *appContext) GetPromEntry(entryName string) *PromEntry {
+	if v, ok := ctx.lookupEntry(PromEntryType, entryName); ok {
+		return v.(*PromEntry)
+	}
+
+	return nil
+}
+
+// lookupEntry returns entry with type and name, alias declared by AddAlias is resolved if entry is missing.
+func (ctx *appContext) lookupEntry(entryType, entryName string) (Entry, bool) {
+	if entry, ok := ctx.getEntry(entryType, entryName); ok {
+		return entry, true
+	}
+
+	target, ok := ctx.resolveAlias(entryName)
+	if !ok {
+		return nil, false
+	}
+
+	entry, ok := ctx.getEntry(entryType, target)
+	if ok {
+		if _, loaded := ctx.aliasesInUse.LoadOrStore(entryType+"/"+entryName, true); !loaded {
+			ctx.GetLoggerEntryDefault().Warn("Entry is referenced with deprecated alias",
+				zap.String("entryType", entryType),
+				zap.String("alias", entryName),
+				zap.String("entryName", target))
+		}
+	}
+
+	return entry, ok
+}
+
+// getEntry returns entry with type and name from registered entries
+func (ctx *appContext) getEntry(entryType, entryName string) (Entry, bool) {
+	ctx.entriesLock.RLock()
+	defer ctx.entriesLock.RUnlock()
+
+	entry, ok := ctx.entries[entryType][entryName]
+	return entry, ok
+}
+
+// AddAlias makes oldName resolve to entry with newName in getters of GlobalAppCtx like GetLoggerEntry and GetEntry,
+// a deprecation warning will be logged once for each alias in use. Aliases could be chained, entry with oldName
+// takes precedence over alias. Alias could also be declared in boot config, refer to BootAliases.
+func (ctx *appContext) AddAlias(oldName, newName string) {
+	if len(oldName) < 1 || len(newName) < 1 || oldName == newName {
+		return
+	}
+
+	ctx.aliases.Store(oldName, newName)
+}
+
+// RemoveAlias remove alias with oldName.
+func (ctx *appContext) RemoveAlias(oldName string) bool {
+	_, loaded := ctx.aliases.LoadAndDelete(oldName)
+	return loaded
+}
+
+// ListAliases list aliases with key of old name and value of new name.
+func (ctx *appContext) ListAliases() map[string]string {
+	res := make(map[string]string)
+	ctx.aliases.Range(func(k, v interface{}) bool {
+		res[k.(string)] = v.(string)
+		return true
+	})
+
+	return res
+}
+
+// resolveAlias follows chain of aliases, false will be returned if name is not an alias or chain is a cycle
+func (ctx *appContext) resolveAlias(name string) (string, bool) {
+	visited := map[string]bool{name: true}
+	for {
+		v, ok := ctx.aliases.Load(name)
+		if !ok {
+			break
+		}
+
+		name = v.(string)
+		if visited[name] {
+			return "", false
+		}
+		visited[name] = true
+	}
+
+	return name, len(visited) > 1
+}
+
+// BootAliases is bootstrap config of aliases of entry names, refer to AddAlias.
+//
+//	aliases:
+//	  - name: old-logger
+//	    target: my-logger
+type BootAliases struct {
+	Aliases []struct {
+		Name   string `yaml:"name" json:"name"`
+		Target string `yaml:"target" json:"target"`
+	} `yaml:"aliases" json:"aliases"`
+}
+
+// registerAliasesYAML add aliases declared in boot config, no entry will be returned
+func registerAliasesYAML(raw []byte) map[string]Entry {
+	boot := &BootAliases{}
+	UnmarshalBootYAML(raw, boot)
+
+	for _, alias := range boot.Aliases {
+		GlobalAppCtx.AddAlias(alias.Name, alias.Target)
+	}
+
+	return map[string]Entry{}
+}
+
 func (ctx *appContext) AddEntry(entry Entry) {
 	if entry == nil {
 		return
 	}
 
+	ctx.entriesLock.Lock()
+	defer ctx.entriesLock.Unlock()
+
 	if v, ok := ctx.entries[entry.GetType()]; !ok {
 		ctx.entries[entry.GetType()] = map[string]Entry{
 			entry.GetName(): entry,
@@ -346,41 +1055,200 @@ func (ctx *appContext) AddEntry(entry Entry) {
 }
 
 func (ctx *appContext) clearEntries() {
-	ctx.entries = map[string]map[string]Entry{}
+	for entryType := range ctx.ListEntries() {
+		ctx.RemoveEntryByType(entryType)
+	}
 }
 
+// GetEntry returns entry with type and name.
+//
+// Entry registered with RegisterLazy will be created and bootstrapped at first access.
 func (ctx *appContext) GetEntry(entryType, entryName string) Entry {
-	if v, ok := ctx.entries[entryType]; ok {
-		return v[entryName]
+	if entry, ok := ctx.lookupEntry(entryType, entryName); ok {
+		return entry
+	}
+
+	if entry := ctx.getLazyEntry(entryType, entryName); entry != nil {
+		return entry
 	}
 
 	return nil
 }
 
+// GetEntryAs returns entry with name as type of T.
+//
+// Name could be an alias declared by AddAlias. Entry types are looked up in alphabetical order,
+// the first entry which is type of T is returned. An error will be returned if entry is missing
+// or none of entries with name is type of T.
+//
+//	entry, err := rkentry.GetEntryAs[*MyEntry]("my-entry")
+func GetEntryAs[T Entry](name string) (T, error) {
+	var res T
+
+	entryTypes := make([]string, 0)
+	for entryType := range GlobalAppCtx.ListEntries() {
+		entryTypes = append(entryTypes, entryType)
+	}
+	sort.Strings(entryTypes)
+
+	types := make([]string, 0)
+	for _, entryType := range entryTypes {
+		entry, ok := GlobalAppCtx.lookupEntry(entryType, name)
+		if !ok {
+			continue
+		}
+
+		if v, ok := entry.(T); ok {
+			return v, nil
+		}
+		types = append(types, fmt.Sprintf("%s(%T)", entryType, entry))
+	}
+
+	if len(types) < 1 {
+		return res, fmt.Errorf("entry is missing, name:%s", name)
+	}
+
+	expected := reflect.TypeOf((*T)(nil)).Elem().String()
+	return res, fmt.Errorf("entry type mismatch, name:%s, expected:%s, actual:[%s]", name, expected, strings.Join(types, ","))
+}
+
+// RegisterLazy register factory of entry with type and name, entry will be created, added and bootstrapped
+// at first access of GetEntry with the same type and name.
+//
+// Factory is called once at a time, it will be called again at next access if it returned nil,
+// returned entry with different type or entry failed to bootstrap.
+func (ctx *appContext) RegisterLazy(entryType, name string, factory func() Entry) {
+	if len(entryType) < 1 || len(name) < 1 || factory == nil {
+		return
+	}
+
+	ctx.lazyLock.Lock()
+	defer ctx.lazyLock.Unlock()
+
+	if ctx.lazyEntries == nil {
+		ctx.lazyEntries = make(map[string]*lazyEntry)
+	}
+	ctx.lazyEntries[name] = &lazyEntry{entryType: entryType, factory: factory}
+}
+
+// getLazyEntry creates and bootstraps lazy entry with type and name if needed
+func (ctx *appContext) getLazyEntry(entryType, name string) Entry {
+	ctx.lazyLock.Lock()
+	lazy, ok := ctx.lazyEntries[name]
+	ctx.lazyLock.Unlock()
+
+	if !ok || lazy.entryType != entryType {
+		return nil
+	}
+
+	lazy.once.Do(func() {
+		lazy.entry = ctx.newLazyEntry(lazy, name)
+	})
+
+	// replace failed one so that factory is called again at next access
+	if lazy.entry == nil {
+		ctx.lazyLock.Lock()
+		if ctx.lazyEntries[name] == lazy {
+			ctx.lazyEntries[name] = &lazyEntry{entryType: lazy.entryType, factory: lazy.factory}
+		}
+		ctx.lazyLock.Unlock()
+	}
+
+	return lazy.entry
+}
+
+// newLazyEntry calls factory of lazy entry, then adds and bootstraps entry, nil will be returned if failed
+func (ctx *appContext) newLazyEntry(lazy *lazyEntry, name string) Entry {
+	entry := lazy.factory()
+	if entry == nil {
+		return nil
+	}
+
+	if entry.GetType() != lazy.entryType {
+		ctx.GetLoggerEntryDefault().Error("Lazy entry type mismatch",
+			zap.String("entryName", name),
+			zap.String("expected", lazy.entryType),
+			zap.String("actual", entry.GetType()))
+		return nil
+	}
+
+	ctx.AddEntry(entry)
+	if err := ctx.transitEntry(entry, context.Background(), true); err != nil {
+		ctx.RemoveEntry(entry)
+		ctx.GetLoggerEntryDefault().Error("Failed to bootstrap lazy entry",
+			zap.String("entryName", name),
+			zap.Error(err))
+		return nil
+	}
+
+	return entry
+}
+
+// RemoveEntry removes entry, lifecycle states and settings keyed by its type and name are cleared as well.
 func (ctx *appContext) RemoveEntry(entry Entry) {
 	if entry == nil {
 		return
 	}
 
+	ctx.entriesLock.Lock()
 	if v, ok := ctx.entries[entry.GetType()]; ok {
 		delete(v, entry.GetName())
 	}
+	ctx.entriesLock.Unlock()
+
+	ctx.removeEntryLifecycle(entry.GetType(), entry.GetName())
 }
 
 func (ctx *appContext) RemoveEntryByType(entryType string) {
+	ctx.entriesLock.Lock()
+	names := make([]string, 0, len(ctx.entries[entryType]))
+	for name := range ctx.entries[entryType] {
+		names = append(names, name)
+	}
 	delete(ctx.entries, entryType)
+	ctx.entriesLock.Unlock()
+
+	ctx.removeEntryLifecycle(entryType, names...)
+}
+
+// removeEntryLifecycle clears lifecycle states and settings of removed entries with type and names
+func (ctx *appContext) removeEntryLifecycle(entryType string, names ...string) {
+	ctx.lifecycle.lock.Lock()
+	defer ctx.lifecycle.lock.Unlock()
+
+	for _, name := range names {
+		ctx.lifecycle.removeEntry(entryType, name)
+	}
 }
 
+// ListEntriesByType returns copy of entries with type, keyed by name.
 func (ctx *appContext) ListEntriesByType(entryType string) map[string]Entry {
-	if v, ok := ctx.entries[entryType]; ok {
-		return v
+	ctx.entriesLock.RLock()
+	defer ctx.entriesLock.RUnlock()
+
+	res := make(map[string]Entry, len(ctx.entries[entryType]))
+	for k, v := range ctx.entries[entryType] {
+		res[k] = v
 	}
 
-	return map[string]Entry{}
+	return res
 }
 
+// ListEntries returns copy of all entries, keyed by type and name.
 func (ctx *appContext) ListEntries() map[string]map[string]Entry {
-	return ctx.entries
+	ctx.entriesLock.RLock()
+	defer ctx.entriesLock.RUnlock()
+
+	res := make(map[string]map[string]Entry, len(ctx.entries))
+	for entryType, entries := range ctx.entries {
+		m := make(map[string]Entry, len(entries))
+		for k, v := range entries {
+			m[k] = v
+		}
+		res[entryType] = m
+	}
+
+	return res
 }
 
 func (ctx *appContext) GetSignerJwtEntry(entryName string) SignerJwt {
@@ -403,15 +1271,92 @@ func (ctx *appContext) GetCryptoEntry(entryName string) Crypto {
 	return nil
 }
 
+// LogEffectiveConfig logs redacted config of all entries as a single event with default EventEntry,
+// checksum of redacted config is added as pair, so that it can be compared between deployments.
+// Call it after bootstrap, refer to WithEffectiveConfigLog.
+func (ctx *appContext) LogEffectiveConfig() error {
+	raw, err := json.Marshal(ctx)
+	if err != nil {
+		return err
+	}
+
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return err
+	}
+
+	redacted, err := json.Marshal(redactSecrets(m))
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(redacted)
+
+	eventEntry := ctx.GetEventEntryDefault()
+	event := eventEntry.Start("effectiveConfig")
+	event.AddPair("checksum", hex.EncodeToString(sum[:]))
+	event.AddPayloads(zap.Any("config", json.RawMessage(redacted)))
+	eventEntry.Finish(event)
+
+	return nil
+}
+
+// MarshalJSON marshal entries grouped by type and name.
+// Use redactSecrets before exposing it, since entries may contain credentials.
+// Entry which failed to marshal will be replaced with its name, type and error.
+func (ctx *appContext) MarshalJSON() ([]byte, error) {
+	entries := make(map[string]map[string]interface{})
+	for entryType, m := range ctx.ListEntries() {
+		entries[entryType] = make(map[string]interface{})
+		for entryName, entry := range m {
+			if raw, err := json.Marshal(entry); err != nil {
+				entries[entryType][entryName] = map[string]interface{}{
+					"name":  entryName,
+					"type":  entryType,
+					"error": err.Error(),
+				}
+			} else {
+				entries[entryType][entryName] = json.RawMessage(raw)
+			}
+		}
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"entries": entries,
+	})
+}
+
 // ***********************************
 // ****** Shutdown hook related ******
 // ***********************************
 
 // GetUpTime returns uptime of application from StartTime.
+// Uptime stops growing once shutdown began, refer to GetShutdownTime.
 func (ctx *appContext) GetUpTime() time.Duration {
+	if shutdownTime := ctx.GetShutdownTime(); !shutdownTime.IsZero() {
+		return shutdownTime.Sub(ctx.startTime)
+	}
+
 	return time.Since(ctx.startTime)
 }
 
+// GetShutdownTime returns time when InterruptAll began, zero if application is not shutting down.
+func (ctx *appContext) GetShutdownTime() time.Time {
+	ctx.shutdownLock.RLock()
+	defer ctx.shutdownLock.RUnlock()
+
+	return ctx.shutdownTime
+}
+
+// markShutdownTime records shutdown time if it is not recorded yet
+func (ctx *appContext) markShutdownTime() {
+	ctx.shutdownLock.Lock()
+	defer ctx.shutdownLock.Unlock()
+
+	if ctx.shutdownTime.IsZero() {
+		ctx.shutdownTime = time.Now()
+	}
+}
+
 // GetStartTime returns start time of application.
 func (ctx *appContext) GetStartTime() time.Time {
 	return ctx.startTime
@@ -465,3 +1410,65 @@ func (ctx *appContext) WaitForShutdownSig() {
 func (ctx *appContext) GetShutdownSig() chan os.Signal {
 	return ctx.shutdownSig
 }
+
+// TriggerShutdown simulates shutdown signal without sending OS signal.
+// Waiters of shutdown signal like Run will proceed with the same path of OS signal.
+func (ctx *appContext) TriggerShutdown() {
+	ctx.triggerShutdown(manualShutdownSig{})
+}
+
+// GetShutdownSigReceived returns signal which triggered shutdown, nil if shutdown is not triggered.
+func (ctx *appContext) GetShutdownSigReceived() os.Signal {
+	ctx.shutdownLock.RLock()
+	defer ctx.shutdownLock.RUnlock()
+
+	return ctx.shutdownSigRecv
+}
+
+// SetShutdownContext provide root context used to interrupt entries by Run and BeginDrain,
+// so that Interrupt of entries carries its values like trace id. Grace period of Run is applied on top of it,
+// deadline of ctx is kept if it is earlier. Context should not be cancelled before shutdown, nil means context.Background.
+func (ctx *appContext) SetShutdownContext(c context.Context) {
+	ctx.shutdownLock.Lock()
+	defer ctx.shutdownLock.Unlock()
+
+	ctx.shutdownCtx = c
+}
+
+// GetShutdownContext returns context provided by SetShutdownContext, context.Background if missing.
+func (ctx *appContext) GetShutdownContext() context.Context {
+	ctx.shutdownLock.RLock()
+	defer ctx.shutdownLock.RUnlock()
+
+	if ctx.shutdownCtx == nil {
+		return context.Background()
+	}
+
+	return ctx.shutdownCtx
+}
+
+// triggerShutdown records the first signal and notify waiters of shutdown signal,
+// signal will be dropped if there is already a pending one.
+func (ctx *appContext) triggerShutdown(sig os.Signal) {
+	ctx.shutdownLock.Lock()
+	if ctx.shutdownSigRecv == nil {
+		ctx.shutdownSigRecv = sig
+	}
+	ctx.shutdownLock.Unlock()
+
+	select {
+	case ctx.shutdownSig <- sig:
+	default:
+	}
+}
+
+// manualShutdownSig is signal used by TriggerShutdown
+type manualShutdownSig struct{}
+
+// String returns name of signal
+func (manualShutdownSig) String() string {
+	return "manual"
+}
+
+// Signal implements os.Signal
+func (manualShutdownSig) Signal() {}
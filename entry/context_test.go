@@ -6,11 +6,23 @@
 package rkentry
 
 import (
+	"bytes"
 	"context"
 	"embed"
+	"encoding/json"
+	"errors"
+	"github.com/rookie-ninja/rk-logger"
+	"github.com/rookie-ninja/rk-query"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	"net/http"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
 	"syscall"
 	"testing"
 	"time"
@@ -39,8 +51,14 @@ cert:
 	assert.NotNil(t, GlobalAppCtx.GetConfigEntry("ut-config"))
 	assert.Nil(t, GlobalAppCtx.GetConfigEntry("ut-config-1"))
 
+	assert.NotNil(t, GlobalAppCtx.GetLoggerEntry("ut-logger"))
+	assert.Equal(t, GlobalAppCtx.GetLoggerEntryDefault(), GlobalAppCtx.GetLoggerEntry("ut-logger-1"))
+
+	// with strict mode
+	GlobalAppCtx.SetLoggerEntryStrictMode(true)
 	assert.NotNil(t, GlobalAppCtx.GetLoggerEntry("ut-logger"))
 	assert.Nil(t, GlobalAppCtx.GetLoggerEntry("ut-logger-1"))
+	GlobalAppCtx.SetLoggerEntryStrictMode(false)
 
 	assert.NotNil(t, GlobalAppCtx.GetEventEntry("ut-event"))
 	assert.Nil(t, GlobalAppCtx.GetEventEntry("ut-event-1"))
@@ -65,6 +83,62 @@ config:
 	assert.Empty(t, GlobalAppCtx.ListEntriesByType(ConfigEntryType))
 }
 
+func TestRegisterInternalEntriesFromDir(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "01-app.yaml"), []byte(`
+---
+app:
+  name: ut-app
+logger:
+  - name: ut-logger
+`), os.ModePerm))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "02-config.json"), []byte(`{
+  "config": [{"name": "ut-config", "content": {"key": "value"}}]
+}`), os.ModePerm))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "03-ignored.txt"), []byte("ignored"), os.ModePerm))
+
+	entries, err := RegisterInternalEntriesFromDir(dir)
+	assert.Nil(t, err)
+	assert.Contains(t, entries, "ut-logger")
+	assert.Contains(t, entries, "ut-config")
+	assert.Equal(t, "ut-app", GlobalAppCtx.GetAppInfoEntry().AppName)
+	assert.Equal(t, "value", GlobalAppCtx.GetConfigEntry("ut-config").GetString("key"))
+
+	// duplicate entry name across files
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "04-logger.yaml"), []byte(`
+---
+logger:
+  - name: ut-logger
+`), os.ModePerm))
+	entries, err = RegisterInternalEntriesFromDir(dir)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "01-app.yaml")
+	assert.Contains(t, err.Error(), "04-logger.yaml")
+	assert.Nil(t, entries)
+
+	// non exist dir
+	_, err = RegisterInternalEntriesFromDir(filepath.Join(dir, "non-exist"))
+	assert.NotNil(t, err)
+
+	registerAppInfoEntryYAML([]byte{})
+}
+
+func TestRequireEntries(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+
+	GlobalAppCtx.AddEntry(&EntryMock{Name: "ut-entry"})
+
+	assert.Nil(t, RequireEntries())
+	assert.Nil(t, RequireEntries("ut-entry"))
+
+	err := RequireEntries("ut-entry", "ut-missing-1", "ut-missing-2")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "ut-missing-1,ut-missing-2")
+	assert.NotContains(t, err.Error(), "ut-entry,")
+}
+
 func TestGlobalAppCtx_init(t *testing.T) {
 	assert.NotNil(t, GlobalAppCtx)
 
@@ -126,6 +200,37 @@ func TestListEntryRegFunc_HappyCase(t *testing.T) {
 	pluginRegFuncList = pluginRegFuncList[:0]
 }
 
+func TestChainRegFuncs(t *testing.T) {
+	trace := make([]string, 0)
+	newRegFunc := func(names ...string) RegFunc {
+		return func(raw []byte) map[string]Entry {
+			trace = append(trace, string(raw)+":"+strings.Join(names, ","))
+			res := make(map[string]Entry)
+			for _, name := range names {
+				res[name] = &EntryMock{Name: name}
+			}
+			return res
+		}
+	}
+
+	// merged in order, nil is ignored
+	res := ChainRegFuncs(newRegFunc("a"), nil, newRegFunc("b", "c"))([]byte("ut"))
+	assert.Len(t, res, 3)
+	assert.Equal(t, []string{"ut:a", "ut:b,c"}, trace)
+	assert.Empty(t, ChainRegFuncs()(nil))
+
+	// same entry returned twice is not a collision
+	entry := &EntryMock{Name: "same"}
+	same := func([]byte) map[string]Entry {
+		return map[string]Entry{"same": entry}
+	}
+	assert.Len(t, ChainRegFuncs(same, same)(nil), 1)
+
+	// collision
+	defer assertPanic(t)
+	ChainRegFuncs(newRegFunc("a"), newRegFunc("a"))(nil)
+}
+
 // value related
 func TestAppContext_AddValue_WithEmptyKey(t *testing.T) {
 	key := ""
@@ -231,6 +336,33 @@ func TestAppContext_ClearValues_HappyCase(t *testing.T) {
 	assert.Empty(t, GlobalAppCtx.ListValues())
 }
 
+func TestAppContext_SaveValues(t *testing.T) {
+	defer GlobalAppCtx.ClearValues()
+	GlobalAppCtx.ClearValues()
+
+	filePath := filepath.Join(t.TempDir(), "values.json")
+
+	// missing file
+	assert.Nil(t, GlobalAppCtx.LoadValues(filePath))
+	assert.Empty(t, GlobalAppCtx.ListValues())
+
+	GlobalAppCtx.AddValue("ut-string", "value")
+	GlobalAppCtx.AddValue("ut-int", 1)
+	GlobalAppCtx.AddValue("ut-func", func() {})
+	assert.Nil(t, GlobalAppCtx.SaveValues(filePath))
+
+	GlobalAppCtx.ClearValues()
+	assert.Nil(t, GlobalAppCtx.LoadValues(filePath))
+	assert.Equal(t, "value", GlobalAppCtx.GetValue("ut-string"))
+	assert.Equal(t, float64(1), GlobalAppCtx.GetValue("ut-int"))
+	// non serializable value should be skipped
+	assert.Nil(t, GlobalAppCtx.GetValue("ut-func"))
+
+	// invalid file
+	assert.Nil(t, os.WriteFile(filePath, []byte("invalid"), 0644))
+	assert.NotNil(t, GlobalAppCtx.LoadValues(filePath))
+}
+
 // shutdown signal related
 func TestAppContext_GetShutdownSig_HappyCase(t *testing.T) {
 	assert.NotNil(t, GlobalAppCtx.GetShutdownSig())
@@ -404,6 +536,155 @@ func TestAppContext_SetReadinessCheck(t *testing.T) {
 	assert.NotNil(t, GlobalAppCtx.livenessCheck)
 }
 
+func TestAppContext_ReadinessProbe(t *testing.T) {
+	defer func() {
+		for _, name := range GlobalAppCtx.ListReadinessProbes() {
+			GlobalAppCtx.RemoveReadinessProbe(name)
+		}
+	}()
+
+	// invalid case
+	GlobalAppCtx.AddReadinessProbe("", func(context.Context) error { return nil }, 0)
+	GlobalAppCtx.AddReadinessProbe("ut-probe", nil, 0)
+	assert.Empty(t, GlobalAppCtx.ListReadinessProbes())
+
+	// happy case
+	calls := 0
+	GlobalAppCtx.AddReadinessProbe("ut-probe", func(context.Context) error {
+		calls++
+		return errors.New("ut-error")
+	}, time.Minute)
+	assert.Equal(t, []string{"ut-probe"}, GlobalAppCtx.ListReadinessProbes())
+
+	res := GlobalAppCtx.CheckReadiness(context.Background(), false)
+	assert.Len(t, res, 1)
+	assert.Equal(t, "ut-probe", res[0].Name)
+	assert.NotNil(t, res[0].Err)
+	assert.False(t, res[0].Cached)
+
+	// cached within ttl
+	res = GlobalAppCtx.CheckReadiness(context.Background(), false)
+	assert.True(t, res[0].Cached)
+	assert.Equal(t, 1, calls)
+
+	// bypass cache
+	res = GlobalAppCtx.CheckReadiness(context.Background(), true)
+	assert.False(t, res[0].Cached)
+	assert.Equal(t, 2, calls)
+
+	assert.True(t, GlobalAppCtx.RemoveReadinessProbe("ut-probe"))
+	assert.False(t, GlobalAppCtx.RemoveReadinessProbe("ut-probe"))
+}
+
+func TestAppContext_ReadinessProbe_WithDependencies(t *testing.T) {
+	defer func() {
+		for _, name := range GlobalAppCtx.ListReadinessProbes() {
+			GlobalAppCtx.RemoveReadinessProbe(name)
+		}
+	}()
+
+	trace := make([]string, 0)
+	newProbe := func(name string, err error) ReadinessProbe {
+		return func(context.Context) error {
+			trace = append(trace, name)
+			return err
+		}
+	}
+
+	// dependent probe registered before its prerequisite
+	GlobalAppCtx.AddReadinessProbeWithDependencies("ut-query", newProbe("ut-query", nil), 0, "ut-schema")
+	GlobalAppCtx.AddReadinessProbeWithDependencies("ut-cache", newProbe("ut-cache", nil), 0, "ut-query")
+	GlobalAppCtx.AddReadinessProbe("ut-schema", newProbe("ut-schema", errors.New("ut-error")), 0)
+
+	res := GlobalAppCtx.CheckReadiness(context.Background(), false)
+	assert.Len(t, res, 3)
+	assert.Equal(t, []string{"ut-schema"}, trace)
+
+	assert.Equal(t, "ut-schema", res[0].Name)
+	assert.False(t, res[0].Skipped)
+	assert.Empty(t, res[0].RootCause)
+
+	assert.Equal(t, "ut-query", res[1].Name)
+	assert.True(t, res[1].Skipped)
+	assert.Equal(t, "ut-schema", res[1].RootCause)
+
+	assert.Equal(t, "ut-cache", res[2].Name)
+	assert.True(t, res[2].Skipped)
+	assert.Equal(t, "ut-schema", res[2].RootCause)
+
+	// prerequisite passed
+	trace = trace[:0]
+	GlobalAppCtx.AddReadinessProbe("ut-schema", newProbe("ut-schema", nil), 0)
+	res = GlobalAppCtx.CheckReadiness(context.Background(), false)
+	assert.Equal(t, []string{"ut-schema", "ut-query", "ut-cache"}, trace)
+	for i := range res {
+		assert.Nil(t, res[i].Err)
+	}
+
+	// missing dependency and cycle
+	GlobalAppCtx.AddReadinessProbeWithDependencies("ut-missing", newProbe("ut-missing", nil), 0, "ut-unknown")
+	GlobalAppCtx.AddReadinessProbeWithDependencies("ut-cycle-a", newProbe("ut-cycle-a", nil), 0, "ut-cycle-b")
+	GlobalAppCtx.AddReadinessProbeWithDependencies("ut-cycle-b", newProbe("ut-cycle-b", nil), 0, "ut-cycle-a")
+	res = GlobalAppCtx.CheckReadiness(context.Background(), false)
+	assert.Len(t, res, 6)
+	for i := range res {
+		switch res[i].Name {
+		case "ut-missing":
+			assert.Contains(t, res[i].Err.Error(), "dependency of probe is missing")
+		case "ut-cycle-a":
+			assert.Contains(t, res[i].Err.Error(), "dependency cycle detected")
+		case "ut-cycle-b":
+			assert.True(t, res[i].Skipped)
+			assert.Equal(t, "ut-cycle-a", res[i].RootCause)
+		}
+	}
+}
+
+func TestAppContext_AddAlias(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(LoggerEntryType)
+	defer func() {
+		for k := range GlobalAppCtx.ListAliases() {
+			GlobalAppCtx.RemoveAlias(k)
+		}
+	}()
+
+	// invalid case
+	GlobalAppCtx.AddAlias("", "ut-logger")
+	GlobalAppCtx.AddAlias("ut-logger", "ut-logger")
+	assert.Empty(t, GlobalAppCtx.ListAliases())
+
+	RegisterLoggerEntry(&BootLogger{
+		Logger: []*BootLoggerE{
+			{Name: "ut-logger"},
+		},
+	})
+
+	// chained alias
+	GlobalAppCtx.AddAlias("ut-logger-v1", "ut-logger")
+	GlobalAppCtx.AddAlias("ut-logger-v0", "ut-logger-v1")
+	assert.Equal(t, map[string]string{"ut-logger-v1": "ut-logger", "ut-logger-v0": "ut-logger-v1"}, GlobalAppCtx.ListAliases())
+	assert.Equal(t, "ut-logger", GlobalAppCtx.GetLoggerEntry("ut-logger-v0").GetName())
+	assert.Equal(t, "ut-logger", GlobalAppCtx.GetEntry(LoggerEntryType, "ut-logger-v1").GetName())
+	assert.Nil(t, GlobalAppCtx.GetEventEntry("ut-logger-v1"))
+
+	// cycle
+	GlobalAppCtx.AddAlias("ut-cycle-a", "ut-cycle-b")
+	GlobalAppCtx.AddAlias("ut-cycle-b", "ut-cycle-a")
+	assert.Nil(t, GlobalAppCtx.GetEntry(LoggerEntryType, "ut-cycle-a"))
+
+	assert.True(t, GlobalAppCtx.RemoveAlias("ut-logger-v1"))
+	assert.False(t, GlobalAppCtx.RemoveAlias("ut-logger-v1"))
+	assert.Nil(t, GlobalAppCtx.GetEntry(LoggerEntryType, "ut-logger-v0"))
+
+	// declared in boot config
+	registerAliasesYAML([]byte(`
+aliases:
+  - name: ut-logger-yaml
+    target: ut-logger
+`))
+	assert.Equal(t, "ut-logger", GlobalAppCtx.GetEntry(LoggerEntryType, "ut-logger-yaml").GetName())
+}
+
 type EntryMock struct {
 	Name string
 }
@@ -428,7 +709,207 @@ func (entry *EntryMock) GetDescription() string {
 	return ""
 }
 
+func TestAppContext_RegisterLazy(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType("mock")
+	defer func() { GlobalAppCtx.lazyEntries = nil }()
+
+	created := 0
+	trace := make([]string, 0)
+	GlobalAppCtx.RegisterLazy("mock", "ut-lazy", func() Entry {
+		created++
+		return &lifecycleEntryMock{EntryMock: EntryMock{Name: "ut-lazy"}, trace: &trace}
+	})
+
+	// not created before access
+	assert.Equal(t, 0, created)
+	assert.Empty(t, GlobalAppCtx.ListEntriesByType("mock"))
+
+	// type mismatch, not created
+	assert.Nil(t, GlobalAppCtx.GetEntry(LoggerEntryType, "ut-lazy"))
+	assert.Equal(t, 0, created)
+	assert.Empty(t, trace)
+
+	// created and bootstrapped once
+	entry := GlobalAppCtx.GetEntry("mock", "ut-lazy")
+	assert.NotNil(t, entry)
+	assert.Equal(t, entry, GlobalAppCtx.GetEntry("mock", "ut-lazy"))
+	assert.Equal(t, 1, created)
+	assert.Equal(t, []string{"bootstrap:ut-lazy"}, trace)
+	assert.Equal(t, EntryStateRunning, GlobalAppCtx.GetEntryState("mock", "ut-lazy"))
+
+	// failed to bootstrap, retry at next access
+	GlobalAppCtx.RegisterLazy("mock", "ut-lazy-panic", func() Entry {
+		created++
+		return &lifecycleEntryMock{EntryMock: EntryMock{Name: "ut-lazy-panic"}, panicBoot: true, trace: &trace}
+	})
+	assert.Nil(t, GlobalAppCtx.GetEntry("mock", "ut-lazy-panic"))
+	assert.Nil(t, GlobalAppCtx.GetEntry("mock", "ut-lazy-panic"))
+	assert.Equal(t, 3, created)
+
+	// factory returns entry with different type
+	GlobalAppCtx.RegisterLazy(LoggerEntryType, "ut-lazy-type", func() Entry {
+		created++
+		return &EntryMock{Name: "ut-lazy-type"}
+	})
+	assert.Nil(t, GlobalAppCtx.GetEntry(LoggerEntryType, "ut-lazy-type"))
+	assert.Equal(t, 4, created)
+	assert.Nil(t, GlobalAppCtx.GetEntry("mock", "ut-lazy-type"))
+}
+
+func TestAppContext_RegisterLazy_WithConcurrentAccess(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType("mock")
+	defer func() { GlobalAppCtx.lazyEntries = nil }()
+
+	created := atomic.NewInt32(0)
+	GlobalAppCtx.RegisterLazy("mock", "ut-lazy-concurrent", func() Entry {
+		created.Inc()
+		return &EntryMock{Name: "ut-lazy-concurrent"}
+	})
+
+	wg := sync.WaitGroup{}
+	entries := make([]Entry, 16)
+	for i := range entries {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			entries[i] = GlobalAppCtx.GetEntry("mock", "ut-lazy-concurrent")
+			for range GlobalAppCtx.ListEntries() {
+			}
+			GlobalAppCtx.checkEntryHealth(context.TODO())
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), created.Load())
+	for i := range entries {
+		assert.NotNil(t, entries[i])
+		assert.Equal(t, entries[0], entries[i])
+	}
+}
+
+func TestAppContext_GetShutdownTime(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	defer func() { GlobalAppCtx.shutdownTime = time.Time{} }()
+	GlobalAppCtx.clearEntries()
+	GlobalAppCtx.shutdownTime = time.Time{}
+
+	assert.True(t, GlobalAppCtx.GetShutdownTime().IsZero())
+	assert.Empty(t, NewProcessInfo().ShutdownTime)
+
+	assert.Nil(t, GlobalAppCtx.InterruptAll(context.TODO()))
+	shutdownTime := GlobalAppCtx.GetShutdownTime()
+	assert.False(t, shutdownTime.IsZero())
+	assert.NotEmpty(t, NewProcessInfo().ShutdownTime)
+
+	// uptime stops growing after shutdown
+	upTime := GlobalAppCtx.GetUpTime()
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, upTime, GlobalAppCtx.GetUpTime())
+	assert.Equal(t, shutdownTime.Sub(GlobalAppCtx.GetStartTime()), upTime)
+
+	// recorded only once
+	assert.Nil(t, GlobalAppCtx.InterruptAll(context.TODO()))
+	assert.Equal(t, shutdownTime, GlobalAppCtx.GetShutdownTime())
+}
+
+func TestGetEntryAs(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType("mock")
+	GlobalAppCtx.AddEntry(&EntryMock{Name: "ut-entry"})
+
+	// happy case
+	entry, err := GetEntryAs[*EntryMock]("ut-entry")
+	assert.Nil(t, err)
+	assert.Equal(t, "ut-entry", entry.GetName())
+
+	// with interface
+	_, err = GetEntryAs[Entry]("ut-entry")
+	assert.Nil(t, err)
+
+	// missing
+	_, err = GetEntryAs[*EntryMock]("ut-missing")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "missing")
+
+	// type mismatch
+	logger, err := GetEntryAs[*LoggerEntry]("ut-entry")
+	assert.Nil(t, logger)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "expected:*rkentry.LoggerEntry")
+	assert.Contains(t, err.Error(), "mock(*rkentry.EntryMock)")
+
+	_, err = GetEntryAs[SignerJwt]("ut-entry")
+	assert.Contains(t, err.Error(), "expected:rkentry.SignerJwt")
+
+	// with alias
+	GlobalAppCtx.AddAlias("ut-entry-old", "ut-entry")
+	defer GlobalAppCtx.RemoveAlias("ut-entry-old")
+	entry, err = GetEntryAs[*EntryMock]("ut-entry-old")
+	assert.Nil(t, err)
+	assert.Equal(t, "ut-entry", entry.GetName())
+
+	// with same name in multiple types
+	defer GlobalAppCtx.RemoveEntryByType("mock-b")
+	GlobalAppCtx.AddEntry(&typedEntryMock{EntryMock: EntryMock{Name: "ut-entry"}, entryType: "mock-b"})
+	for i := 0; i < 10; i++ {
+		res, err := GetEntryAs[Entry]("ut-entry")
+		assert.Nil(t, err)
+		assert.Equal(t, "mock", res.GetType())
+	}
+}
+
+type typedEntryMock struct {
+	EntryMock
+	entryType string
+}
+
+func (entry *typedEntryMock) GetType() string {
+	return entry.entryType
+}
+
 func TestMain(m *testing.M) {
 	code := m.Run()
 	os.Exit(code)
 }
+
+type configEntryMock struct {
+	EntryMock
+}
+
+func (entry *configEntryMock) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"addr":     "ut-addr",
+		"password": "ut-password",
+	})
+}
+
+func TestAppContext_LogEffectiveConfig(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	GlobalAppCtx.clearEntries()
+
+	buf := &bytes.Buffer{}
+	GlobalAppCtx.AddEntry(&EventEntry{
+		entryName:    "ut-event-effective-config",
+		entryType:    EventEntryType,
+		IsDefault:    true,
+		LoggerConfig: rklogger.NewZapEventConfig(),
+		EventHelper: rkquery.NewEventHelper(rkquery.NewEventFactory(
+			rkquery.WithZapLogger(zap.New(zapcore.NewCore(
+				zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(buf), zap.DebugLevel))),
+			rkquery.WithEncoding(rkquery.JSON))),
+	})
+	GlobalAppCtx.AddEntry(&configEntryMock{EntryMock: EntryMock{Name: "ut-effective-config"}})
+
+	assert.Nil(t, GlobalAppCtx.LogEffectiveConfig())
+	first := buf.String()
+	assert.Contains(t, first, "effectiveConfig")
+	assert.Contains(t, first, "ut-addr")
+	assert.Contains(t, first, "checksum")
+	assert.NotContains(t, first, "ut-password")
+
+	// checksum is stable for the same config
+	buf.Reset()
+	assert.Nil(t, GlobalAppCtx.LogEffectiveConfig())
+	checksum := regexp.MustCompile(`"checksum":"[0-9a-f]{64}"`).FindString(first)
+	assert.NotEmpty(t, checksum)
+	assert.Contains(t, buf.String(), checksum)
+}
@@ -8,6 +8,7 @@ package rkentry
 import (
 	"context"
 	"github.com/golang-jwt/jwt/v4"
+	"net/http"
 )
 
 const (
@@ -35,6 +36,8 @@ const (
 	SignerJwtEntryType = "SignerJwtEntry"
 	CryptoEntryType    = "CryptoEntry"
 	PProfEntryType     = "PProfEntry"
+	// GrpcHealthEntryType public access
+	GrpcHealthEntryType = "GrpcHealthEntry"
 )
 
 // RegFunc can be used to create an entry could be any kinds of services or pieces of codes which
@@ -90,6 +93,31 @@ type Entry interface {
 	String() string
 }
 
+// HealthReporter is an optional interface of Entry which reports health of entry itself.
+//
+// Health of entries implementing it will be checked together with readiness probes,
+// entries not implementing it are assumed healthy.
+type HealthReporter interface {
+	// Health returns error if entry is not healthy
+	Health(ctx context.Context) error
+}
+
+// Route is an HTTP route exposed by entry, Path is relative to admin prefix of CommonServiceEntry.
+// Requests with any method are accepted if Method is empty. Summary describes route in OpenAPI spec.
+type Route struct {
+	Method  string
+	Path    string
+	Handler http.HandlerFunc
+	Summary string
+}
+
+// RouteProvider is an optional interface of Entry which exposes HTTP routes,
+// routes are collected and mounted by CommonServiceEntry.
+type RouteProvider interface {
+	// Routes returns routes of entry
+	Routes() []Route
+}
+
 // SignerJwt interface which must be implemented for JWT signer
 type SignerJwt interface {
 	Entry
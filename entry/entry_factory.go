@@ -0,0 +1,265 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"fmt"
+	"github.com/mitchellh/mapstructure"
+	"gopkg.in/yaml.v2"
+	"strings"
+	"sync"
+)
+
+var (
+	entryFactories    = make(map[string]EntryFactory)
+	entryFactoriesMut sync.RWMutex
+)
+
+// EntryFactory creates Entry from raw config block.
+type EntryFactory func(raw map[string]interface{}) (Entry, error)
+
+// BootEntryFactory is bootstrap config of entries created by EntryFactory.
+//
+// Example:
+//
+//	---
+//	entries:
+//	  - type: my-entry
+//	    domain: "*"
+//	    bootstrapTimeout: 10s
+//	    failurePolicy: warn
+//	    config:
+//	      name: my-entry
+//	      key: value
+type BootEntryFactory struct {
+	Entries []*BootEntryFactoryE `yaml:"entries" json:"entries"`
+}
+
+// BootEntryFactoryE element of BootEntryFactory
+type BootEntryFactoryE struct {
+	Type              string                 `yaml:"type" json:"type"`
+	Domain            string                 `yaml:"domain" json:"domain"`
+	Config            map[string]interface{} `yaml:"config" json:"config"`
+	BootTimeout       `yaml:",inline" json:",inline" mapstructure:",squash"`
+	BootFailurePolicy `yaml:",inline" json:",inline" mapstructure:",squash"`
+}
+
+// RegisterEntryFactory register EntryFactory with type name, factory with the same type name will be replaced.
+func RegisterEntryFactory(typeName string, factory EntryFactory) {
+	if len(typeName) < 1 || factory == nil {
+		return
+	}
+
+	entryFactoriesMut.Lock()
+	defer entryFactoriesMut.Unlock()
+
+	entryFactories[typeName] = factory
+}
+
+// UnregisterEntryFactory remove EntryFactory with type name.
+func UnregisterEntryFactory(typeName string) {
+	entryFactoriesMut.Lock()
+	defer entryFactoriesMut.Unlock()
+
+	delete(entryFactories, typeName)
+}
+
+// ListEntryFactories list type names of registered EntryFactory.
+func ListEntryFactories() []string {
+	entryFactoriesMut.RLock()
+	defer entryFactoriesMut.RUnlock()
+
+	res := make([]string, 0, len(entryFactories))
+	for k := range entryFactories {
+		res = append(res, k)
+	}
+
+	return res
+}
+
+// RegisterEntriesFromFactory create entries with registered EntryFactory and add them into GlobalAppCtx.
+//
+// Blocks with domain not matching DOMAIN env are skipped.
+// An error will be returned if factory of type is missing or factory failed, no entry will be added in that case.
+func RegisterEntriesFromFactory(boot *BootEntryFactory) ([]Entry, error) {
+	res := make([]Entry, 0)
+	blocks := make([]*BootEntryFactoryE, 0)
+
+	// 1: create all entries before adding any of them
+	for i, block := range boot.Entries {
+		if !IsValidDomain(block.Domain) {
+			continue
+		}
+
+		entryFactoriesMut.RLock()
+		factory, ok := entryFactories[block.Type]
+		entryFactoriesMut.RUnlock()
+
+		if !ok {
+			return nil, fmt.Errorf("entry factory is missing, type:%s, index:%d", block.Type, i)
+		}
+
+		raw := block.Config
+		if raw == nil {
+			raw = make(map[string]interface{})
+		}
+
+		entry, err := factory(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create entry, type:%s, index:%d, %v", block.Type, i, err)
+		}
+
+		if entry == nil {
+			continue
+		}
+
+		res = append(res, entry)
+		blocks = append(blocks, block)
+	}
+
+	// 2: timeouts and failure policies are cleared if any of them is invalid
+	for i, entry := range res {
+		err := GlobalAppCtx.SetEntryTimeout(entry.GetType(), entry.GetName(), &blocks[i].BootTimeout)
+		if err == nil {
+			err = GlobalAppCtx.SetEntryFailurePolicy(entry.GetType(), entry.GetName(), &blocks[i].BootFailurePolicy)
+		}
+
+		if err != nil {
+			for _, added := range res[:i+1] {
+				GlobalAppCtx.removeEntryLifecycle(added.GetType(), added.GetName())
+			}
+			return nil, fmt.Errorf("failed to create entry, type:%s, %v", blocks[i].Type, err)
+		}
+	}
+
+	// 3: add entries
+	for _, entry := range res {
+		GlobalAppCtx.AddEntry(entry)
+	}
+
+	return res, nil
+}
+
+// RegisterEntriesFromFactoryYAML parse entries section of raw YAML and create entries with registered EntryFactory.
+//
+// Boot config is parsed like other entries, overrides of env and --rkset, domain sections and enabledIf are applied.
+// An error will be returned if entries section is malformed.
+func RegisterEntriesFromFactoryYAML(raw []byte) (map[string]Entry, error) {
+	bootM, err := parseBootYAML(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	boot := &BootEntryFactory{}
+	if err := mapstructure.Decode(bootM, boot); err != nil {
+		return nil, fmt.Errorf("invalid entries in boot config, %v", err)
+	}
+
+	// keys are lower-cased while parsing boot config, restore keys in raw YAML since factories may be case-sensitive
+	originalM := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal(raw, &originalM); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]string)
+	collectOriginalKeys(originalM, keys)
+
+	for i := range boot.Entries {
+		if boot.Entries[i] == nil {
+			return nil, fmt.Errorf("invalid entry block, index:%d", i)
+		}
+		boot.Entries[i].Config, _ = restoreOriginalKeys(toStringKeyMap(boot.Entries[i].Config), keys).(map[string]interface{})
+	}
+
+	entries, err := RegisterEntriesFromFactory(boot)
+	if err != nil {
+		return nil, err
+	}
+
+	res := map[string]Entry{}
+	for i := range entries {
+		res[entries[i].GetName()] = entries[i]
+	}
+
+	return res, nil
+}
+
+// registerEntriesFromFactoryYAML is RegFunc of entries created by EntryFactory
+func registerEntriesFromFactoryYAML(raw []byte) map[string]Entry {
+	res, err := RegisterEntriesFromFactoryYAML(raw)
+	if err != nil {
+		ShutdownWithError(err)
+	}
+
+	return res
+}
+
+// collectOriginalKeys collects string keys in YAML recursively, mapped from lower-cased keys
+func collectOriginalKeys(in interface{}, keys map[string]string) {
+	switch v := in.(type) {
+	case map[interface{}]interface{}:
+		for k, e := range v {
+			if str, ok := k.(string); ok {
+				if _, exist := keys[strings.ToLower(str)]; !exist {
+					keys[strings.ToLower(str)] = str
+				}
+			}
+			collectOriginalKeys(e, keys)
+		}
+	case []interface{}:
+		for i := range v {
+			collectOriginalKeys(v[i], keys)
+		}
+	}
+}
+
+// restoreOriginalKeys replaces lower-cased keys of map returned by toStringKeyMap with keys collected by collectOriginalKeys
+func restoreOriginalKeys(in interface{}, keys map[string]string) interface{} {
+	switch v := in.(type) {
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			if original, ok := keys[k]; ok {
+				k = original
+			}
+			res[k] = restoreOriginalKeys(e, keys)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i := range v {
+			res[i] = restoreOriginalKeys(v[i], keys)
+		}
+		return res
+	default:
+		return in
+	}
+}
+
+// toStringKeyMap converts map[interface{}]interface{} unmarshalled by yaml.v2 into map[string]interface{} recursively.
+func toStringKeyMap(in interface{}) interface{} {
+	switch v := in.(type) {
+	case map[interface{}]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			res[fmt.Sprintf("%v", k)] = toStringKeyMap(e)
+		}
+		return res
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			res[k] = toStringKeyMap(e)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i := range v {
+			res[i] = toStringKeyMap(v[i])
+		}
+		return res
+	default:
+		return in
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRegisterEntryFactory(t *testing.T) {
+	defer UnregisterEntryFactory("ut-type")
+
+	// with invalid input
+	RegisterEntryFactory("", func(map[string]interface{}) (Entry, error) { return nil, nil })
+	RegisterEntryFactory("ut-type", nil)
+	assert.NotContains(t, ListEntryFactories(), "ut-type")
+
+	RegisterEntryFactory("ut-type", func(map[string]interface{}) (Entry, error) { return nil, nil })
+	assert.Contains(t, ListEntryFactories(), "ut-type")
+
+	UnregisterEntryFactory("ut-type")
+	assert.NotContains(t, ListEntryFactories(), "ut-type")
+}
+
+func TestRegisterEntriesFromFactoryYAML(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType("mock")
+	defer UnregisterEntryFactory("ut-type")
+
+	RegisterEntryFactory("ut-type", func(raw map[string]interface{}) (Entry, error) {
+		name, _ := raw["name"].(string)
+		if len(name) < 1 {
+			return nil, errors.New("name is required")
+		}
+
+		// nested keys should be kept as they are
+		nested, ok := raw["nested"].(map[string]interface{})
+		if !ok || nested["camelKey"] != "value" {
+			return nil, errors.New("invalid nested config")
+		}
+
+		return &EntryMock{Name: name}, nil
+	})
+
+	// happy case
+	bootStr := `
+---
+entries:
+  - type: ut-type
+    config:
+      name: ut-entry
+      nested:
+        camelKey: value
+  - type: ut-type
+    domain: ut-domain-not-match
+    config:
+      name: ut-entry-skipped
+`
+	entries, err := RegisterEntriesFromFactoryYAML([]byte(bootStr))
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+	assert.NotNil(t, entries["ut-entry"])
+	assert.NotNil(t, GlobalAppCtx.GetEntry("mock", "ut-entry"))
+
+	// missing factory
+	bootStr = `
+---
+entries:
+  - type: ut-type-missing
+`
+	_, err = RegisterEntriesFromFactoryYAML([]byte(bootStr))
+	assert.NotNil(t, err)
+
+	// factory failed
+	bootStr = `
+---
+entries:
+  - type: ut-type
+    config:
+      key: value
+`
+	_, err = RegisterEntriesFromFactoryYAML([]byte(bootStr))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "name is required")
+
+	// invalid YAML
+	_, err = RegisterEntriesFromFactoryYAML([]byte("entries: [invalid"))
+	assert.NotNil(t, err)
+
+	// invalid type of field
+	_, err = RegisterEntriesFromFactoryYAML([]byte("entries:\n  - type: [ut-type]"))
+	assert.NotNil(t, err)
+	_, err = RegisterEntriesFromFactoryYAML([]byte("entries:\n  - type: ut-type\n    config: [invalid]"))
+	assert.NotNil(t, err)
+}
+
+func TestRegisterEntriesFromFactoryYAML_WithBootConfigFeatures(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType("mock")
+	defer UnregisterEntryFactory("ut-type")
+
+	RegisterEntryFactory("ut-type", func(raw map[string]interface{}) (Entry, error) {
+		name, _ := raw["name"].(string)
+		return &EntryMock{Name: name}, nil
+	})
+
+	// overrides of env and enabledIf
+	t.Setenv("RK_ENTRIES_0_CONFIG_NAME", "ut-entry-env")
+	bootStr := `
+---
+entries:
+  - type: ut-type
+    config:
+      name: ut-entry
+  - type: ut-type
+    enabledIf: "false"
+    config:
+      name: ut-entry-disabled
+`
+	entries, err := RegisterEntriesFromFactoryYAML([]byte(bootStr))
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+	assert.NotNil(t, entries["ut-entry-env"])
+}
+
+func TestRegisterEntriesFromFactory_WithFailedBlock(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType("mock")
+	defer UnregisterEntryFactory("ut-type")
+
+	RegisterEntryFactory("ut-type", func(raw map[string]interface{}) (Entry, error) {
+		name, _ := raw["name"].(string)
+		if len(name) < 1 {
+			return nil, errors.New("name is required")
+		}
+		return &EntryMock{Name: name}, nil
+	})
+
+	// factory failed
+	_, err := RegisterEntriesFromFactory(&BootEntryFactory{
+		Entries: []*BootEntryFactoryE{
+			{Type: "ut-type", Config: map[string]interface{}{"name": "ut-entry-a"}},
+			{Type: "ut-type"},
+		},
+	})
+	assert.NotNil(t, err)
+	assert.Nil(t, GlobalAppCtx.GetEntry("mock", "ut-entry-a"))
+
+	// invalid timeout
+	_, err = RegisterEntriesFromFactory(&BootEntryFactory{
+		Entries: []*BootEntryFactoryE{
+			{
+				Type:        "ut-type",
+				Config:      map[string]interface{}{"name": "ut-entry-a"},
+				BootTimeout: BootTimeout{BootstrapTimeout: "1s"},
+			},
+			{
+				Type:        "ut-type",
+				Config:      map[string]interface{}{"name": "ut-entry-b"},
+				BootTimeout: BootTimeout{BootstrapTimeout: "invalid"},
+			},
+		},
+	})
+	assert.NotNil(t, err)
+	assert.Nil(t, GlobalAppCtx.GetEntry("mock", "ut-entry-a"))
+	assert.Nil(t, GlobalAppCtx.GetEntry("mock", "ut-entry-b"))
+	timeout, _ := GlobalAppCtx.GetEntryTimeout("mock", "ut-entry-a")
+	assert.Zero(t, timeout)
+}
+
+func TestRegisterEntriesFromFactoryYAML_AsRegFunc(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType("mock")
+	defer UnregisterEntryFactory("ut-type")
+
+	RegisterEntryFactory("ut-type", func(raw map[string]interface{}) (Entry, error) {
+		return &EntryMock{Name: "ut-entry"}, nil
+	})
+
+	entries := registerEntriesFromFactoryYAML([]byte("entries:\n  - type: ut-type"))
+	assert.NotNil(t, entries["ut-entry"])
+
+	// without entries section
+	assert.Empty(t, registerEntriesFromFactoryYAML([]byte("logger: []")))
+
+	defer assertPanic(t)
+	registerEntriesFromFactoryYAML([]byte("entries:\n  - type: ut-type-missing"))
+}
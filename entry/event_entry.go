@@ -6,19 +6,48 @@
 package rkentry
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"encoding/json"
+	"fmt"
 	"github.com/rookie-ninja/rk-entry/v2/middleware"
 	"github.com/rookie-ninja/rk-logger"
 	"github.com/rookie-ninja/rk-query"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"gopkg.in/natefinch/lumberjack.v2"
+	"io"
+	"os"
+	"runtime/debug"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 )
 
+const (
+	// PanicMsgKey pair key of panic message recorded by EventEntry.RecordPanic
+	PanicMsgKey = "panicMsg"
+	// PanicStackKey pair key of panic stack recorded by EventEntry.RecordPanic
+	PanicStackKey = "panicStack"
+	// PanicGoroutineKey pair key of goroutine id recorded by EventEntry.RecordPanic
+	PanicGoroutineKey = "panicGoroutine"
+	// ParentEventIdKey pair key of parent event id recorded by EventEntry.StartChild
+	ParentEventIdKey = "parentEventId"
+	// TruncatedValuesKey counter key of values truncated by limit of EventEntry
+	TruncatedValuesKey = "truncatedValues"
+	// DroppedPairsKey counter key of pairs dropped by limit of EventEntry
+	DroppedPairsKey = "droppedPairs"
+	// TruncatedValueMarker is appended to values truncated by limit of EventEntry
+	TruncatedValueMarker = "...(truncated)"
+)
+
+// PanicRecorder converts recovered value of panic into fields of event.
+type PanicRecorder func(event rkquery.Event, recovered interface{})
+
 // NewEventEntryNoop create event logger entry with noop event factory.
 // Event factory and event helper will be created with noop zap logger.
 // Since we don't need any log rotation in case of noop, lumberjack config will be nil.
@@ -86,6 +115,8 @@ func RegisterEventEntry(boot *BootEvent) []*EventEntry {
 	}
 
 	for _, event := range configMap {
+		validateBootConfig(event)
+
 		entry := &EventEntry{
 			entryName:        event.Name,
 			entryType:        EventEntryType,
@@ -148,6 +179,30 @@ func RegisterEventEntry(boot *BootEvent) []*EventEntry {
 		if eventLogger, err = rklogger.NewZapLoggerWithConfAndSyncer(eventLoggerConfig, eventLoggerLumberjackConfig, syncers); err != nil {
 			ShutdownWithError(err)
 		} else {
+			// events are written to sinks only
+			if event.SinkOnly {
+				eventLogger = zap.NewNop()
+			}
+
+			// mirror summary of events to stdout in dev mode
+			if event.Dev || GetActiveDomain() == "dev" {
+				entry.devMode = true
+				eventLogger = eventLogger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+					return zapcore.NewTee(core, newDevEventCore(os.Stdout))
+				}))
+			}
+
+			// write events with background goroutine
+			if event.Async.Enabled {
+				entry.asyncQueue = newAsyncEventQueue(&event.Async)
+				eventLogger = eventLogger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+					return entry.asyncQueue.wrap(core)
+				}))
+			}
+
+			// redact secret values before events are queued or written
+			eventLogger = eventLogger.WithOptions(zap.WrapCore(wrapSecretCore))
+
 			eventFactory = rkquery.NewEventFactory(
 				rkquery.WithZapLogger(eventLogger),
 				rkquery.WithAppName(GlobalAppCtx.GetAppInfoEntry().AppName),
@@ -155,12 +210,28 @@ func RegisterEventEntry(boot *BootEvent) []*EventEntry {
 				rkquery.WithEncoding(rkquery.ToEncoding(event.Encoding)))
 		}
 
+		for i := range event.Sinks {
+			sink, err := newEventSinkFromBoot(&event.Sinks[i])
+			if err != nil {
+				ShutdownWithError(fmt.Errorf("failed to create event sink, entry:%s, %v", event.Name, err))
+			}
+			entry.AddSink(sink)
+		}
+
 		entry.EventFactory = eventFactory
 		entry.EventHelper = rkquery.NewEventHelper(eventFactory)
 		entry.lokiSyncer = lokiSyncer
 		entry.baseLogger = eventLogger
 		entry.LoggerConfig = eventLoggerConfig
 		entry.LumberjackConfig = eventLoggerLumberjackConfig
+		entry.SetLimit(event.Limit.MaxValueLength, event.Limit.MaxPairs)
+
+		if err := GlobalAppCtx.SetEntryTimeout(EventEntryType, event.Name, &event.BootTimeout); err != nil {
+			ShutdownWithError(err)
+		}
+		if err := GlobalAppCtx.SetEntryFailurePolicy(EventEntryType, event.Name, &event.BootFailurePolicy); err != nil {
+			ShutdownWithError(err)
+		}
 
 		GlobalAppCtx.AddEntry(entry)
 		res = append(res, entry)
@@ -199,20 +270,50 @@ type BootLoki struct {
 	Password           string            `yaml:"password" json:"password"`
 	InsecureSkipVerify bool              `yaml:"insecureSkipVerify" json:"insecureSkipVerify"`
 	Labels             map[string]string `yaml:"labels" json:"labels"`
-	MaxBatchWaitMs     int               `yaml:"maxBatchWaitMs" json:"maxBatchWaitMs"`
-	MaxBatchSize       int               `yaml:"maxBatchSize" json:"maxBatchSize"`
+	MaxBatchWaitMs     int               `yaml:"maxBatchWaitMs" json:"maxBatchWaitMs" validate:"min=0"`
+	MaxBatchSize       int               `yaml:"maxBatchSize" json:"maxBatchSize" validate:"min=0"`
 }
 
 // BootEventE bootstrap element of EventEntry
 type BootEventE struct {
-	Name        string             `yaml:"name" json:"name"`
-	Description string             `yaml:"description" json:"description"`
-	Domain      string             `yaml:"domain" json:"domain"`
-	Default     bool               `yaml:"default" json:"default"`
-	Encoding    string             `yaml:"encoding" json:"encoding"`
-	OutputPaths []string           `yaml:"outputPaths" json:"outputPaths"`
-	Lumberjack  *lumberjack.Logger `yaml:"lumberjack" json:"lumberjack"`
-	Loki        BootLoki           `yaml:"loki" json:"loki"`
+	Name              string             `yaml:"name" json:"name"`
+	Description       string             `yaml:"description" json:"description"`
+	Domain            string             `yaml:"domain" json:"domain"`
+	Default           bool               `yaml:"default" json:"default"`
+	Dev               bool               `yaml:"dev" json:"dev"`
+	Encoding          string             `yaml:"encoding" json:"encoding" validate:"omitempty,oneofci=console json flatten"`
+	OutputPaths       []string           `yaml:"outputPaths" json:"outputPaths"`
+	Lumberjack        *lumberjack.Logger `yaml:"lumberjack" json:"lumberjack"`
+	Loki              BootLoki           `yaml:"loki" json:"loki"`
+	Async             BootEventAsync     `yaml:"async" json:"async"`
+	Sinks             []BootEventSink    `yaml:"sinks" json:"sinks" validate:"dive"`
+	SinkOnly          bool               `yaml:"sinkOnly" json:"sinkOnly"`
+	Limit             BootEventLimit     `yaml:"limit" json:"limit"`
+	BootTimeout       `yaml:",inline" json:",inline" mapstructure:",squash"`
+	BootFailurePolicy `yaml:",inline" json:",inline" mapstructure:",squash"`
+}
+
+// BootEventAsync bootstrap config of async mode of EventEntry.
+//
+// 1: BufferSize: Max events waiting to be written, default is 1024.
+// 2: FlushIntervalMs: Interval of flushing underlying writers, default is 1000.
+// 3: Policy: What to do while buffer is full, block or drop, default is block.
+type BootEventAsync struct {
+	Enabled         bool   `yaml:"enabled" json:"enabled"`
+	BufferSize      int    `yaml:"bufferSize" json:"bufferSize" validate:"min=0"`
+	FlushIntervalMs int    `yaml:"flushIntervalMs" json:"flushIntervalMs" validate:"min=0"`
+	Policy          string `yaml:"policy" json:"policy" validate:"omitempty,oneofci=block drop"`
+}
+
+// BootEventLimit bootstrap config of limits of pairs and payloads in each event, zero means unlimited.
+//
+// 1: MaxValueLength: Max bytes of value of pair and string payload, longer value is truncated with TruncatedValueMarker.
+// 2: MaxPairs: Max number of pairs, pairs beyond it are dropped.
+//
+// Number of truncated values and dropped pairs are recorded as counters of TruncatedValuesKey and DroppedPairsKey.
+type BootEventLimit struct {
+	MaxValueLength int `yaml:"maxValueLength" json:"maxValueLength" validate:"min=0"`
+	MaxPairs       int `yaml:"maxPairs" json:"maxPairs" validate:"min=0"`
 }
 
 // EventEntry contains bellow fields.
@@ -223,15 +324,28 @@ type EventEntry struct {
 	entryType        string               `yaml:"-" json:"-"`
 	entryDescription string               `yaml:"-" json:"-"`
 	IsDefault        bool                 `yaml:"-" json:"-"`
+	devMode          bool                 `yaml:"-" json:"-"`
 	LoggerConfig     *zap.Config          `yaml:"-" json:"-"`
 	LumberjackConfig *lumberjack.Logger   `yaml:"-" json:"-"`
 	lokiSyncer       *rklogger.LokiSyncer `yaml:"-" json:"-"`
 	baseLogger       *zap.Logger          `yaml:"-" json:"-"`
 	bootstrapOnce    sync.Once            `yaml:"-" json:"-"`
+	panicRecorder    PanicRecorder        `yaml:"-" json:"-"`
+	asyncQueue       *asyncEventQueue     `yaml:"-" json:"-"`
+	sinks            []EventSink          `yaml:"-" json:"-"`
+	sinkLock         sync.RWMutex         `yaml:"-" json:"-"`
+	limitLock        sync.RWMutex         `yaml:"-" json:"-"`
+	maxValueLength   int                  `yaml:"-" json:"-"`
+	maxPairs         int                  `yaml:"-" json:"-"`
 }
 
 // Bootstrap entry.
 func (entry *EventEntry) Bootstrap(ctx context.Context) {
+	// restart background writer stopped by Interrupt
+	if entry.asyncQueue != nil {
+		entry.asyncQueue.start()
+	}
+
 	entry.bootstrapOnce.Do(func() {
 		if entry.lokiSyncer != nil {
 			entry.lokiSyncer.Bootstrap(ctx)
@@ -241,9 +355,16 @@ func (entry *EventEntry) Bootstrap(ctx context.Context) {
 
 // Interrupt entry.
 func (entry *EventEntry) Interrupt(ctx context.Context) {
+	// drain buffered events first
+	if entry.asyncQueue != nil {
+		entry.asyncQueue.stop()
+	}
+
 	if entry.lokiSyncer != nil {
 		entry.lokiSyncer.Interrupt(ctx)
 	}
+
+	entry.closeSinks()
 }
 
 // GetName returns name of entry.
@@ -278,6 +399,7 @@ func (entry *EventEntry) MarshalJSON() ([]byte, error) {
 		EntryDescription string                  `yaml:"description" json:"description"`
 		LoggerConfig     *rklogger.ZapConfigWrap `yaml:"zapConfig" json:"zapConfig"`
 		LumberjackConfig *lumberjack.Logger      `yaml:"lumberjackConfig" json:"lumberjackConfig"`
+		Dev              bool                    `yaml:"dev" json:"dev"`
 	}
 
 	return json.Marshal(&innerEventLoggerEntry{
@@ -286,6 +408,7 @@ func (entry *EventEntry) MarshalJSON() ([]byte, error) {
 		EntryDescription: entry.entryDescription,
 		LoggerConfig:     loggerConfigWrap,
 		LumberjackConfig: entry.LumberjackConfig,
+		Dev:              entry.devMode,
 	})
 }
 
@@ -320,3 +443,515 @@ func (entry *EventEntry) Sync() {
 		entry.baseLogger.Sync()
 	}
 }
+
+// StartEvent start a new event with operation from default EventEntry in GlobalAppCtx.
+func StartEvent(operation string, opts ...rkquery.EventOption) rkquery.Event {
+	return GlobalAppCtx.GetEventEntryDefault().Start(operation, opts...)
+}
+
+// StartWithContext start a new event with operation, trace id will be extracted from context
+// with TraceIDExtractor set in GlobalAppCtx.
+//
+// Nothing will be added if extractor is missing or returns empty string.
+func (entry *EventEntry) StartWithContext(ctx context.Context, operation string, opts ...rkquery.EventOption) rkquery.Event {
+	event := entry.Start(operation, opts...)
+
+	if f := GlobalAppCtx.GetTraceIDExtractor(); f != nil && ctx != nil {
+		if traceId := f(ctx); len(traceId) > 0 {
+			event.SetTraceId(traceId)
+		}
+	}
+
+	return event
+}
+
+// StartChild start a new event with operation as child of parent.
+//
+// Event id of parent is recorded as pair of ParentEventIdKey, trace id, request id and remote address
+// of parent are propagated, so are pairs of parent with sharedKeys. A plain event is started if parent is nil.
+// Shared pairs are limited as well, refer to SetLimit.
+func (entry *EventEntry) StartChild(parent rkquery.Event, operation string, sharedKeys ...string) rkquery.Event {
+	event := entry.withLimit(entry.Start(operation))
+	if parent == nil {
+		return event
+	}
+
+	event.AddPair(ParentEventIdKey, parent.GetEventId())
+	event.SetTraceId(parent.GetTraceId())
+	event.SetRequestId(parent.GetRequestId())
+	event.SetRemoteAddr(parent.GetRemoteAddr())
+
+	for _, key := range sharedKeys {
+		if v := parent.GetValueFromPair(key); len(v) > 0 {
+			event.AddPair(key, v)
+		}
+	}
+
+	return event
+}
+
+// GetParentEventId returns event id of parent recorded by StartChild, empty string if missing.
+func GetParentEventId(event rkquery.Event) string {
+	if event == nil {
+		return ""
+	}
+
+	return event.GetValueFromPair(ParentEventIdKey)
+}
+
+// SetPanicRecorder override PanicRecorder used by RecordPanic.
+func (entry *EventEntry) SetPanicRecorder(f PanicRecorder) {
+	entry.panicRecorder = f
+}
+
+// RecordPanic adds recovered value of panic into event with standardized fields.
+//
+// By default, panic counter, error, panicMsg, panicStack and panicGoroutine pairs will be added.
+// Use SetPanicRecorder to override it.
+//
+// Call it in deferred function right after recover(). Pairs are limited even if event
+// was not created by EventEntry, refer to SetLimit.
+func (entry *EventEntry) RecordPanic(event rkquery.Event, recovered interface{}) {
+	if event == nil || recovered == nil {
+		return
+	}
+
+	event = entry.withLimit(event)
+
+	if entry.panicRecorder != nil {
+		entry.panicRecorder(event, recovered)
+		return
+	}
+
+	recordPanicDefault(event, recovered, debug.Stack())
+}
+
+// recordPanicDefault adds panic related fields into event.
+func recordPanicDefault(event rkquery.Event, recovered interface{}, stack []byte) {
+	var err error
+	if v, ok := recovered.(error); ok {
+		err = v
+	} else {
+		err = fmt.Errorf("%v", recovered)
+	}
+
+	event.SetCounter("panic", 1)
+	event.AddErr(err)
+	event.AddPair(PanicMsgKey, err.Error())
+	event.AddPair(PanicStackKey, string(stack))
+
+	// first line of stack looks like: goroutine 1 [running]:
+	if line := bytes.Fields(stack); len(line) > 1 && string(line[0]) == "goroutine" {
+		event.AddPair(PanicGoroutineKey, string(line[1]))
+	}
+}
+
+// IsAsync returns true if events are written by background goroutine.
+func (entry *EventEntry) IsAsync() bool {
+	return entry.asyncQueue != nil
+}
+
+// GetDroppedEventCount returns number of events dropped since buffer was full in async mode.
+func (entry *EventEntry) GetDroppedEventCount() int64 {
+	if entry.asyncQueue == nil {
+		return 0
+	}
+
+	return atomic.LoadInt64(&entry.asyncQueue.dropped)
+}
+
+// SetLimit set max bytes of values and max number of pairs of events created after this call,
+// zero or negative value means unlimited.
+func (entry *EventEntry) SetLimit(maxValueLength, maxPairs int) {
+	entry.limitLock.Lock()
+	defer entry.limitLock.Unlock()
+
+	entry.maxValueLength = maxValueLength
+	entry.maxPairs = maxPairs
+}
+
+// withLimit wraps event with limitEvent if limit is set and event is not wrapped yet
+func (entry *EventEntry) withLimit(event rkquery.Event) rkquery.Event {
+	entry.limitLock.RLock()
+	maxValueLength, maxPairs := entry.maxValueLength, entry.maxPairs
+	entry.limitLock.RUnlock()
+
+	if maxValueLength < 1 && maxPairs < 1 {
+		return event
+	}
+
+	switch v := event.(type) {
+	case *limitEvent:
+		return event
+	case *sinkEvent:
+		if _, ok := v.Event.(*limitEvent); ok {
+			return event
+		}
+	}
+
+	return &limitEvent{
+		Event:          event,
+		maxValueLength: maxValueLength,
+		maxPairs:       maxPairs,
+	}
+}
+
+// limitEvent truncates oversize values and drops pairs exceeding limit of EventEntry
+type limitEvent struct {
+	rkquery.Event
+	lock           sync.Mutex
+	maxValueLength int
+	maxPairs       int
+	pairs          int
+}
+
+// AddPair adds pair if number of pairs is within limit, value is truncated if it is too long
+func (event *limitEvent) AddPair(key, value string) {
+	event.lock.Lock()
+	defer event.lock.Unlock()
+
+	if event.maxPairs > 0 && event.pairs >= event.maxPairs {
+		event.Event.IncCounter(DroppedPairsKey, 1)
+		return
+	}
+
+	event.pairs++
+	event.Event.AddPair(key, event.truncate(value))
+}
+
+// AddPayloads adds payloads, values of string fields are truncated if they are too long
+func (event *limitEvent) AddPayloads(fields ...zap.Field) {
+	event.lock.Lock()
+	defer event.lock.Unlock()
+
+	for i := range fields {
+		if fields[i].Type == zapcore.StringType {
+			fields[i].String = event.truncate(fields[i].String)
+		}
+	}
+
+	event.Event.AddPayloads(fields...)
+}
+
+// truncate value at boundary of rune and record truncation, lock should be held by caller
+func (event *limitEvent) truncate(value string) string {
+	if event.maxValueLength < 1 || len(value) <= event.maxValueLength {
+		return value
+	}
+
+	end := event.maxValueLength
+	for end > 0 && !utf8.RuneStart(value[end]) {
+		end--
+	}
+
+	event.Event.IncCounter(TruncatedValuesKey, 1)
+	return value[:end] + TruncatedValueMarker
+}
+
+// IsDevMode returns true if summary of events will be mirrored to stdout.
+func (entry *EventEntry) IsDevMode() bool {
+	return entry.devMode
+}
+
+const (
+	devColorGreen = "\x1b[32m"
+	devColorRed   = "\x1b[31m"
+	devColorReset = "\x1b[0m"
+)
+
+// devEventCore is a zapcore.Core which renders a compact colored summary of finished events.
+//
+// Events flushed with JSON encoding carry fields directly, events flushed with console
+// encoding carry key=value lines in message, flatten encoding is already compact and printed as it is.
+type devEventCore struct {
+	writer io.Writer
+	lock   *sync.Mutex
+	fields []zapcore.Field
+}
+
+// newDevEventCore create devEventCore writes to writer.
+func newDevEventCore(writer io.Writer) *devEventCore {
+	return &devEventCore{
+		writer: writer,
+		lock:   &sync.Mutex{},
+		fields: make([]zapcore.Field, 0),
+	}
+}
+
+// Enabled always returns true
+func (core *devEventCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+// With returns a copy of core with additional fields
+func (core *devEventCore) With(fields []zapcore.Field) zapcore.Core {
+	res := &devEventCore{
+		writer: core.writer,
+		lock:   core.lock,
+		fields: make([]zapcore.Field, 0, len(core.fields)+len(fields)),
+	}
+	res.fields = append(res.fields, core.fields...)
+	res.fields = append(res.fields, fields...)
+
+	return res
+}
+
+// Check adds core to CheckedEntry
+func (core *devEventCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce.AddCore(ent, core)
+}
+
+// Write renders summary of event
+func (core *devEventCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	summary := core.summary(ent, fields)
+	if len(summary) < 1 {
+		return nil
+	}
+
+	core.lock.Lock()
+	defer core.lock.Unlock()
+
+	_, err := io.WriteString(core.writer, summary+"\n")
+	return err
+}
+
+// Sync is noop
+func (core *devEventCore) Sync() error {
+	return nil
+}
+
+// summary renders event as [resCode] operation elapsed k=v...
+func (core *devEventCore) summary(ent zapcore.Entry, fields []zapcore.Field) string {
+	values := make(map[string]interface{})
+
+	enc := zapcore.NewMapObjectEncoder()
+	for i := range core.fields {
+		core.fields[i].AddTo(enc)
+	}
+	for i := range fields {
+		fields[i].AddTo(enc)
+	}
+	for k, v := range enc.Fields {
+		values[k] = v
+	}
+
+	// console encoding, parse key=value lines
+	if strings.Contains(ent.Message, "\n") {
+		for _, line := range strings.Split(ent.Message, "\n") {
+			if tokens := strings.SplitN(line, "=", 2); len(tokens) == 2 {
+				values[tokens[0]] = tokens[1]
+			}
+		}
+	}
+
+	operation, ok := values["operation"]
+	if !ok {
+		// flatten encoding or non-event logs
+		return strings.TrimSpace(ent.Message)
+	}
+
+	resCode := fmt.Sprintf("%v", getDefaultIfNil(values["resCode"], "X"))
+	color := devColorGreen
+	if resCode != "OK" && !strings.HasPrefix(resCode, "2") {
+		color = devColorRed
+	}
+
+	var elapsed time.Duration
+	switch v := values["elapsedNano"].(type) {
+	case int64:
+		elapsed = time.Duration(v)
+	case string:
+		fmt.Sscanf(v, "%d", &elapsed)
+	}
+
+	if elapsed > time.Millisecond {
+		elapsed = elapsed.Round(time.Microsecond)
+	}
+
+	builder := &strings.Builder{}
+	builder.WriteString(fmt.Sprintf("%s[%s]%s %v %s", color, resCode, devColorReset, operation, elapsed))
+
+	// pairs
+	pairs := make(map[string]interface{})
+	switch v := values["pairs"].(type) {
+	case map[string]interface{}:
+		pairs = v
+	case string:
+		json.Unmarshal([]byte(v), &pairs)
+	}
+
+	keys := make([]string, 0, len(pairs))
+	for k := range pairs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		builder.WriteString(fmt.Sprintf(" %s=%v", k, pairs[k]))
+	}
+
+	return builder.String()
+}
+
+// getDefaultIfNil returns def if value is nil
+func getDefaultIfNil(value, def interface{}) interface{} {
+	if value == nil {
+		return def
+	}
+
+	return value
+}
+
+// asyncEventItem is a log entry waiting to be written
+type asyncEventItem struct {
+	core   zapcore.Core
+	ent    zapcore.Entry
+	fields []zapcore.Field
+}
+
+// asyncEventQueue buffers log entries of events and writes them with background goroutine.
+type asyncEventQueue struct {
+	ch            chan *asyncEventItem
+	drop          bool
+	flushInterval time.Duration
+	dropped       int64
+	root          zapcore.Core
+	closed        bool
+	lock          sync.RWMutex
+	done          chan struct{}
+}
+
+// newAsyncEventQueue create asyncEventQueue with defaults
+func newAsyncEventQueue(boot *BootEventAsync) *asyncEventQueue {
+	queue := &asyncEventQueue{
+		ch:            make(chan *asyncEventItem, 1024),
+		flushInterval: time.Second,
+		drop:          strings.EqualFold(boot.Policy, "drop"),
+		done:          make(chan struct{}),
+	}
+
+	if boot.BufferSize > 0 {
+		queue.ch = make(chan *asyncEventItem, boot.BufferSize)
+	}
+
+	if boot.FlushIntervalMs > 0 {
+		queue.flushInterval = time.Duration(boot.FlushIntervalMs) * time.Millisecond
+	}
+
+	return queue
+}
+
+// wrap core and start background writer, should be called once
+func (queue *asyncEventQueue) wrap(core zapcore.Core) zapcore.Core {
+	queue.root = core
+	go queue.run(queue.ch, queue.done)
+
+	return &asyncEventCore{
+		Core:  core,
+		queue: queue,
+	}
+}
+
+// run writes buffered entries and flush periodically until queue stopped
+func (queue *asyncEventQueue) run(ch chan *asyncEventItem, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(queue.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case item, ok := <-ch:
+			if !ok {
+				queue.root.Sync()
+				return
+			}
+			item.core.Write(item.ent, item.fields)
+		case <-ticker.C:
+			queue.root.Sync()
+		}
+	}
+}
+
+// enqueue entry, write it synchronously if queue was stopped
+func (queue *asyncEventQueue) enqueue(item *asyncEventItem) error {
+	queue.lock.RLock()
+	defer queue.lock.RUnlock()
+
+	if queue.closed {
+		return item.core.Write(item.ent, item.fields)
+	}
+
+	if !queue.drop {
+		queue.ch <- item
+		return nil
+	}
+
+	select {
+	case queue.ch <- item:
+	default:
+		atomic.AddInt64(&queue.dropped, 1)
+	}
+
+	return nil
+}
+
+// start background writer with new buffer if queue was stopped
+func (queue *asyncEventQueue) start() {
+	queue.lock.Lock()
+	defer queue.lock.Unlock()
+
+	if !queue.closed || queue.root == nil {
+		return
+	}
+
+	queue.ch = make(chan *asyncEventItem, cap(queue.ch))
+	queue.done = make(chan struct{})
+	queue.closed = false
+	go queue.run(queue.ch, queue.done)
+}
+
+// stop queue and wait for buffered entries to be written
+func (queue *asyncEventQueue) stop() {
+	queue.lock.Lock()
+	if !queue.closed {
+		queue.closed = true
+		close(queue.ch)
+	}
+	done := queue.done
+	queue.lock.Unlock()
+
+	<-done
+}
+
+// asyncEventCore is a zapcore.Core which enqueue entries into asyncEventQueue
+type asyncEventCore struct {
+	zapcore.Core
+	queue *asyncEventQueue
+}
+
+// With encode fields with underlying core synchronously
+func (core *asyncEventCore) With(fields []zapcore.Field) zapcore.Core {
+	return &asyncEventCore{
+		Core:  core.Core.With(fields),
+		queue: core.queue,
+	}
+}
+
+// Check adds core to CheckedEntry
+func (core *asyncEventCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if core.Enabled(ent.Level) {
+		return ce.AddCore(ent, core)
+	}
+
+	return ce
+}
+
+// Write enqueue entry
+func (core *asyncEventCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return core.queue.enqueue(&asyncEventItem{
+		core:   core.Core,
+		ent:    ent,
+		fields: fields,
+	})
+}
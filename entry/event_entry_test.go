@@ -6,8 +6,18 @@
 package rkentry
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"github.com/rookie-ninja/rk-query"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 )
 
@@ -108,3 +118,341 @@ func TestEventEntry_Syncer(t *testing.T) {
 	entries[0].AddLabelToLokiSyncer("key", "value")
 	entries[0].Sync()
 }
+
+func TestEventEntry_RecordPanic(t *testing.T) {
+	defer assertNotPanic(t)
+
+	entry := NewEventEntryStdout()
+
+	// with nil event
+	entry.RecordPanic(nil, "ut-panic")
+
+	// with default recorder
+	event := entry.Start("op")
+	entry.RecordPanic(event, "ut-panic")
+	assert.Equal(t, "ut-panic", event.GetValueFromPair(PanicMsgKey))
+	assert.NotEmpty(t, event.GetValueFromPair(PanicStackKey))
+	assert.NotEmpty(t, event.GetValueFromPair(PanicGoroutineKey))
+	entry.Finish(event)
+
+	// with custom recorder
+	called := false
+	entry.SetPanicRecorder(func(event rkquery.Event, recovered interface{}) {
+		called = true
+		event.AddPair(PanicMsgKey, "custom")
+	})
+	event = entry.Start("op")
+	entry.RecordPanic(event, errors.New("ut-panic"))
+	assert.True(t, called)
+	assert.Equal(t, "custom", event.GetValueFromPair(PanicMsgKey))
+}
+
+func TestEventEntry_StartChild(t *testing.T) {
+	buf := &bytes.Buffer{}
+	entry := &EventEntry{
+		entryName: "ut-event-child",
+		entryType: EventEntryType,
+		EventHelper: rkquery.NewEventHelper(rkquery.NewEventFactory(
+			rkquery.WithZapLogger(zap.New(zapcore.NewCore(
+				zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(buf), zap.DebugLevel))),
+			rkquery.WithEncoding(rkquery.JSON))),
+	}
+
+	parent := entry.Start("parent")
+	parent.SetTraceId("ut-trace")
+	parent.SetRequestId("ut-request")
+	parent.AddPair("tenant", "ut-tenant")
+	parent.AddPair("private", "ut-private")
+
+	child := entry.StartChild(parent, "child", "tenant", "missing")
+	assert.Equal(t, "child", child.GetOperation())
+	assert.NotEqual(t, parent.GetEventId(), child.GetEventId())
+	assert.Equal(t, parent.GetEventId(), GetParentEventId(child))
+	assert.Equal(t, "ut-trace", child.GetTraceId())
+	assert.Equal(t, "ut-request", child.GetRequestId())
+	assert.Equal(t, "ut-tenant", child.GetValueFromPair("tenant"))
+	assert.Empty(t, child.GetValueFromPair("private"))
+	assert.Empty(t, child.GetValueFromPair("missing"))
+
+	entry.Finish(child)
+	assert.Contains(t, buf.String(), `"parentEventId":"`+parent.GetEventId()+`"`)
+
+	// without parent
+	assert.Empty(t, GetParentEventId(entry.StartChild(nil, "orphan")))
+	assert.Empty(t, GetParentEventId(nil))
+}
+
+func TestStartEvent(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(EventEntryType)
+
+	// without default entry
+	assert.Equal(t, EventEntryStdout.EventHelper, GlobalAppCtx.GetEventHelperDefault())
+	event := StartEvent("ut-op")
+	assert.Equal(t, "ut-op", event.GetOperation())
+
+	// with default entry
+	entry := NewEventEntryStdout()
+	entry.entryName = "ut-default"
+	entry.IsDefault = true
+	entry.SetLimit(0, 1)
+	GlobalAppCtx.AddEntry(entry)
+	assert.Equal(t, entry.EventHelper, GlobalAppCtx.GetEventHelperDefault())
+
+	event = StartEvent("ut-op")
+	event.AddPair("k1", "v1")
+	event.AddPair("k2", "v2")
+	assert.Equal(t, "v1", event.GetValueFromPair("k1"))
+	assert.Empty(t, event.GetValueFromPair("k2"))
+	assert.Equal(t, int64(1), event.GetCounter(DroppedPairsKey))
+}
+
+func TestEventEntry_StartWithContext(t *testing.T) {
+	defer GlobalAppCtx.SetTraceIDExtractor(nil)
+
+	entry := NewEventEntryStdout()
+
+	// without extractor
+	event := entry.StartWithContext(context.TODO(), "op")
+	assert.Empty(t, event.GetTraceId())
+
+	type traceKey struct{}
+	GlobalAppCtx.SetTraceIDExtractor(func(ctx context.Context) string {
+		if v, ok := ctx.Value(traceKey{}).(string); ok {
+			return v
+		}
+		return ""
+	})
+
+	// without trace id in context
+	event = entry.StartWithContext(context.TODO(), "op")
+	assert.Empty(t, event.GetTraceId())
+
+	// with trace id in context
+	event = entry.StartWithContext(context.WithValue(context.TODO(), traceKey{}, "ut-trace"), "op")
+	assert.Equal(t, "ut-trace", event.GetTraceId())
+	assert.Equal(t, "op", event.GetOperation())
+}
+
+func TestRegisterEventEntry_WithDev(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(EventEntryType)
+
+	// with boot config
+	entries := RegisterEventEntry(&BootEvent{
+		Event: []*BootEventE{
+			{
+				Name:        "ut-event",
+				Dev:         true,
+				OutputPaths: []string{"stdout"},
+			},
+		},
+	})
+	assert.True(t, entries[0].IsDevMode())
+	entries[0].Finish(entries[0].Start("op"))
+
+	// with DOMAIN=dev
+	t.Setenv("DOMAIN", "dev")
+	entries = RegisterEventEntry(&BootEvent{
+		Event: []*BootEventE{
+			{
+				Name:        "ut-event",
+				OutputPaths: []string{"stdout"},
+			},
+		},
+	})
+	assert.True(t, entries[0].IsDevMode())
+}
+
+func TestDevEventCore(t *testing.T) {
+	for _, encoding := range []string{"json", "console", "flatten"} {
+		buf := &bytes.Buffer{}
+		factory := rkquery.NewEventFactory(
+			rkquery.WithZapLogger(zap.New(newDevEventCore(buf))),
+			rkquery.WithEncoding(rkquery.ToEncoding(encoding)))
+		helper := rkquery.NewEventHelper(factory)
+
+		event := helper.Start("ut-op")
+		event.AddPair("b", "2")
+		event.AddPair("a", "1")
+		helper.Finish(event)
+
+		assert.Contains(t, buf.String(), "ut-op", encoding)
+		assert.Contains(t, buf.String(), "OK", encoding)
+		if encoding != "flatten" {
+			assert.Contains(t, buf.String(), " a=1 b=2", encoding)
+		}
+	}
+
+	// failed event
+	buf := &bytes.Buffer{}
+	helper := rkquery.NewEventHelper(rkquery.NewEventFactory(
+		rkquery.WithZapLogger(zap.New(newDevEventCore(buf))),
+		rkquery.WithEncoding(rkquery.JSON)))
+	helper.FinishWithCond(helper.Start("ut-op"), false)
+	assert.Contains(t, buf.String(), devColorRed+"[Fail]")
+}
+
+func TestRegisterEventEntry_WithInvalidBootConfig(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(EventEntryType)
+	defer assertPanic(t)
+
+	RegisterEventEntry(&BootEvent{
+		Event: []*BootEventE{
+			{
+				Name:     "ut-event",
+				Encoding: "xml",
+			},
+		},
+	})
+}
+
+func TestRegisterEventEntry_WithAsync(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(EventEntryType)
+
+	logPath := filepath.Join(t.TempDir(), "event.log")
+	entries := RegisterEventEntry(&BootEvent{
+		Event: []*BootEventE{
+			{
+				Name:        "ut-event",
+				Encoding:    "json",
+				OutputPaths: []string{logPath},
+				Async: BootEventAsync{
+					Enabled:    true,
+					BufferSize: 10,
+				},
+			},
+		},
+	})
+	entry := entries[0]
+	assert.True(t, entry.IsAsync())
+
+	for i := 0; i < 100; i++ {
+		entry.Finish(entry.Start("ut-op"))
+	}
+
+	// buffered events should be drained
+	entry.Interrupt(context.TODO())
+	content, err := os.ReadFile(logPath)
+	assert.Nil(t, err)
+	assert.Equal(t, 100, strings.Count(string(content), "ut-op"))
+	assert.Zero(t, entry.GetDroppedEventCount())
+
+	// events after interrupt are written synchronously
+	entry.Finish(entry.Start("ut-op"))
+	content, _ = os.ReadFile(logPath)
+	assert.Equal(t, 101, strings.Count(string(content), "ut-op"))
+
+	// background writer is restarted after bootstrap
+	entry.Bootstrap(context.TODO())
+	assert.False(t, entry.asyncQueue.closed)
+	for i := 0; i < 100; i++ {
+		entry.Finish(entry.Start("ut-op"))
+	}
+	entry.Interrupt(context.TODO())
+	content, _ = os.ReadFile(logPath)
+	assert.Equal(t, 201, strings.Count(string(content), "ut-op"))
+	assert.Zero(t, entry.GetDroppedEventCount())
+}
+
+func TestAsyncEventQueue_WithDrop(t *testing.T) {
+	release := make(chan struct{})
+	queue := newAsyncEventQueue(&BootEventAsync{
+		Enabled:    true,
+		BufferSize: 1,
+		Policy:     "drop",
+	})
+	core := queue.wrap(&blockingCoreMock{
+		Core:    zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(&bytes.Buffer{}), zap.DebugLevel),
+		release: release,
+	})
+
+	logger := zap.New(core)
+	for i := 0; i < 10; i++ {
+		logger.Info("msg")
+	}
+
+	assert.Greater(t, atomic.LoadInt64(&queue.dropped), int64(0))
+
+	close(release)
+	queue.stop()
+}
+
+type blockingCoreMock struct {
+	zapcore.Core
+	release chan struct{}
+}
+
+func (core *blockingCoreMock) Write(zapcore.Entry, []zapcore.Field) error {
+	<-core.release
+	return nil
+}
+
+func TestEventEntry_SetLimit(t *testing.T) {
+	buf := &bytes.Buffer{}
+	entry := &EventEntry{
+		entryName: "ut-event-limit",
+		entryType: EventEntryType,
+		EventHelper: rkquery.NewEventHelper(rkquery.NewEventFactory(
+			rkquery.WithZapLogger(zap.New(zapcore.NewCore(
+				zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(buf), zap.DebugLevel))),
+			rkquery.WithEncoding(rkquery.JSON))),
+	}
+
+	// unlimited by default
+	event := entry.Start("ut-unlimited")
+	event.AddPair("key", strings.Repeat("a", 64))
+	assert.Len(t, event.GetValueFromPair("key"), 64)
+
+	entry.SetLimit(8, 2)
+	event = entry.Start("ut-limited")
+	event.AddPair("short", "value")
+	event.AddPair("long", strings.Repeat("a", 7)+"世界")
+	event.AddPair("dropped", "value")
+	event.AddPayloads(zap.String("payload", strings.Repeat("b", 16)), zap.Int("int", 1))
+
+	assert.Equal(t, "value", event.GetValueFromPair("short"))
+	assert.Equal(t, strings.Repeat("a", 7)+TruncatedValueMarker, event.GetValueFromPair("long"))
+	assert.Empty(t, event.GetValueFromPair("dropped"))
+	assert.Equal(t, int64(2), event.GetCounter(TruncatedValuesKey))
+	assert.Equal(t, int64(1), event.GetCounter(DroppedPairsKey))
+
+	entry.Finish(event)
+	assert.Contains(t, buf.String(), `"payload":"bbbbbbbb`+TruncatedValueMarker+`"`)
+	assert.Contains(t, buf.String(), `"droppedPairs":1`)
+}
+
+func TestEventEntry_SetLimit_WithChildAndPanic(t *testing.T) {
+	entry := &EventEntry{
+		entryName: "ut-event-limit",
+		entryType: EventEntryType,
+		EventHelper: rkquery.NewEventHelper(rkquery.NewEventFactory(
+			rkquery.WithZapLogger(zap.NewNop()), rkquery.WithEncoding(rkquery.JSON))),
+	}
+	entry.SetLimit(8, 2)
+
+	// shared pairs of child are limited
+	parent := entry.EventHelper.Start("ut-parent")
+	parent.AddPair("key", strings.Repeat("a", 16))
+	child := entry.StartChild(parent, "ut-child", "key")
+	assert.Equal(t, strings.Repeat("a", 8)+TruncatedValueMarker, child.GetValueFromPair("key"))
+	assert.Equal(t, int64(2), child.GetCounter(TruncatedValuesKey))
+
+	// panic of event not created by entry is limited
+	event := entry.EventHelper.Start("ut-panic")
+	entry.RecordPanic(event, "ut-panic")
+	assert.Equal(t, "ut-panic", event.GetValueFromPair(PanicMsgKey))
+	assert.True(t, strings.HasSuffix(event.GetValueFromPair(PanicStackKey), TruncatedValueMarker))
+	assert.Empty(t, event.GetValueFromPair(PanicGoroutineKey))
+	assert.Equal(t, int64(1), event.GetCounter(DroppedPairsKey))
+
+	// limit could be changed while events are created
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			entry.SetLimit(i, i)
+			entry.Start("ut-op").AddPair("key", "value")
+		}(i)
+	}
+	wg.Wait()
+}
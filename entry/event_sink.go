@@ -0,0 +1,308 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/rookie-ninja/rk-query"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// EventSinkTypeStdout type of EventSink which writes JSON lines to stdout
+	EventSinkTypeStdout = "stdout"
+	// EventSinkTypeFile type of EventSink which writes JSON lines to file
+	EventSinkTypeFile = "file"
+)
+
+// EventSink receives finished events of EventEntry.
+//
+// Sink which implements io.Closer will be closed while EventEntry is interrupted.
+type EventSink interface {
+	Write(event rkquery.Event) error
+}
+
+// BootEventSink bootstrap config of EventSink.
+//
+// 1: Type: stdout or file.
+// 2: Path: File path of sink with type of file, events are appended as JSON lines.
+type BootEventSink struct {
+	Type string `yaml:"type" json:"type" validate:"oneofci=stdout file"`
+	Path string `yaml:"path" json:"path" validate:"required_if=Type file"`
+}
+
+// NewEventSinkWriter creates EventSink which writes events as JSON lines to writer.
+func NewEventSinkWriter(writer io.Writer) EventSink {
+	return &writerEventSink{writer: writer}
+}
+
+// NewEventSinkStdout creates EventSink which writes events as JSON lines to stdout.
+func NewEventSinkStdout() EventSink {
+	return NewEventSinkWriter(os.Stdout)
+}
+
+// NewEventSinkFile creates EventSink which appends events as JSON lines to file.
+func NewEventSinkFile(filePath string) (EventSink, error) {
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &writerEventSink{writer: file, closer: file}, nil
+}
+
+// newEventSinkFromBoot creates EventSink from boot config
+func newEventSinkFromBoot(boot *BootEventSink) (EventSink, error) {
+	switch strings.ToLower(boot.Type) {
+	case EventSinkTypeStdout:
+		return NewEventSinkStdout(), nil
+	case EventSinkTypeFile:
+		return NewEventSinkFile(boot.Path)
+	}
+
+	return nil, fmt.Errorf("unknown type of event sink:%s", boot.Type)
+}
+
+// writerEventSink writes events as JSON lines
+type writerEventSink struct {
+	lock   sync.Mutex
+	writer io.Writer
+	closer io.Closer
+}
+
+// Write event as a JSON line
+func (sink *writerEventSink) Write(event rkquery.Event) error {
+	bytes, err := json.Marshal(eventToMap(event))
+	if err != nil {
+		return err
+	}
+
+	sink.lock.Lock()
+	defer sink.lock.Unlock()
+
+	_, err = sink.writer.Write(append(bytes, '\n'))
+	return err
+}
+
+// Close underlying file
+func (sink *writerEventSink) Close() error {
+	if sink.closer != nil {
+		return sink.closer.Close()
+	}
+
+	return nil
+}
+
+// eventToMap converts event to map, errors are not included since they can not be listed.
+//
+// Pairs and counters are included if event implements eventLister, like events passed to EventSink by EventEntry.
+func eventToMap(event rkquery.Event) map[string]interface{} {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, field := range event.ListPayloads() {
+		field.AddTo(enc)
+	}
+
+	pairs, counters := make(map[string]string), make(map[string]int64)
+	if lister, ok := event.(eventLister); ok {
+		pairs, counters = lister.listPairs(), lister.listCounters()
+	}
+
+	return map[string]interface{}{
+		"eventId":     event.GetEventId(),
+		"traceId":     event.GetTraceId(),
+		"requestId":   event.GetRequestId(),
+		"operation":   event.GetOperation(),
+		"resCode":     event.GetResCode(),
+		"remoteAddr":  event.GetRemoteAddr(),
+		"eventStatus": event.GetEventStatus().String(),
+		"startTime":   event.GetStartTime().Format(time.RFC3339Nano),
+		"endTime":     event.GetEndTime().Format(time.RFC3339Nano),
+		"elapsedNano": event.GetEndTime().Sub(event.GetStartTime()).Nanoseconds(),
+		"payloads":    enc.Fields,
+		"pairs":       pairs,
+		"counters":    counters,
+	}
+}
+
+// AddSink add EventSink which receives events created by this entry after they are finished.
+func (entry *EventEntry) AddSink(sink EventSink) {
+	if sink == nil {
+		return
+	}
+
+	entry.sinkLock.Lock()
+	defer entry.sinkLock.Unlock()
+
+	entry.sinks = append(entry.sinks, sink)
+}
+
+// ListSinks list EventSink added to this entry.
+func (entry *EventEntry) ListSinks() []EventSink {
+	entry.sinkLock.RLock()
+	defer entry.sinkLock.RUnlock()
+
+	res := make([]EventSink, len(entry.sinks))
+	copy(res, entry.sinks)
+	return res
+}
+
+// CreateEvent creates event with EventFactory, event will be written to sinks after finished.
+func (entry *EventEntry) CreateEvent(opts ...rkquery.EventOption) rkquery.Event {
+	return entry.wrapEvent(entry.EventFactory.CreateEvent(opts...))
+}
+
+// CreateEventThreadSafe creates thread safe event with EventFactory, event will be written to sinks after finished.
+func (entry *EventEntry) CreateEventThreadSafe(opts ...rkquery.EventOption) rkquery.Event {
+	return entry.wrapEvent(entry.EventFactory.CreateEventThreadSafe(opts...))
+}
+
+// Start creates and starts event with operation, event will be written to sinks after finished.
+func (entry *EventEntry) Start(operation string, opts ...rkquery.EventOption) rkquery.Event {
+	return entry.wrapEvent(entry.EventHelper.Start(operation, opts...))
+}
+
+// wrapEvent wraps event with limitEvent if limit is set, and with sinkEvent if there is any sink
+func (entry *EventEntry) wrapEvent(event rkquery.Event) rkquery.Event {
+	event = entry.withLimit(event)
+
+	if len(entry.ListSinks()) < 1 {
+		return event
+	}
+
+	return &sinkEvent{
+		Event:       event,
+		entry:       entry,
+		pairKeys:    make(map[string]bool),
+		counterKeys: make(map[string]bool),
+	}
+}
+
+// closeSinks close sinks which implements io.Closer
+func (entry *EventEntry) closeSinks() {
+	for _, sink := range entry.ListSinks() {
+		if v, ok := sink.(io.Closer); ok {
+			v.Close()
+		}
+	}
+}
+
+// eventLister lists pairs and counters of event which can not be listed from rkquery.Event
+type eventLister interface {
+	listPairs() map[string]string
+	listCounters() map[string]int64
+}
+
+// sinkEvent writes event to sinks of EventEntry after finished, keys of pairs and counters are recorded
+type sinkEvent struct {
+	rkquery.Event
+	entry       *EventEntry
+	lock        sync.Mutex
+	pairKeys    map[string]bool
+	counterKeys map[string]bool
+}
+
+// AddPair adds pair and records key
+func (event *sinkEvent) AddPair(key, value string) {
+	event.recordKey(event.pairKeys, key)
+	event.Event.AddPair(key, value)
+}
+
+// SetCounter sets counter and records key
+func (event *sinkEvent) SetCounter(key string, value int64) {
+	event.recordKey(event.counterKeys, key)
+	event.Event.SetCounter(key, value)
+}
+
+// IncCounter increases counter and records key
+func (event *sinkEvent) IncCounter(key string, delta int64) {
+	event.recordKey(event.counterKeys, key)
+	event.Event.IncCounter(key, delta)
+}
+
+// recordKey adds key into keys
+func (event *sinkEvent) recordKey(keys map[string]bool, key string) {
+	event.lock.Lock()
+	defer event.lock.Unlock()
+
+	keys[key] = true
+}
+
+// listPairs returns non-empty pairs with recorded keys, pairs dropped by limit of EventEntry are excluded
+func (event *sinkEvent) listPairs() map[string]string {
+	event.lock.Lock()
+	defer event.lock.Unlock()
+
+	res := make(map[string]string, len(event.pairKeys))
+	for key := range event.pairKeys {
+		if v := event.Event.GetValueFromPair(key); len(v) > 0 {
+			res[key] = v
+		}
+	}
+
+	return res
+}
+
+// listCounters returns counters with recorded keys, counters of limit of EventEntry are included if positive
+func (event *sinkEvent) listCounters() map[string]int64 {
+	event.lock.Lock()
+	defer event.lock.Unlock()
+
+	res := make(map[string]int64, len(event.counterKeys))
+	for key := range event.counterKeys {
+		res[key] = event.Event.GetCounter(key)
+	}
+
+	for _, key := range []string{TruncatedValuesKey, DroppedPairsKey} {
+		if v := event.Event.GetCounter(key); v > 0 {
+			res[key] = v
+		}
+	}
+
+	return res
+}
+
+// Finish event and write it to sinks
+func (event *sinkEvent) Finish() {
+	event.Event.Finish()
+
+	finished := &listedEvent{
+		Event:    event.Event,
+		pairs:    event.listPairs(),
+		counters: event.listCounters(),
+	}
+
+	for _, sink := range event.entry.ListSinks() {
+		if err := sink.Write(finished); err != nil {
+			GlobalAppCtx.GetLoggerEntryDefault().Warn("Failed to write event to sink",
+				zap.String("eventEntry", event.entry.GetName()),
+				zap.Error(err))
+		}
+	}
+}
+
+// listedEvent is finished event passed to EventSink with pairs and counters listed
+type listedEvent struct {
+	rkquery.Event
+	pairs    map[string]string
+	counters map[string]int64
+}
+
+// listPairs returns pairs of event
+func (event *listedEvent) listPairs() map[string]string {
+	return event.pairs
+}
+
+// listCounters returns counters of event
+func (event *listedEvent) listCounters() map[string]int64 {
+	return event.counters
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/rookie-ninja/rk-query"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type failedEventSinkMock struct{}
+
+func (sink *failedEventSinkMock) Write(rkquery.Event) error {
+	return errors.New("ut-error")
+}
+
+func TestRegisterEventEntry_WithSinks(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(EventEntryType)
+
+	filePath := filepath.Join(t.TempDir(), "ut-events.log")
+	entries := RegisterEventEntry(&BootEvent{
+		Event: []*BootEventE{
+			{
+				Name:     "ut-event",
+				SinkOnly: true,
+				Sinks: []BootEventSink{
+					{Type: "file", Path: filePath},
+				},
+			},
+		},
+	})
+	assert.Len(t, entries, 1)
+	entry := entries[0]
+	assert.Len(t, entry.ListSinks(), 1)
+
+	buf := &bytes.Buffer{}
+	entry.AddSink(NewEventSinkWriter(buf))
+	entry.AddSink(&failedEventSinkMock{})
+	entry.AddSink(nil)
+	assert.Len(t, entry.ListSinks(), 3)
+
+	// with helper
+	event := entry.Start("ut-operation")
+	event.AddPayloads(zap.String("ut-key", "ut-value"))
+	event.AddPair("ut-pair", "ut-value")
+	event.SetCounter("ut-counter", 2)
+	event.IncCounter("ut-counter", 1)
+	entry.Finish(event)
+
+	// with factory
+	event = entry.CreateEventThreadSafe(rkquery.WithOperation("ut-thread-safe"))
+	event.Finish()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+
+	m := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal([]byte(lines[0]), &m))
+	assert.Equal(t, "ut-operation", m["operation"])
+	assert.Equal(t, "OK", m["resCode"])
+	assert.Equal(t, "ut-value", m["payloads"].(map[string]interface{})["ut-key"])
+	assert.Equal(t, map[string]interface{}{"ut-pair": "ut-value"}, m["pairs"])
+	assert.Equal(t, map[string]interface{}{"ut-counter": float64(3)}, m["counters"])
+
+	// pairs dropped by limit are excluded
+	entry.SetLimit(0, 1)
+	event = entry.Start("ut-limited")
+	event.AddPair("ut-pair", "ut-value")
+	event.AddPair("ut-dropped", "ut-value")
+	entry.Finish(event)
+	entry.SetLimit(0, 0)
+
+	lines = strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 3)
+	m = map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal([]byte(lines[2]), &m))
+	assert.Equal(t, map[string]interface{}{"ut-pair": "ut-value"}, m["pairs"])
+	assert.Equal(t, map[string]interface{}{DroppedPairsKey: float64(1)}, m["counters"])
+
+	// file sink is closed while interrupting
+	entry.Interrupt(context.TODO())
+	raw, err := os.ReadFile(filePath)
+	assert.Nil(t, err)
+	assert.Equal(t, buf.String(), string(raw))
+}
+
+func TestRegisterEventEntry_WithInvalidSink(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(EventEntryType)
+	defer assertPanic(t)
+
+	RegisterEventEntry(&BootEvent{
+		Event: []*BootEventE{
+			{
+				Name:  "ut-event",
+				Sinks: []BootEventSink{{Type: "file"}},
+			},
+		},
+	})
+}
@@ -0,0 +1,200 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EvalEnabledIf evaluates condition of enabledIf in boot config.
+//
+// Supported syntax:
+//
+//	${DOMAIN} == prod
+//	${REGION} != "us-east-1" && (${FEATURE_X} || ${DOMAIN} == beta)
+//	!${DISABLED}
+//
+// ${NAME} is replaced with environment variable, ${DOMAIN} is replaced with GetActiveDomain().
+// Operands are compared as strings, an operand alone is true unless it is empty, false or 0.
+func EvalEnabledIf(expr string) (bool, error) {
+	tokens, err := tokenizeExpr(expr)
+	if err != nil {
+		return false, fmt.Errorf("invalid enabledIf expression, expr:%s, %v", expr, err)
+	}
+
+	p := &exprParser{tokens: tokens}
+	res, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected token:%s", p.tokens[p.pos].value)
+	}
+	if err != nil {
+		return false, fmt.Errorf("invalid enabledIf expression, expr:%s, %v", expr, err)
+	}
+
+	return res, nil
+}
+
+// exprToken is a token of enabledIf expression, operand is true if token is a value
+type exprToken struct {
+	value   string
+	operand bool
+}
+
+// tokenizeExpr splits expression into tokens and resolves variables
+func tokenizeExpr(expr string) ([]exprToken, error) {
+	res := make([]exprToken, 0)
+
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case strings.HasPrefix(expr[i:], "&&"), strings.HasPrefix(expr[i:], "||"),
+			strings.HasPrefix(expr[i:], "=="), strings.HasPrefix(expr[i:], "!="):
+			res = append(res, exprToken{value: expr[i : i+2]})
+			i += 2
+		case c == '(' || c == ')' || c == '!':
+			res = append(res, exprToken{value: string(c)})
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(expr[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at:%d", i)
+			}
+			res = append(res, exprToken{value: expr[i+1 : i+1+end], operand: true})
+			i += end + 2
+		case strings.HasPrefix(expr[i:], "${"):
+			end := strings.IndexByte(expr[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated variable at:%d", i)
+			}
+			res = append(res, exprToken{value: lookupExprVar(expr[i+2 : i+end]), operand: true})
+			i += end + 1
+		default:
+			start := i
+			for i < len(expr) && !strings.ContainsRune(" \t()!&|=\"'$", rune(expr[i])) {
+				i++
+			}
+			if start == i {
+				return nil, fmt.Errorf("unexpected character:%q at:%d", c, i)
+			}
+			res = append(res, exprToken{value: expr[start:i], operand: true})
+		}
+	}
+
+	return res, nil
+}
+
+// lookupExprVar returns value of variable in expression
+func lookupExprVar(name string) string {
+	if name == domainEnvKey {
+		return GetActiveDomain()
+	}
+
+	return os.Getenv(name)
+}
+
+// exprParser is a recursive descent parser of enabledIf expression
+type exprParser struct {
+	tokens []exprToken
+	pos    int
+}
+
+// peek returns operator at current position, empty if it is an operand or end
+func (p *exprParser) peek() string {
+	if p.pos < len(p.tokens) && !p.tokens[p.pos].operand {
+		return p.tokens[p.pos].value
+	}
+	return ""
+}
+
+func (p *exprParser) parseOr() (bool, error) {
+	res, err := p.parseAnd()
+	for err == nil && p.peek() == "||" {
+		p.pos++
+		var right bool
+		right, err = p.parseAnd()
+		res = res || right
+	}
+
+	return res, err
+}
+
+func (p *exprParser) parseAnd() (bool, error) {
+	res, err := p.parseUnary()
+	for err == nil && p.peek() == "&&" {
+		p.pos++
+		var right bool
+		right, err = p.parseUnary()
+		res = res && right
+	}
+
+	return res, err
+}
+
+func (p *exprParser) parseUnary() (bool, error) {
+	if p.peek() == "!" {
+		p.pos++
+		res, err := p.parseUnary()
+		return !res, err
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (bool, error) {
+	if p.pos >= len(p.tokens) {
+		return false, fmt.Errorf("unexpected end of expression")
+	}
+
+	if p.peek() == "(" {
+		p.pos++
+		res, err := p.parseOr()
+		if err != nil {
+			return false, err
+		}
+		if p.peek() != ")" {
+			return false, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return res, nil
+	}
+
+	left := p.tokens[p.pos]
+	if !left.operand {
+		return false, fmt.Errorf("unexpected token:%s", left.value)
+	}
+	p.pos++
+
+	op := p.peek()
+	if op != "==" && op != "!=" {
+		return isTruthy(left.value), nil
+	}
+	p.pos++
+
+	if p.pos >= len(p.tokens) || !p.tokens[p.pos].operand {
+		return false, fmt.Errorf("missing operand after:%s", op)
+	}
+	right := p.tokens[p.pos]
+	p.pos++
+
+	if op == "==" {
+		return left.value == right.value, nil
+	}
+	return left.value != right.value, nil
+}
+
+// isTruthy returns false for empty, false and 0
+func isTruthy(s string) bool {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "false", "0":
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEvalEnabledIf(t *testing.T) {
+	defer SetActiveDomain("")
+	SetActiveDomain("prod")
+	t.Setenv("UT_REGION", "us-east-1")
+	t.Setenv("UT_FEATURE", "true")
+
+	cases := map[string]bool{
+		"${DOMAIN} == prod":                                 true,
+		"${DOMAIN} != prod":                                 false,
+		`${UT_REGION} == "us-east-1"`:                       true,
+		`${UT_REGION} == 'us-west-1' || ${UT_FEATURE}`:      true,
+		"${UT_FEATURE} && !${UT_MISSING}":                   true,
+		"!(${DOMAIN} == prod && ${UT_REGION} == us-east-1)": false,
+		"${UT_MISSING}":                                     false,
+		"false || 0":                                        false,
+		"(${DOMAIN} == beta || ${DOMAIN} == prod) && true":  true,
+	}
+
+	for expr, expect := range cases {
+		res, err := EvalEnabledIf(expr)
+		assert.Nil(t, err, expr)
+		assert.Equal(t, expect, res, expr)
+	}
+}
+
+func TestEvalEnabledIf_WithInvalidExpr(t *testing.T) {
+	invalid := []string{
+		"",
+		"${DOMAIN} ==",
+		"(${DOMAIN} == prod",
+		"${DOMAIN == prod",
+		`"prod`,
+		"a b",
+		"&& a",
+	}
+
+	for _, expr := range invalid {
+		_, err := EvalEnabledIf(expr)
+		assert.NotNil(t, err, expr)
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// GraphEdgeDependency edge declared by EntryDependent
+	GraphEdgeDependency = "dependency"
+	// GraphEdgeRef edge declared by EntryReferrer
+	GraphEdgeRef = "ref"
+)
+
+// EntryReferrer is an optional interface of Entry.
+// Entries returned by GetRefs are referenced by this entry, like LoggerEntry used by PromEntry.
+// Unlike EntryDependent, refs won't affect bootstrap order.
+type EntryReferrer interface {
+	// GetRefs returns names of entries this entry refers to
+	GetRefs() []string
+}
+
+// EntryGraph is registration graph of entries in GlobalAppCtx.
+type EntryGraph struct {
+	Nodes []*GraphNode `json:"nodes" yaml:"nodes"`
+	Edges []*GraphEdge `json:"edges" yaml:"edges"`
+}
+
+// GraphNode is an entry in EntryGraph, Missing is true if entry was referred but not registered.
+type GraphNode struct {
+	Id      string `json:"id" yaml:"id"`
+	Name    string `json:"name" yaml:"name"`
+	Type    string `json:"type" yaml:"type"`
+	Missing bool   `json:"missing" yaml:"missing"`
+}
+
+// GraphEdge is a relationship between two nodes with id of type/name.
+type GraphEdge struct {
+	From string `json:"from" yaml:"from"`
+	To   string `json:"to" yaml:"to"`
+	Kind string `json:"kind" yaml:"kind"`
+}
+
+// ExportGraph returns EntryGraph of entries and their dependencies and refs.
+//
+// Nodes and edges are sorted, so the result is stable for the same set of entries.
+func (ctx *appContext) ExportGraph() *EntryGraph {
+	res := &EntryGraph{
+		Nodes: make([]*GraphNode, 0),
+		Edges: make([]*GraphEdge, 0),
+	}
+
+	byName := make(map[string][]*GraphNode)
+	entries := make([]Entry, 0)
+	for _, m := range ctx.ListEntries() {
+		for _, entry := range m {
+			node := &GraphNode{
+				Id:   entry.GetType() + "/" + entry.GetName(),
+				Name: entry.GetName(),
+				Type: entry.GetType(),
+			}
+			res.Nodes = append(res.Nodes, node)
+			byName[node.Name] = append(byName[node.Name], node)
+			entries = append(entries, entry)
+		}
+	}
+
+	addEdges := func(from Entry, names []string, kind string) {
+		fromId := from.GetType() + "/" + from.GetName()
+		for _, name := range names {
+			if len(name) < 1 {
+				continue
+			}
+
+			targets, ok := byName[name]
+			if !ok {
+				node := &GraphNode{Id: "/" + name, Name: name, Missing: true}
+				res.Nodes = append(res.Nodes, node)
+				byName[name] = []*GraphNode{node}
+				targets = byName[name]
+			}
+
+			for _, target := range targets {
+				if target.Id == fromId {
+					continue
+				}
+				res.Edges = append(res.Edges, &GraphEdge{From: fromId, To: target.Id, Kind: kind})
+			}
+		}
+	}
+
+	for _, entry := range entries {
+		if v, ok := entry.(EntryDependent); ok {
+			addEdges(entry, v.GetDependencies(), GraphEdgeDependency)
+		}
+		if v, ok := entry.(EntryReferrer); ok {
+			addEdges(entry, v.GetRefs(), GraphEdgeRef)
+		}
+	}
+
+	sort.Slice(res.Nodes, func(i, j int) bool {
+		return res.Nodes[i].Id < res.Nodes[j].Id
+	})
+	sort.Slice(res.Edges, func(i, j int) bool {
+		left, right := res.Edges[i], res.Edges[j]
+		if left.From != right.From {
+			return left.From < right.From
+		}
+		if left.To != right.To {
+			return left.To < right.To
+		}
+		return left.Kind < right.Kind
+	})
+
+	return res
+}
+
+// DOT returns graphviz representation of EntryGraph.
+//
+// Dependency edges are solid and ref edges are dashed, missing nodes are red.
+func (g *EntryGraph) DOT() string {
+	builder := &strings.Builder{}
+	builder.WriteString("digraph entries {\n")
+	builder.WriteString("  node [shape=box];\n")
+
+	for _, node := range g.Nodes {
+		if node.Missing {
+			builder.WriteString(fmt.Sprintf("  %q [label=%q, color=red];\n", node.Id, node.Name+"\n(missing)"))
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("  %q [label=%q];\n", node.Id, node.Name+"\n"+node.Type))
+	}
+
+	for _, edge := range g.Edges {
+		style := "solid"
+		if edge.Kind == GraphEdgeRef {
+			style = "dashed"
+		}
+		builder.WriteString(fmt.Sprintf("  %q -> %q [label=%q, style=%s];\n", edge.From, edge.To, edge.Kind, style))
+	}
+
+	builder.WriteString("}\n")
+	return builder.String()
+}
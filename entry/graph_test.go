@@ -0,0 +1,57 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type referrerEntryMock struct {
+	EntryMock
+	refs []string
+}
+
+func (entry *referrerEntryMock) GetRefs() []string {
+	return entry.refs
+}
+
+func TestAppContext_ExportGraph(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	GlobalAppCtx.clearEntries()
+
+	trace := make([]string, 0)
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "a"}, trace: &trace})
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "b"}, deps: []string{"a"}, trace: &trace})
+	GlobalAppCtx.AddEntry(&referrerEntryMock{EntryMock: EntryMock{Name: "c"}, refs: []string{"b", "missing", ""}})
+
+	graph := GlobalAppCtx.ExportGraph()
+	assert.Len(t, graph.Nodes, 4)
+	assert.Equal(t, "/missing", graph.Nodes[0].Id)
+	assert.True(t, graph.Nodes[0].Missing)
+	assert.Equal(t, "mock/a", graph.Nodes[1].Id)
+
+	assert.Equal(t, []*GraphEdge{
+		{From: "mock/b", To: "mock/a", Kind: GraphEdgeDependency},
+		{From: "mock/c", To: "/missing", Kind: GraphEdgeRef},
+		{From: "mock/c", To: "mock/b", Kind: GraphEdgeRef},
+	}, graph.Edges)
+
+	// DOT
+	dot := graph.DOT()
+	assert.Contains(t, dot, "digraph entries {")
+	assert.Contains(t, dot, `"mock/b" -> "mock/a" [label="dependency", style=solid];`)
+	assert.Contains(t, dot, `"mock/c" -> "mock/b" [label="ref", style=dashed];`)
+	assert.Contains(t, dot, `"mock/a" [label="a\nmock"];`)
+	assert.Contains(t, dot, `"/missing" [label="missing\n(missing)", color=red];`)
+	assert.NotContains(t, dot, `\\n`)
+
+	// JSON
+	bytes, err := json.Marshal(graph)
+	assert.Nil(t, err)
+	assert.Contains(t, string(bytes), `"kind":"dependency"`)
+}
@@ -0,0 +1,266 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"context"
+	"encoding/json"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"sort"
+	"time"
+)
+
+const (
+	// GrpcHealthAppNameKey is header key of application name in response of GrpcHealthEntry
+	GrpcHealthAppNameKey = "x-rk-app-name"
+	// GrpcHealthAppVersionKey is header key of application version in response of GrpcHealthEntry
+	GrpcHealthAppVersionKey = "x-rk-app-version"
+	// DefaultGrpcHealthWatchInterval is the interval to check health status of watched service
+	DefaultGrpcHealthWatchInterval = 5 * time.Second
+)
+
+// BootGrpcHealth bootstrap config of GrpcHealthEntry.
+//
+// 1: Enabled: Enable gRPC health service.
+// 2: WatchInterval: Interval of checking status for Watch in milliseconds, 5000 by default.
+// 3: Services: Services with names of readiness probes which decide their status,
+// service without probes are backed by all probes like the overall service with empty name.
+type BootGrpcHealth struct {
+	Enabled       bool                     `yaml:"enabled" json:"enabled"`
+	WatchInterval int                      `yaml:"watchInterval" json:"watchInterval"`
+	Services      []*BootGrpcHealthService `yaml:"services" json:"services"`
+}
+
+// BootGrpcHealthService bootstrap config of service in GrpcHealthEntry.
+type BootGrpcHealthService struct {
+	Name   string   `yaml:"name" json:"name"`
+	Probes []string `yaml:"probes" json:"probes"`
+}
+
+// GrpcHealthEntry implements grpc_health_v1.HealthServer backed by readiness probes of GlobalAppCtx,
+// so that status of gRPC health protocol is consistent with ready handler of CommonServiceEntry.
+//
+// Service with empty name reports overall status of application. NOT_SERVING is reported while draining.
+// Name and version of application are sent as response header.
+type GrpcHealthEntry struct {
+	grpc_health_v1.UnimplementedHealthServer
+
+	entryName        string              `json:"-" yaml:"-"`
+	entryType        string              `json:"-" yaml:"-"`
+	entryDescription string              `json:"-" yaml:"-"`
+	WatchInterval    time.Duration       `json:"-" yaml:"-"`
+	services         map[string][]string `json:"-" yaml:"-"`
+}
+
+// GrpcHealthEntryOption option for GrpcHealthEntry
+type GrpcHealthEntryOption func(entry *GrpcHealthEntry)
+
+// WithNameGrpcHealthEntry provide name of GrpcHealthEntry
+func WithNameGrpcHealthEntry(name string) GrpcHealthEntryOption {
+	return func(entry *GrpcHealthEntry) {
+		entry.entryName = name
+	}
+}
+
+// WithServiceGrpcHealthEntry provide service with names of readiness probes which decide its status
+func WithServiceGrpcHealthEntry(service string, probes ...string) GrpcHealthEntryOption {
+	return func(entry *GrpcHealthEntry) {
+		entry.services[service] = probes
+	}
+}
+
+// WithWatchIntervalGrpcHealthEntry provide interval of checking status for Watch
+func WithWatchIntervalGrpcHealthEntry(d time.Duration) GrpcHealthEntryOption {
+	return func(entry *GrpcHealthEntry) {
+		if d > 0 {
+			entry.WatchInterval = d
+		}
+	}
+}
+
+// RegisterGrpcHealthEntry create GrpcHealthEntry with config, nil will be returned if not enabled.
+func RegisterGrpcHealthEntry(boot *BootGrpcHealth, opts ...GrpcHealthEntryOption) *GrpcHealthEntry {
+	if !boot.Enabled {
+		return nil
+	}
+
+	entry := &GrpcHealthEntry{
+		entryName:        "GrpcHealthEntry",
+		entryType:        GrpcHealthEntryType,
+		entryDescription: "Internal RK entry for gRPC health service.",
+		WatchInterval:    DefaultGrpcHealthWatchInterval,
+		services:         make(map[string][]string),
+	}
+
+	if boot.WatchInterval > 0 {
+		entry.WatchInterval = time.Duration(boot.WatchInterval) * time.Millisecond
+	}
+
+	for _, service := range boot.Services {
+		if service != nil {
+			entry.services[service.Name] = service.Probes
+		}
+	}
+
+	for i := range opts {
+		opts[i](entry)
+	}
+
+	return entry
+}
+
+// Bootstrap GrpcHealthEntry
+func (entry *GrpcHealthEntry) Bootstrap(ctx context.Context) {}
+
+// Interrupt GrpcHealthEntry
+func (entry *GrpcHealthEntry) Interrupt(ctx context.Context) {}
+
+// GetName returns name of entry
+func (entry *GrpcHealthEntry) GetName() string {
+	return entry.entryName
+}
+
+// GetType returns type of entry
+func (entry *GrpcHealthEntry) GetType() string {
+	return entry.entryType
+}
+
+// GetDescription returns description of entry
+func (entry *GrpcHealthEntry) GetDescription() string {
+	return entry.entryDescription
+}
+
+// String returns string of entry
+func (entry *GrpcHealthEntry) String() string {
+	bytes, _ := json.Marshal(entry)
+	return string(bytes)
+}
+
+// MarshalJSON Marshal entry
+func (entry *GrpcHealthEntry) MarshalJSON() ([]byte, error) {
+	m := map[string]interface{}{
+		"name":          entry.GetName(),
+		"type":          entry.GetType(),
+		"description":   entry.GetDescription(),
+		"watchInterval": entry.WatchInterval.String(),
+		"services":      entry.ListServices(),
+	}
+
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON Unmarshal entry
+func (entry *GrpcHealthEntry) UnmarshalJSON([]byte) error {
+	return nil
+}
+
+// Register mount gRPC health service on server
+func (entry *GrpcHealthEntry) Register(server grpc.ServiceRegistrar) {
+	grpc_health_v1.RegisterHealthServer(server, entry)
+}
+
+// ListServices returns sorted names of services declared in entry
+func (entry *GrpcHealthEntry) ListServices() []string {
+	res := make([]string, 0, len(entry.services))
+	for k := range entry.services {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+
+	return res
+}
+
+// GetStatus returns status of service, SERVICE_UNKNOWN if service is not declared
+// or any of its probes is not registered.
+//
+// Service is SERVING if all of its readiness probes are ready, service without probes
+// and service with empty name are SERVING if all readiness probes are ready.
+func (entry *GrpcHealthEntry) GetStatus(ctx context.Context, service string) grpc_health_v1.HealthCheckResponse_ServingStatus {
+	probes, ok := entry.services[service]
+	if !ok && len(service) > 0 {
+		return grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN
+	}
+
+	if GlobalAppCtx.IsDraining() {
+		return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	}
+
+	wanted := make(map[string]bool)
+	for i := range probes {
+		wanted[probes[i]] = true
+	}
+
+	ready, found := true, make(map[string]bool)
+	for _, res := range GlobalAppCtx.CheckReadiness(ctx, false) {
+		found[res.Name] = true
+		if res.Err != nil && (len(wanted) < 1 || wanted[res.Name]) {
+			ready = false
+		}
+	}
+
+	for name := range wanted {
+		if !found[name] {
+			return grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN
+		}
+	}
+
+	if !ready {
+		return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	}
+
+	return grpc_health_v1.HealthCheckResponse_SERVING
+}
+
+// Check implements grpc_health_v1.HealthServer, NotFound will be returned if status is SERVICE_UNKNOWN.
+func (entry *GrpcHealthEntry) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	grpc.SetHeader(ctx, entry.appInfoHeader())
+
+	res := entry.GetStatus(ctx, req.GetService())
+	if res == grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN {
+		return nil, status.Error(codes.NotFound, "unknown service")
+	}
+
+	return &grpc_health_v1.HealthCheckResponse{
+		Status: res,
+	}, nil
+}
+
+// Watch implements grpc_health_v1.HealthServer, status is checked with WatchInterval and sent if changed.
+func (entry *GrpcHealthEntry) Watch(req *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	stream.SetHeader(entry.appInfoHeader())
+
+	ticker := time.NewTicker(entry.WatchInterval)
+	defer ticker.Stop()
+
+	last := grpc_health_v1.HealthCheckResponse_ServingStatus(-1)
+	for {
+		if res := entry.GetStatus(stream.Context(), req.GetService()); res != last {
+			if err := stream.Send(&grpc_health_v1.HealthCheckResponse{Status: res}); err != nil {
+				return status.Error(codes.Canceled, "stream has ended")
+			}
+			last = res
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return status.Error(codes.Canceled, "stream has ended")
+		case <-ticker.C:
+		}
+	}
+}
+
+// appInfoHeader returns header with name and version of application
+func (entry *GrpcHealthEntry) appInfoHeader() metadata.MD {
+	appInfo := GlobalAppCtx.GetAppInfoEntry()
+
+	return metadata.Pairs(
+		GrpcHealthAppNameKey, appInfo.AppName,
+		GrpcHealthAppVersionKey, appInfo.Version)
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestRegisterGrpcHealthEntry(t *testing.T) {
+	// disabled
+	assert.Nil(t, RegisterGrpcHealthEntry(&BootGrpcHealth{}))
+
+	entry := RegisterGrpcHealthEntry(&BootGrpcHealth{
+		Enabled:       true,
+		WatchInterval: 100,
+		Services: []*BootGrpcHealthService{
+			{Name: "ut.Service", Probes: []string{"ut-db"}},
+		},
+	}, WithNameGrpcHealthEntry("ut-health"), WithServiceGrpcHealthEntry("ut.Other"))
+
+	assert.Equal(t, "ut-health", entry.GetName())
+	assert.Equal(t, GrpcHealthEntryType, entry.GetType())
+	assert.Equal(t, 100*time.Millisecond, entry.WatchInterval)
+	assert.Equal(t, []string{"ut.Other", "ut.Service"}, entry.ListServices())
+	assert.Contains(t, entry.String(), "ut.Service")
+}
+
+func TestGrpcHealthEntry_Check(t *testing.T) {
+	defer GlobalAppCtx.RemoveReadinessProbe("ut-db")
+	defer GlobalAppCtx.RemoveReadinessProbe("ut-cache")
+
+	dbDown := atomic.NewBool(false)
+	GlobalAppCtx.AddReadinessProbe("ut-db", func(ctx context.Context) error {
+		if dbDown.Load() {
+			return errors.New("db is down")
+		}
+		return nil
+	}, 0)
+	GlobalAppCtx.AddReadinessProbe("ut-cache", func(ctx context.Context) error { return nil }, 0)
+
+	entry := RegisterGrpcHealthEntry(&BootGrpcHealth{Enabled: true},
+		WithServiceGrpcHealthEntry("ut.Service", "ut-db"),
+		WithServiceGrpcHealthEntry("ut.Cache", "ut-cache"),
+		WithServiceGrpcHealthEntry("ut.Missing", "ut-missing"),
+		WithWatchIntervalGrpcHealthEntry(10*time.Millisecond))
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	entry.Register(server)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.Nil(t, err)
+	defer conn.Close()
+	client := grpc_health_v1.NewHealthClient(conn)
+
+	// all ready
+	header := metadata.MD{}
+	resp, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{}, grpc.Header(&header))
+	assert.Nil(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, resp.Status)
+	assert.Equal(t, []string{GlobalAppCtx.GetAppInfoEntry().AppName}, header.Get(GrpcHealthAppNameKey))
+
+	// unknown service
+	_, err = client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "ut.Unknown"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// service with unregistered probe
+	_, err = client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "ut.Missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// watch service
+	stream, err := client.Watch(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "ut.Service"})
+	assert.Nil(t, err)
+	resp, err = stream.Recv()
+	assert.Nil(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, resp.Status)
+
+	// db is down, only services depending on it are not serving
+	dbDown.Store(true)
+	resp, err = stream.Recv()
+	assert.Nil(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, resp.Status)
+
+	resp, err = client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, resp.Status)
+
+	resp, err = client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "ut.Cache"})
+	assert.Nil(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, resp.Status)
+}
@@ -0,0 +1,334 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v2"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// KubeEventReasonBootstrapCompleted reason of Kubernetes Event posted after all entries bootstrapped
+	KubeEventReasonBootstrapCompleted = "BootstrapCompleted"
+	// KubeEventReasonShutdownStarted reason of Kubernetes Event posted before entries are interrupted
+	KubeEventReasonShutdownStarted = "ShutdownStarted"
+
+	kubeServiceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+	kubeEventTimeout      = 5 * time.Second
+)
+
+// kubeEventRecorder posts Kubernetes Events with involved object of current pod.
+//
+// Pod is identified by environment variable of POD_NAME (hostname by default), POD_NAMESPACE and POD_UID,
+// which could be injected with downward API.
+type kubeEventRecorder struct {
+	server    string
+	token     string
+	namespace string
+	podName   string
+	podUID    string
+	client    *http.Client
+}
+
+// kubeConfig is the subset of kubeconfig file used by kubeEventRecorder.
+type kubeConfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Clusters       []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			CertificateAuthority     string `yaml:"certificate-authority"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+			InsecureSkipTlsVerify    bool   `yaml:"insecure-skip-tls-verify"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Contexts []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster   string `yaml:"cluster"`
+			User      string `yaml:"user"`
+			Namespace string `yaml:"namespace"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+	Users []struct {
+		Name string `yaml:"name"`
+		User struct {
+			Token                 string `yaml:"token"`
+			ClientCertificate     string `yaml:"client-certificate"`
+			ClientCertificateData string `yaml:"client-certificate-data"`
+			ClientKey             string `yaml:"client-key"`
+			ClientKeyData         string `yaml:"client-key-data"`
+		} `yaml:"user"`
+	} `yaml:"users"`
+}
+
+// newKubeEventRecorder creates kubeEventRecorder from in-cluster config,
+// or kubeconfig file declared by KUBECONFIG ($HOME/.kube/config by default) if not running in cluster.
+func newKubeEventRecorder() (*kubeEventRecorder, error) {
+	var recorder *kubeEventRecorder
+	var err error
+
+	if len(os.Getenv("KUBERNETES_SERVICE_HOST")) > 0 && fileExists(filepath.Join(kubeServiceAccountDir, "token")) {
+		recorder, err = newKubeEventRecorderInCluster()
+	} else {
+		recorder, err = newKubeEventRecorderFromKubeConfig()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if ns := os.Getenv("POD_NAMESPACE"); len(ns) > 0 {
+		recorder.namespace = ns
+	}
+	if len(recorder.namespace) < 1 {
+		recorder.namespace = "default"
+	}
+
+	recorder.podName = os.Getenv("POD_NAME")
+	if len(recorder.podName) < 1 {
+		if recorder.podName, err = os.Hostname(); err != nil {
+			return nil, err
+		}
+	}
+	recorder.podUID = os.Getenv("POD_UID")
+
+	return recorder, nil
+}
+
+// newKubeEventRecorderInCluster creates kubeEventRecorder with service account of pod
+func newKubeEventRecorderInCluster() (*kubeEventRecorder, error) {
+	token, err := os.ReadFile(filepath.Join(kubeServiceAccountDir, "token"))
+	if err != nil {
+		return nil, err
+	}
+
+	conf := &tls.Config{}
+	if ca, err := os.ReadFile(filepath.Join(kubeServiceAccountDir, "ca.crt")); err == nil {
+		conf.RootCAs = x509.NewCertPool()
+		conf.RootCAs.AppendCertsFromPEM(ca)
+	}
+
+	recorder := &kubeEventRecorder{
+		server: "https://" + net.JoinHostPort(os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")),
+		token:  strings.TrimSpace(string(token)),
+		client: &http.Client{
+			Timeout:   kubeEventTimeout,
+			Transport: &http.Transport{TLSClientConfig: conf},
+		},
+	}
+
+	if ns, err := os.ReadFile(filepath.Join(kubeServiceAccountDir, "namespace")); err == nil {
+		recorder.namespace = strings.TrimSpace(string(ns))
+	}
+
+	return recorder, nil
+}
+
+// newKubeEventRecorderFromKubeConfig creates kubeEventRecorder with current context of kubeconfig
+func newKubeEventRecorderFromKubeConfig() (*kubeEventRecorder, error) {
+	path := os.Getenv("KUBECONFIG")
+	if len(path) < 1 {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(home, ".kube", "config")
+	}
+	// only the first file is used if multiple files are listed
+	path = filepath.SplitList(path)[0]
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("neither in-cluster config nor kubeconfig is available, %v", err)
+	}
+
+	config := &kubeConfig{}
+	if err := yaml.Unmarshal(raw, config); err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig:%s, %v", path, err)
+	}
+
+	recorder := &kubeEventRecorder{}
+	conf := &tls.Config{}
+	found := false
+	for _, kubeCtx := range config.Contexts {
+		if kubeCtx.Name != config.CurrentContext {
+			continue
+		}
+		found = true
+		recorder.namespace = kubeCtx.Context.Namespace
+
+		for _, cluster := range config.Clusters {
+			if cluster.Name != kubeCtx.Context.Cluster {
+				continue
+			}
+			recorder.server = strings.TrimSuffix(cluster.Cluster.Server, "/")
+			conf.InsecureSkipVerify = cluster.Cluster.InsecureSkipTlsVerify
+
+			ca, err := readKubeConfigData(cluster.Cluster.CertificateAuthorityData, cluster.Cluster.CertificateAuthority)
+			if err != nil {
+				return nil, err
+			}
+			if len(ca) > 0 {
+				conf.RootCAs = x509.NewCertPool()
+				conf.RootCAs.AppendCertsFromPEM(ca)
+			}
+		}
+
+		for _, user := range config.Users {
+			if user.Name != kubeCtx.Context.User {
+				continue
+			}
+			recorder.token = user.User.Token
+
+			cert, err := readKubeConfigData(user.User.ClientCertificateData, user.User.ClientCertificate)
+			if err != nil {
+				return nil, err
+			}
+			key, err := readKubeConfigData(user.User.ClientKeyData, user.User.ClientKey)
+			if err != nil {
+				return nil, err
+			}
+			if len(cert) > 0 && len(key) > 0 {
+				pair, err := tls.X509KeyPair(cert, key)
+				if err != nil {
+					return nil, err
+				}
+				conf.Certificates = []tls.Certificate{pair}
+			}
+		}
+	}
+
+	if !found || len(recorder.server) < 1 {
+		return nil, fmt.Errorf("cluster of current context:%s is missing in kubeconfig:%s", config.CurrentContext, path)
+	}
+
+	recorder.client = &http.Client{
+		Timeout:   kubeEventTimeout,
+		Transport: &http.Transport{TLSClientConfig: conf},
+	}
+
+	return recorder, nil
+}
+
+// readKubeConfigData decode base64 data, or read file if data is empty
+func readKubeConfigData(data, path string) ([]byte, error) {
+	if len(data) > 0 {
+		return base64.StdEncoding.DecodeString(data)
+	}
+
+	if len(path) > 0 {
+		return os.ReadFile(path)
+	}
+
+	return nil, nil
+}
+
+// record post an Event of Normal type with reason and message
+func (r *kubeEventRecorder) record(c context.Context, reason, message string) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	component := GlobalAppCtx.GetAppInfoEntry().AppName
+
+	event := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Event",
+		"metadata": map[string]interface{}{
+			"generateName": r.podName + ".",
+			"namespace":    r.namespace,
+		},
+		"involvedObject": map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"name":       r.podName,
+			"namespace":  r.namespace,
+			"uid":        r.podUID,
+		},
+		"reason":             reason,
+		"message":            message,
+		"type":               "Normal",
+		"count":              1,
+		"firstTimestamp":     now,
+		"lastTimestamp":      now,
+		"source":             map[string]interface{}{"component": component, "host": r.podName},
+		"reportingComponent": component,
+		"reportingInstance":  r.podName,
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(c, http.MethodPost,
+		fmt.Sprintf("%s/api/v1/namespaces/%s/events", r.server, r.namespace), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(r.token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+r.token)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to post event, status:%d, %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	return nil
+}
+
+// recordBestEffort post an Event and log warning if failed
+func (r *kubeEventRecorder) recordBestEffort(reason, message string) {
+	if r == nil {
+		return
+	}
+
+	c, cancel := context.WithTimeout(context.Background(), kubeEventTimeout)
+	defer cancel()
+
+	if err := r.record(c, reason, message); err != nil {
+		GlobalAppCtx.GetLoggerEntryDefault().Warn("Failed to post Kubernetes event",
+			zap.String("reason", reason), zap.Error(err))
+	}
+}
+
+// kubeEventEntryCounts returns message with counts of entries in GlobalAppCtx grouped by type
+func kubeEventEntryCounts(action string) string {
+	entries := GlobalAppCtx.ListEntries()
+
+	types := make([]string, 0, len(entries))
+	total := 0
+	for entryType, m := range entries {
+		if len(m) < 1 {
+			continue
+		}
+		types = append(types, fmt.Sprintf("%s:%d", entryType, len(m)))
+		total += len(m)
+	}
+	sort.Strings(types)
+
+	return fmt.Sprintf("%s %d entries, [%s]", action, total, strings.Join(types, ", "))
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func writeKubeConfig(t *testing.T, server string) {
+	path := filepath.Join(t.TempDir(), "config")
+	content := fmt.Sprintf(`
+apiVersion: v1
+kind: Config
+current-context: ut-context
+clusters:
+  - name: ut-cluster
+    cluster:
+      server: %s
+      insecure-skip-tls-verify: true
+contexts:
+  - name: ut-context
+    context:
+      cluster: ut-cluster
+      user: ut-user
+      namespace: ut-ns
+users:
+  - name: ut-user
+    user:
+      token: ut-token
+`, server)
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0644))
+
+	t.Setenv("KUBECONFIG", path)
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	t.Setenv("POD_NAME", "ut-pod")
+	t.Setenv("POD_NAMESPACE", "")
+}
+
+func TestRun_WithKubeEvents(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	GlobalAppCtx.clearEntries()
+
+	lock := sync.Mutex{}
+	events := make([]map[string]interface{}, 0)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/namespaces/ut-ns/events", r.URL.Path)
+		assert.Equal(t, "Bearer ut-token", r.Header.Get("Authorization"))
+
+		event := make(map[string]interface{})
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&event))
+		lock.Lock()
+		events = append(events, event)
+		lock.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	writeKubeConfig(t, server.URL)
+
+	trace := make([]string, 0)
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "ut-kube-events"}, trace: &trace})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Nil(t, Run(ctx, WithKubeEvents()))
+
+	lock.Lock()
+	defer lock.Unlock()
+	assert.Len(t, events, 2)
+	assert.Equal(t, KubeEventReasonBootstrapCompleted, events[0]["reason"])
+	assert.Contains(t, events[0]["message"], "Bootstrapped")
+	assert.Contains(t, events[0]["message"], "mock:1")
+	assert.Equal(t, KubeEventReasonShutdownStarted, events[1]["reason"])
+
+	involved := events[0]["involvedObject"].(map[string]interface{})
+	assert.Equal(t, "Pod", involved["kind"])
+	assert.Equal(t, "ut-pod", involved["name"])
+	assert.Equal(t, "ut-ns", involved["namespace"])
+}
+
+func TestRun_WithKubeEvents_BestEffort(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	GlobalAppCtx.clearEntries()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+	writeKubeConfig(t, server.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// failed to post
+	assert.Nil(t, Run(ctx, WithKubeEvents()))
+
+	// config is missing
+	t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "missing"))
+	_, err := newKubeEventRecorder()
+	assert.NotNil(t, err)
+	assert.Nil(t, Run(ctx, WithKubeEvents()))
+}
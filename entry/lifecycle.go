@@ -0,0 +1,1765 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"context"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// EntryStateRegistered entry was registered and never bootstrapped
+	EntryStateRegistered EntryState = "Registered"
+	// EntryStateBootstrapping entry is bootstrapping
+	EntryStateBootstrapping EntryState = "Bootstrapping"
+	// EntryStateRunning entry was bootstrapped
+	EntryStateRunning EntryState = "Running"
+	// EntryStateInterrupting entry is interrupting
+	EntryStateInterrupting EntryState = "Interrupting"
+	// EntryStateStopped entry was interrupted
+	EntryStateStopped EntryState = "Stopped"
+	// EntryStateFailed entry panicked or timed out while bootstrapping or interrupting, bootstrap gate of entry
+	// didn't pass before timeout or cancellation, or any dependency of entry failed to bootstrap
+	EntryStateFailed EntryState = "Failed"
+)
+
+const (
+	// FailurePolicyFail startup fails if entry failed to bootstrap, default policy of entries
+	FailurePolicyFail FailurePolicy = "fail"
+	// FailurePolicyWarn entry failed to bootstrap is logged as warning, remaining entries keep bootstrapping
+	FailurePolicyWarn FailurePolicy = "warn"
+	// FailurePolicyIgnore entry failed to bootstrap is logged at debug level, remaining entries keep bootstrapping
+	FailurePolicyIgnore FailurePolicy = "ignore"
+)
+
+// DefaultSlowInterruptThreshold is the duration after which Interrupt of an entry is reported as slow
+const DefaultSlowInterruptThreshold = 5 * time.Second
+
+// DefaultGracePeriod is the max duration Run waits for entries to be interrupted
+const DefaultGracePeriod = 30 * time.Second
+
+// DefaultLifecycleEventBufferSize is size of channel returned by SubscribeLifecycle
+const DefaultLifecycleEventBufferSize = 64
+
+// DefaultBootstrapGateTimeout is the max duration BootstrapAll waits for gate of an entry
+const DefaultBootstrapGateTimeout = time.Minute
+
+// maxBootstrapGateBackoff is the max interval between polls of a bootstrap gate
+const maxBootstrapGateBackoff = 5 * time.Second
+
+// RunOption option for Run
+type RunOption func(*runOption)
+
+type runOption struct {
+	gracePeriod         time.Duration
+	drainPeriod         time.Duration
+	valuePath           string
+	logConfig           bool
+	kubeEvents          bool
+	defaultTimeout      *entryTimeout
+	loggerReopen        bool
+	gates               map[string]*bootstrapGate
+	interruptPriorities map[string]int
+	logSummary          *bootstrapLogSummary
+	shutdownCtx         context.Context
+	inFlightWait        bool
+}
+
+// bootstrapLogSummary is thresholds of SetBootstrapLogSummary provided with WithBootstrapLogSummary
+type bootstrapLogSummary struct {
+	everyN   int
+	interval time.Duration
+}
+
+// WithGracePeriod provide max duration to wait for entries to be interrupted, DefaultGracePeriod by default.
+func WithGracePeriod(d time.Duration) RunOption {
+	return func(opt *runOption) {
+		if d > 0 {
+			opt.gracePeriod = d
+		}
+	}
+}
+
+// WithDrainPeriod provide duration to drain traffic after shutdown signal received, refer to BeginDrain.
+func WithDrainPeriod(d time.Duration) RunOption {
+	return func(opt *runOption) {
+		if d > 0 {
+			opt.drainPeriod = d
+		}
+	}
+}
+
+// WithDefaultEntryTimeout provide default timeouts of bootstrapping and interrupting each entry,
+// which are used if they are not declared in boot config of entry. Non-positive value means no timeout.
+func WithDefaultEntryTimeout(bootstrap, interrupt time.Duration) RunOption {
+	return func(opt *runOption) {
+		opt.defaultTimeout = &entryTimeout{bootstrap: bootstrap, interrupt: interrupt}
+	}
+}
+
+// WithLoggerReopenOnSignal reopen files of all LoggerEntry while SIGUSR1 is received, refer to SetLoggerReopenOnSignal.
+func WithLoggerReopenOnSignal() RunOption {
+	return func(opt *runOption) {
+		opt.loggerReopen = true
+	}
+}
+
+// WithBootstrapGate provide gate of entries with name before bootstrap, refer to AddBootstrapGate.
+func WithBootstrapGate(entryName string, gate BootstrapGate, timeout time.Duration) RunOption {
+	return func(opt *runOption) {
+		if len(entryName) < 1 || gate == nil {
+			return
+		}
+		if opt.gates == nil {
+			opt.gates = make(map[string]*bootstrapGate)
+		}
+		opt.gates[entryName] = newBootstrapGate(gate, timeout)
+	}
+}
+
+// WithInterruptPriority provide interrupt priority of entries with name, refer to SetInterruptPriority.
+func WithInterruptPriority(entryName string, priority int) RunOption {
+	return func(opt *runOption) {
+		if len(entryName) < 1 {
+			return
+		}
+		if opt.interruptPriorities == nil {
+			opt.interruptPriorities = make(map[string]int)
+		}
+		opt.interruptPriorities[entryName] = priority
+	}
+}
+
+// WithBootstrapLogSummary summarize logs of bootstrap with progress every n entries or every interval,
+// refer to SetBootstrapLogSummary.
+func WithBootstrapLogSummary(everyN int, interval time.Duration) RunOption {
+	return func(opt *runOption) {
+		opt.logSummary = &bootstrapLogSummary{everyN: everyN, interval: interval}
+	}
+}
+
+// WithShutdownContext provide root context to interrupt entries, refer to SetShutdownContext.
+func WithShutdownContext(c context.Context) RunOption {
+	return func(opt *runOption) {
+		opt.shutdownCtx = c
+	}
+}
+
+// WithInFlightWait wait for in-flight operations before interrupting entries, refer to SetWaitInFlightOnInterrupt.
+func WithInFlightWait() RunOption {
+	return func(opt *runOption) {
+		opt.inFlightWait = true
+	}
+}
+
+// WithEffectiveConfigLog log redacted config of all entries after bootstrap, refer to LogEffectiveConfig.
+func WithEffectiveConfigLog() RunOption {
+	return func(opt *runOption) {
+		opt.logConfig = true
+	}
+}
+
+// WithKubeEvents post Kubernetes Events of BootstrapCompleted and ShutdownStarted on current pod with counts of entries.
+//
+// In-cluster config is used if running in pod, otherwise, kubeconfig declared by KUBECONFIG is used.
+// Pod is identified by POD_NAME (hostname by default) and POD_NAMESPACE, which could be injected with downward API.
+// Events are posted in best effort, failures will be logged with default LoggerEntry.
+func WithKubeEvents() RunOption {
+	return func(opt *runOption) {
+		opt.kubeEvents = true
+	}
+}
+
+// WithUserValuePersistence provide file path to load values from before bootstrap and save values to after shutdown,
+// refer to LoadValues and SaveValues.
+func WithUserValuePersistence(path string) RunOption {
+	return func(opt *runOption) {
+		opt.valuePath = path
+	}
+}
+
+// EntryState is lifecycle state of entry managed by GlobalAppCtx.
+type EntryState string
+
+// entryStateValues are numeric values of states exported as rk_entry_state
+var entryStateValues = map[EntryState]float64{
+	EntryStateRegistered:    0,
+	EntryStateBootstrapping: 1,
+	EntryStateRunning:       2,
+	EntryStateInterrupting:  3,
+	EntryStateStopped:       4,
+	EntryStateFailed:        5,
+}
+
+// Value returns numeric value of state exported as rk_entry_state, -1 if state is unknown.
+//
+// Registered:0, Bootstrapping:1, Running:2, Interrupting:3, Stopped:4, Failed:5
+func (s EntryState) Value() float64 {
+	if v, ok := entryStateValues[s]; ok {
+		return v
+	}
+
+	return -1
+}
+
+// entryStateGauge is current state of entries with labels of name and type, registered into PromEntry
+var entryStateGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "rk_entry_state",
+	Help: "Lifecycle state of entry, Registered:0, Bootstrapping:1, Running:2, Interrupting:3, Stopped:4, Failed:5",
+}, []string{"name", "type"})
+
+// lifecycleState keeps states and lifecycle settings of entries in appContext, all fields are guarded by lock.
+//
+// states, partial, timeouts and policies are keyed by type/name and cleared while entry is removed,
+// gates and priorities are keyed by entry name. partial keeps entries failed in the middle of Bootstrap
+// which need to be interrupted to release resources, inFlightDone is closed while inFlight drops to zero.
+type lifecycleState struct {
+	lock             sync.Mutex
+	states           map[string]EntryState
+	partial          map[string]bool
+	timeouts         map[string]*entryTimeout
+	policies         map[string]FailurePolicy
+	gates            map[string]*bootstrapGate
+	priorities       map[string]int
+	defaultTimeout   entryTimeout
+	requiredEnvs     []string
+	summaryEveryN    int
+	summaryInterval  time.Duration
+	rollbackOnFail   bool
+	draining         bool
+	waitInFlight     bool
+	inFlight         int64
+	inFlightDone     chan struct{}
+	slowThreshold    time.Duration
+	interruptRecords []InterruptRecord
+	subscribers      []*lifecycleSubscriber
+}
+
+// newLifecycleState creates lifecycleState with default settings
+func newLifecycleState() *lifecycleState {
+	return &lifecycleState{
+		states:           make(map[string]EntryState),
+		partial:          make(map[string]bool),
+		timeouts:         make(map[string]*entryTimeout),
+		policies:         make(map[string]FailurePolicy),
+		gates:            make(map[string]*bootstrapGate),
+		priorities:       make(map[string]int),
+		requiredEnvs:     make([]string, 0),
+		slowThreshold:    DefaultSlowInterruptThreshold,
+		interruptRecords: make([]InterruptRecord, 0),
+		subscribers:      make([]*lifecycleSubscriber, 0),
+	}
+}
+
+// setState set state of entry, update rk_entry_state and publish LifecycleEvent, lock should be held by caller
+func (s *lifecycleState) setState(entryType, entryName string, state EntryState) {
+	key := entryType + "/" + entryName
+	from, ok := s.states[key]
+	if !ok {
+		from = EntryStateRegistered
+	}
+
+	s.states[key] = state
+	delete(s.partial, key)
+	entryStateGauge.WithLabelValues(entryName, entryType).Set(state.Value())
+
+	s.publish(LifecycleEvent{
+		EntryName: entryName,
+		EntryType: entryType,
+		FromState: from,
+		ToState:   state,
+		Time:      time.Now(),
+	})
+}
+
+// getState returns state of entry, EntryStateRegistered if missing, lock should be held by caller
+func (s *lifecycleState) getState(entryType, entryName string) EntryState {
+	if v, ok := s.states[entryType+"/"+entryName]; ok {
+		return v
+	}
+
+	return EntryStateRegistered
+}
+
+// interruptible returns true if entry is running or failed in the middle of Bootstrap, lock should be held by caller
+func (s *lifecycleState) interruptible(entryType, entryName string) bool {
+	key := entryType + "/" + entryName
+	switch s.states[key] {
+	case EntryStateRunning:
+		return true
+	case EntryStateFailed:
+		return s.partial[key]
+	}
+
+	return false
+}
+
+// removeEntry clears states and settings of entry with type and name, lock should be held by caller
+func (s *lifecycleState) removeEntry(entryType, entryName string) {
+	key := entryType + "/" + entryName
+	delete(s.states, key)
+	delete(s.partial, key)
+	delete(s.timeouts, key)
+	delete(s.policies, key)
+	entryStateGauge.DeleteLabelValues(entryName, entryType)
+}
+
+// LifecycleEvent is transition of lifecycle state of entry, refer to SubscribeLifecycle.
+type LifecycleEvent struct {
+	EntryName string     `json:"entryName" yaml:"entryName"`
+	EntryType string     `json:"entryType" yaml:"entryType"`
+	FromState EntryState `json:"fromState" yaml:"fromState"`
+	ToState   EntryState `json:"toState" yaml:"toState"`
+	Time      time.Time  `json:"time" yaml:"time"`
+}
+
+// lifecycleSubscriber is channel of subscriber with count of events dropped while channel is full
+type lifecycleSubscriber struct {
+	ch      chan LifecycleEvent
+	dropped int64
+}
+
+// publish sends event to every subscriber without blocking, event is dropped for subscribers with full channel,
+// lock should be held by caller
+func (s *lifecycleState) publish(event LifecycleEvent) {
+	for _, sub := range s.subscribers {
+		select {
+		case sub.ch <- event:
+		default:
+			sub.dropped++
+		}
+	}
+}
+
+// findSubscriber returns subscriber of channel, lock should be held by caller
+func (s *lifecycleState) findSubscriber(ch <-chan LifecycleEvent) (int, *lifecycleSubscriber) {
+	for i, sub := range s.subscribers {
+		if (<-chan LifecycleEvent)(sub.ch) == ch {
+			return i, sub
+		}
+	}
+
+	return -1, nil
+}
+
+// InterruptRecord is result of interrupting an entry in InterruptAll.
+type InterruptRecord struct {
+	EntryName string        `json:"entryName" yaml:"entryName"`
+	EntryType string        `json:"entryType" yaml:"entryType"`
+	Elapsed   time.Duration `json:"elapsed" yaml:"elapsed"`
+	Slow      bool          `json:"slow" yaml:"slow"`
+	Err       string        `json:"err,omitempty" yaml:"err,omitempty"`
+}
+
+// BootstrapGate returns nil if entry is allowed to bootstrap.
+type BootstrapGate func(ctx context.Context) error
+
+// bootstrapGate wraps BootstrapGate with timeout
+type bootstrapGate struct {
+	gate    BootstrapGate
+	timeout time.Duration
+}
+
+// BootTimeout is bootstrap config of timeouts of an entry, embedded in boot config of entries.
+//
+// Values are durations like 500ms or 10s, defaults set by SetDefaultEntryTimeout are used if missing.
+//
+//	logger:
+//	  - name: my-logger
+//	    bootstrapTimeout: 10s
+//	    interruptTimeout: 5s
+type BootTimeout struct {
+	BootstrapTimeout string `yaml:"bootstrapTimeout" json:"bootstrapTimeout"`
+	InterruptTimeout string `yaml:"interruptTimeout" json:"interruptTimeout"`
+}
+
+// entryTimeout is parsed BootTimeout, zero means not declared
+type entryTimeout struct {
+	bootstrap time.Duration
+	interrupt time.Duration
+}
+
+// bootstrapProgress logs progress of BootstrapAll with thresholds of bootstrapLogSummary
+type bootstrapProgress struct {
+	everyN   int
+	interval time.Duration
+	total    int
+	done     int
+	start    time.Time
+	last     time.Time
+}
+
+// FailurePolicy decides whether startup fails if entry failed to bootstrap
+type FailurePolicy string
+
+// BootFailurePolicy is bootstrap config of failure policy of an entry, embedded in boot config of entries.
+//
+// Value is one of fail, warn and ignore, fail is used if missing.
+//
+//	logger:
+//	  - name: my-logger
+//	    failurePolicy: warn
+type BootFailurePolicy struct {
+	FailurePolicy string `yaml:"failurePolicy" json:"failurePolicy"`
+}
+
+// builtin entries would be bootstrapped before entries with default priority
+var builtinEntryPriority = map[string]int{
+	appInfoEntryType:   -500,
+	ConfigEntryType:    -400,
+	LoggerEntryType:    -300,
+	EventEntryType:     -300,
+	CertEntryType:      -200,
+	CryptoEntryType:    -100,
+	SignerJwtEntryType: -100,
+}
+
+// EntryDependent is an optional interface of Entry.
+// Entries returned by GetDependencies will be bootstrapped before this entry.
+type EntryDependent interface {
+	// GetDependencies returns names of entries this entry depends on
+	GetDependencies() []string
+}
+
+// EntryPrioritized is an optional interface of Entry.
+// Entry with lower priority will be bootstrapped earlier if there is no dependency between them.
+// Default priority is 0, builtin entries like logger and config have negative priority.
+type EntryPrioritized interface {
+	// GetPriority returns priority of entry
+	GetPriority() int
+}
+
+// EntryInterruptPrioritized is an optional interface of Entry.
+// Entry with higher interrupt priority will be interrupted earlier, regardless of bootstrap order.
+// Default interrupt priority is 0, entries with the same interrupt priority are interrupted in reversed bootstrap order.
+type EntryInterruptPrioritized interface {
+	// GetInterruptPriority returns interrupt priority of entry
+	GetInterruptPriority() int
+}
+
+const (
+	// PlanReasonDependency entry is ordered after its declared dependencies
+	PlanReasonDependency = "dependency"
+	// PlanReasonPriority entry is ordered by non-default priority
+	PlanReasonPriority = "priority"
+	// PlanReasonStable entry is ordered by type and name
+	PlanReasonStable = "stable"
+)
+
+// PlanStep is one step of bootstrap plan.
+type PlanStep struct {
+	Order             int      `json:"order" yaml:"order"`
+	EntryName         string   `json:"entryName" yaml:"entryName"`
+	EntryType         string   `json:"entryType" yaml:"entryType"`
+	Priority          int      `json:"priority" yaml:"priority"`
+	InterruptPriority int      `json:"interruptPriority" yaml:"interruptPriority"`
+	Dependencies      []string `json:"dependencies" yaml:"dependencies"`
+	Reason            string   `json:"reason" yaml:"reason"`
+
+	entry Entry
+}
+
+// String returns step as type/name
+func (s PlanStep) String() string {
+	return s.EntryType + "/" + s.EntryName
+}
+
+// BootstrapPlan returns ordered steps BootstrapAll would execute without running any of them.
+//
+// Order respects dependencies declared by EntryDependent first, then priority declared by EntryPrioritized,
+// entries with the same priority are ordered by type and name.
+// An error will be returned if a dependency is missing or there is a cycle.
+func (ctx *appContext) BootstrapPlan() ([]PlanStep, error) {
+	// 1: collect steps
+	steps := make([]*PlanStep, 0)
+	byName := make(map[string][]*PlanStep)
+	for _, entries := range ctx.ListEntries() {
+		for _, entry := range entries {
+			step := &PlanStep{
+				EntryName:         entry.GetName(),
+				EntryType:         entry.GetType(),
+				Priority:          getEntryPriority(entry),
+				InterruptPriority: ctx.getEntryInterruptPriority(entry),
+				Dependencies:      make([]string, 0),
+				entry:             entry,
+			}
+
+			if v, ok := entry.(EntryDependent); ok {
+				step.Dependencies = append(step.Dependencies, v.GetDependencies()...)
+			}
+
+			steps = append(steps, step)
+			byName[step.EntryName] = append(byName[step.EntryName], step)
+		}
+	}
+
+	// 2: resolve dependencies
+	inDegree := make(map[*PlanStep]int)
+	dependents := make(map[*PlanStep][]*PlanStep)
+	for _, step := range steps {
+		for _, dep := range step.Dependencies {
+			targets, ok := byName[dep]
+			if !ok {
+				return nil, fmt.Errorf("dependency of entry is missing, entry:%s, dependency:%s", step, dep)
+			}
+
+			for _, target := range targets {
+				if target == step {
+					continue
+				}
+				inDegree[step]++
+				dependents[target] = append(dependents[target], step)
+			}
+		}
+	}
+
+	// 3: topological sort, pick up ready step with lowest priority each time
+	ready := make([]*PlanStep, 0)
+	for _, step := range steps {
+		if inDegree[step] == 0 {
+			ready = append(ready, step)
+		}
+	}
+
+	res := make([]PlanStep, 0, len(steps))
+	for len(ready) > 0 {
+		sort.SliceStable(ready, func(i, j int) bool {
+			return lessPlanStep(ready[i], ready[j])
+		})
+
+		step := ready[0]
+		ready = ready[1:]
+
+		step.Order = len(res)
+		step.Reason = getPlanReason(step)
+		res = append(res, *step)
+
+		for _, dependent := range dependents[step] {
+			inDegree[dependent]--
+			if inDegree[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	if len(res) != len(steps) {
+		cycle := make([]string, 0)
+		for _, step := range steps {
+			if inDegree[step] > 0 {
+				cycle = append(cycle, step.String())
+			}
+		}
+		sort.Strings(cycle)
+		return nil, fmt.Errorf("dependency cycle detected between entries:[%s]", strings.Join(cycle, ","))
+	}
+
+	return res, nil
+}
+
+// InterruptPlan returns ordered steps InterruptAll would execute without running any of them.
+//
+// Entries with higher interrupt priority declared by EntryInterruptPrioritized or SetInterruptPriority
+// are interrupted earlier, entries with the same interrupt priority are interrupted in reversed order of BootstrapPlan.
+// Dependencies are not taken into account for entries with different interrupt priorities.
+func (ctx *appContext) InterruptPlan() ([]PlanStep, error) {
+	plan, err := ctx.BootstrapPlan()
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]PlanStep, 0, len(plan))
+	for i := len(plan) - 1; i >= 0; i-- {
+		res = append(res, plan[i])
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].InterruptPriority > res[j].InterruptPriority
+	})
+
+	for i := range res {
+		res[i].Order = i
+	}
+
+	return res, nil
+}
+
+// SetInterruptPriority set interrupt priority of entries with name, which overrides EntryInterruptPrioritized.
+// Entry with higher interrupt priority will be interrupted earlier, refer to InterruptPlan.
+func (ctx *appContext) SetInterruptPriority(entryName string, priority int) {
+	if len(entryName) < 1 {
+		return
+	}
+
+	ctx.lifecycle.lock.Lock()
+	defer ctx.lifecycle.lock.Unlock()
+
+	ctx.lifecycle.priorities[entryName] = priority
+}
+
+// RemoveInterruptPriority remove interrupt priority of entries with name set by SetInterruptPriority.
+func (ctx *appContext) RemoveInterruptPriority(entryName string) bool {
+	ctx.lifecycle.lock.Lock()
+	defer ctx.lifecycle.lock.Unlock()
+
+	if _, ok := ctx.lifecycle.priorities[entryName]; !ok {
+		return false
+	}
+
+	delete(ctx.lifecycle.priorities, entryName)
+	return true
+}
+
+// BootstrapAll bootstrap all entries in GlobalAppCtx with order of BootstrapPlan.
+// Entries which are already running will be skipped.
+// Resolved plan is logged as a single event with default EventEntry before bootstrapping.
+//
+// Nothing will be bootstrapped if any environment variable declared by RequireEnv is missing.
+//
+// Panic from Entry.Bootstrap will be recovered and returned as error, remaining entries won't be bootstrapped,
+// unless failure policy of entry is warn or ignore, refer to SetEntryFailurePolicy.
+// Entries depending on an entry which failed are marked as failed without bootstrapping and follow their own policy.
+// Entries bootstrapped by this call will be interrupted in reversed order if SetRollbackOnBootstrapFailure is enabled,
+// otherwise, they are left running. Failure is returned as MultiError with errors of bootstrap and rollback.
+func (ctx *appContext) BootstrapAll(c context.Context) error {
+	if err := CheckRequiredEnv(); err != nil {
+		return err
+	}
+
+	plan, err := ctx.BootstrapPlan()
+	if err != nil {
+		return err
+	}
+
+	recordBootstrapPlan(plan)
+
+	progress := ctx.newBootstrapProgress(len(plan))
+
+	ctx.lifecycle.lock.Lock()
+	rollbackOnFail := ctx.lifecycle.rollbackOnFail
+	ctx.lifecycle.lock.Unlock()
+
+	bootstrapped := make([]PlanStep, 0, len(plan))
+	failed := make(map[string]bool)
+	for i := range plan {
+		// entries already running are not owned by this call
+		running := ctx.GetEntryState(plan[i].EntryType, plan[i].EntryName) == EntryStateRunning
+
+		var err error
+		if !running {
+			if err = ctx.checkFailedDependency(plan[i], failed); err == nil {
+				err = ctx.waitBootstrapGate(c, plan[i])
+			}
+		}
+		if err == nil {
+			start := time.Now()
+			err = ctx.transitEntry(plan[i].entry, c, true)
+			progress.step(plan[i], time.Since(start), err)
+		}
+
+		if err != nil && ctx.tolerateBootstrapFailure(plan[i], err) {
+			failed[plan[i].EntryName] = true
+			continue
+		}
+
+		if err != nil {
+			var rollbackErr error
+			if rollbackOnFail {
+				rollbackErr = ctx.rollbackBootstrap(c, bootstrapped)
+			}
+			return NewMultiError(fmt.Sprintf("failed to bootstrap entry:%s", plan[i]), err, rollbackErr)
+		}
+
+		if !running {
+			bootstrapped = append(bootstrapped, plan[i])
+		}
+	}
+
+	progress.finish()
+
+	return nil
+}
+
+// SetBootstrapLogSummary summarize logs of BootstrapAll, progress is logged every n entries
+// or every interval with default LoggerEntry, and a bootstrapCompleted event is logged with default EventEntry
+// after all entries bootstrapped. Summary is disabled if both of n and interval are not positive.
+//
+// Log of each entry is always available at debug level. Entries which log their own events in Bootstrap
+// could check IsBootstrapLogSummarized and lower them to debug level.
+func (ctx *appContext) SetBootstrapLogSummary(everyN int, interval time.Duration) {
+	ctx.lifecycle.lock.Lock()
+	defer ctx.lifecycle.lock.Unlock()
+
+	ctx.lifecycle.summaryEveryN = everyN
+	ctx.lifecycle.summaryInterval = interval
+}
+
+// IsBootstrapLogSummarized returns true if logs of BootstrapAll are summarized, refer to SetBootstrapLogSummary.
+func (ctx *appContext) IsBootstrapLogSummarized() bool {
+	ctx.lifecycle.lock.Lock()
+	defer ctx.lifecycle.lock.Unlock()
+
+	return ctx.lifecycle.summaryEveryN > 0 || ctx.lifecycle.summaryInterval > 0
+}
+
+// newBootstrapProgress creates bootstrapProgress with thresholds set by SetBootstrapLogSummary
+func (ctx *appContext) newBootstrapProgress(total int) *bootstrapProgress {
+	ctx.lifecycle.lock.Lock()
+	defer ctx.lifecycle.lock.Unlock()
+
+	now := time.Now()
+	return &bootstrapProgress{
+		everyN:   ctx.lifecycle.summaryEveryN,
+		interval: ctx.lifecycle.summaryInterval,
+		total:    total,
+		start:    now,
+		last:     now,
+	}
+}
+
+// enabled returns true if progress summaries are logged
+func (p *bootstrapProgress) enabled() bool {
+	return p.everyN > 0 || p.interval > 0
+}
+
+// step logs entry at debug level, and progress if any threshold is reached
+func (p *bootstrapProgress) step(step PlanStep, elapsed time.Duration, err error) {
+	logger := GlobalAppCtx.GetLoggerEntryDefault()
+	logger.Debug("Bootstrapped entry",
+		zap.String("entryName", step.EntryName),
+		zap.String("entryType", step.EntryType),
+		zap.Duration("elapsed", elapsed),
+		zap.Error(err))
+
+	if err != nil || !p.enabled() {
+		return
+	}
+
+	p.done++
+	now := time.Now()
+	if (p.everyN > 0 && p.done%p.everyN == 0) || (p.interval > 0 && now.Sub(p.last) >= p.interval) {
+		p.last = now
+		logger.Info("Bootstrap in progress",
+			zap.Int("bootstrapped", p.done),
+			zap.Int("total", p.total),
+			zap.Duration("elapsed", now.Sub(p.start)))
+	}
+}
+
+// finish logs bootstrapCompleted event if progress summaries are logged
+func (p *bootstrapProgress) finish() {
+	if !p.enabled() {
+		return
+	}
+
+	eventEntry := GlobalAppCtx.GetEventEntryDefault()
+	event := eventEntry.Start("bootstrapCompleted")
+	event.AddPair("entries", strconv.Itoa(p.total))
+	event.AddPair("elapsed", time.Since(p.start).String())
+	eventEntry.Finish(event)
+}
+
+// AddBootstrapGate add gate of entries with name, BootstrapAll polls gate with backoff before bootstrapping them
+// until it returns nil. Entries will be failed if gate didn't pass within timeout, DefaultBootstrapGateTimeout
+// will be used if timeout is not positive. Gate with the same entry name will be replaced.
+func (ctx *appContext) AddBootstrapGate(entryName string, gate BootstrapGate, timeout time.Duration) {
+	if len(entryName) < 1 || gate == nil {
+		return
+	}
+
+	ctx.lifecycle.lock.Lock()
+	defer ctx.lifecycle.lock.Unlock()
+
+	ctx.lifecycle.gates[entryName] = newBootstrapGate(gate, timeout)
+}
+
+// newBootstrapGate creates bootstrapGate, DefaultBootstrapGateTimeout will be used if timeout is not positive
+func newBootstrapGate(gate BootstrapGate, timeout time.Duration) *bootstrapGate {
+	if timeout <= 0 {
+		timeout = DefaultBootstrapGateTimeout
+	}
+
+	return &bootstrapGate{
+		gate:    gate,
+		timeout: timeout,
+	}
+}
+
+// RemoveBootstrapGate remove gate of entries with name.
+func (ctx *appContext) RemoveBootstrapGate(entryName string) bool {
+	ctx.lifecycle.lock.Lock()
+	defer ctx.lifecycle.lock.Unlock()
+
+	if _, ok := ctx.lifecycle.gates[entryName]; !ok {
+		return false
+	}
+
+	delete(ctx.lifecycle.gates, entryName)
+	return true
+}
+
+// waitBootstrapGate polls gate of step with backoff, entry is marked as failed if gate didn't pass within timeout,
+// error of c is returned if c is done before timeout.
+func (ctx *appContext) waitBootstrapGate(c context.Context, step PlanStep) error {
+	ctx.lifecycle.lock.Lock()
+	g, ok := ctx.lifecycle.gates[step.EntryName]
+	ctx.lifecycle.lock.Unlock()
+	if !ok {
+		return nil
+	}
+
+	ctx.GetLoggerEntryDefault().Info("Waiting for bootstrap gate",
+		zap.String("entryName", step.EntryName),
+		zap.String("entryType", step.EntryType),
+		zap.Duration("timeout", g.timeout))
+
+	gateCtx, cancel := context.WithTimeout(c, g.timeout)
+	defer cancel()
+
+	backoff := 50 * time.Millisecond
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+
+	for {
+		err := g.gate(gateCtx)
+		if err == nil {
+			return nil
+		}
+
+		// restart timer after gate returned, it may be fired already by the first poll
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(backoff)
+
+		select {
+		case <-gateCtx.Done():
+			ctx.lifecycle.lock.Lock()
+			ctx.lifecycle.setState(step.EntryType, step.EntryName, EntryStateFailed)
+			ctx.lifecycle.lock.Unlock()
+
+			// parent context is done before timeout of gate
+			if c.Err() != nil {
+				return c.Err()
+			}
+			return fmt.Errorf("bootstrap gate didn't pass within %s, %v", g.timeout, err)
+		case <-timer.C:
+		}
+
+		if backoff *= 2; backoff > maxBootstrapGateBackoff {
+			backoff = maxBootstrapGateBackoff
+		}
+	}
+}
+
+// SetDefaultEntryTimeout set timeouts of bootstrapping and interrupting entries which didn't declare them
+// in boot config. Non-positive value means no timeout which is the default.
+func (ctx *appContext) SetDefaultEntryTimeout(bootstrap, interrupt time.Duration) {
+	ctx.lifecycle.lock.Lock()
+	defer ctx.lifecycle.lock.Unlock()
+
+	ctx.lifecycle.defaultTimeout = entryTimeout{
+		bootstrap: bootstrap,
+		interrupt: interrupt,
+	}
+}
+
+// SetEntryTimeout set timeouts of entry with type and name from boot config.
+// Missing values fall back to defaults, an error will be returned if value is not a positive duration.
+func (ctx *appContext) SetEntryTimeout(entryType, entryName string, boot *BootTimeout) error {
+	if boot == nil {
+		boot = &BootTimeout{}
+	}
+
+	res := &entryTimeout{}
+	for _, v := range []struct {
+		key   string
+		value string
+		dest  *time.Duration
+	}{
+		{key: "bootstrapTimeout", value: boot.BootstrapTimeout, dest: &res.bootstrap},
+		{key: "interruptTimeout", value: boot.InterruptTimeout, dest: &res.interrupt},
+	} {
+		if len(v.value) < 1 {
+			continue
+		}
+
+		d, err := time.ParseDuration(v.value)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid %s of entry:%s/%s, value:%s", v.key, entryType, entryName, v.value)
+		}
+		*v.dest = d
+	}
+
+	ctx.lifecycle.lock.Lock()
+	defer ctx.lifecycle.lock.Unlock()
+
+	key := entryType + "/" + entryName
+	if res.bootstrap == 0 && res.interrupt == 0 {
+		delete(ctx.lifecycle.timeouts, key)
+	} else {
+		ctx.lifecycle.timeouts[key] = res
+	}
+
+	return nil
+}
+
+// GetEntryTimeout returns timeouts of bootstrapping and interrupting entry with type and name,
+// defaults are returned if entry didn't declare them, zero means no timeout.
+func (ctx *appContext) GetEntryTimeout(entryType, entryName string) (bootstrap, interrupt time.Duration) {
+	ctx.lifecycle.lock.Lock()
+	defer ctx.lifecycle.lock.Unlock()
+
+	bootstrap, interrupt = ctx.lifecycle.defaultTimeout.bootstrap, ctx.lifecycle.defaultTimeout.interrupt
+	if v, ok := ctx.lifecycle.timeouts[entryType+"/"+entryName]; ok {
+		if v.bootstrap > 0 {
+			bootstrap = v.bootstrap
+		}
+		if v.interrupt > 0 {
+			interrupt = v.interrupt
+		}
+	}
+
+	if bootstrap < 0 {
+		bootstrap = 0
+	}
+	if interrupt < 0 {
+		interrupt = 0
+	}
+
+	return bootstrap, interrupt
+}
+
+// SetEntryFailurePolicy set failure policy of entry with type and name from boot config.
+// Missing value falls back to fail, an error will be returned if value is not one of fail, warn and ignore.
+func (ctx *appContext) SetEntryFailurePolicy(entryType, entryName string, boot *BootFailurePolicy) error {
+	if boot == nil {
+		boot = &BootFailurePolicy{}
+	}
+
+	policy := FailurePolicy(strings.ToLower(boot.FailurePolicy))
+	switch policy {
+	case "", FailurePolicyFail, FailurePolicyWarn, FailurePolicyIgnore:
+	default:
+		return fmt.Errorf("invalid failurePolicy of entry:%s/%s, value:%s", entryType, entryName, boot.FailurePolicy)
+	}
+
+	ctx.lifecycle.lock.Lock()
+	defer ctx.lifecycle.lock.Unlock()
+
+	key := entryType + "/" + entryName
+	if policy == "" || policy == FailurePolicyFail {
+		delete(ctx.lifecycle.policies, key)
+	} else {
+		ctx.lifecycle.policies[key] = policy
+	}
+
+	return nil
+}
+
+// GetEntryFailurePolicy returns failure policy of entry with type and name, FailurePolicyFail if not declared.
+func (ctx *appContext) GetEntryFailurePolicy(entryType, entryName string) FailurePolicy {
+	ctx.lifecycle.lock.Lock()
+	defer ctx.lifecycle.lock.Unlock()
+
+	if v, ok := ctx.lifecycle.policies[entryType+"/"+entryName]; ok {
+		return v
+	}
+
+	return FailurePolicyFail
+}
+
+// checkFailedDependency marks entry as failed and returns error if any dependency of entry is in failed
+func (ctx *appContext) checkFailedDependency(step PlanStep, failed map[string]bool) error {
+	for _, dep := range step.Dependencies {
+		if !failed[dep] {
+			continue
+		}
+
+		ctx.lifecycle.lock.Lock()
+		ctx.lifecycle.setState(step.EntryType, step.EntryName, EntryStateFailed)
+		ctx.lifecycle.lock.Unlock()
+		return fmt.Errorf("dependency of entry failed to bootstrap, entry:%s, dependency:%s", step, dep)
+	}
+
+	return nil
+}
+
+// tolerateBootstrapFailure logs failure of entry and returns true if failure policy of entry is not fail
+func (ctx *appContext) tolerateBootstrapFailure(step PlanStep, err error) bool {
+	fields := []zap.Field{
+		zap.String("entryName", step.EntryName),
+		zap.String("entryType", step.EntryType),
+		zap.Error(err),
+	}
+
+	switch ctx.GetEntryFailurePolicy(step.EntryType, step.EntryName) {
+	case FailurePolicyWarn:
+		ctx.GetLoggerEntryDefault().Warn("Failed to bootstrap non-critical entry, continue", fields...)
+		return true
+	case FailurePolicyIgnore:
+		ctx.GetLoggerEntryDefault().Debug("Failed to bootstrap non-critical entry, continue", fields...)
+		return true
+	}
+
+	return false
+}
+
+// RequireEnv declares environment variables which must be set and non-empty before BootstrapAll,
+// names declared already will be ignored.
+func RequireEnv(vars ...string) {
+	lc := GlobalAppCtx.lifecycle
+	lc.lock.Lock()
+	defer lc.lock.Unlock()
+
+	declared := make(map[string]bool, len(lc.requiredEnvs))
+	for _, name := range lc.requiredEnvs {
+		declared[name] = true
+	}
+
+	for _, name := range vars {
+		if len(name) < 1 || declared[name] {
+			continue
+		}
+		declared[name] = true
+		lc.requiredEnvs = append(lc.requiredEnvs, name)
+	}
+}
+
+// CheckRequiredEnv returns an error naming all environment variables declared by RequireEnv which are missing or empty.
+func CheckRequiredEnv() error {
+	lc := GlobalAppCtx.lifecycle
+	lc.lock.Lock()
+	defer lc.lock.Unlock()
+
+	missing := make([]string, 0)
+	for _, name := range lc.requiredEnvs {
+		if len(os.Getenv(name)) < 1 {
+			missing = append(missing, name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("required environment variables are missing or empty, [%s]", strings.Join(missing, ","))
+	}
+
+	return nil
+}
+
+// TrackInFlight marks start of an in-flight operation like a request, call returned function once it is finished.
+// Calling returned function more than once is a no-op.
+//
+// InterruptAll waits for all in-flight operations before interrupting entries if SetWaitInFlightOnInterrupt is enabled.
+func (ctx *appContext) TrackInFlight() func() {
+	ctx.lifecycle.lock.Lock()
+	ctx.lifecycle.inFlight++
+	ctx.lifecycle.lock.Unlock()
+
+	once := sync.Once{}
+	return func() {
+		once.Do(func() {
+			ctx.lifecycle.lock.Lock()
+			defer ctx.lifecycle.lock.Unlock()
+
+			ctx.lifecycle.inFlight--
+			if ctx.lifecycle.inFlight == 0 && ctx.lifecycle.inFlightDone != nil {
+				close(ctx.lifecycle.inFlightDone)
+				ctx.lifecycle.inFlightDone = nil
+			}
+		})
+	}
+}
+
+// GetInFlightCount returns number of in-flight operations tracked by TrackInFlight.
+func (ctx *appContext) GetInFlightCount() int64 {
+	ctx.lifecycle.lock.Lock()
+	defer ctx.lifecycle.lock.Unlock()
+
+	return ctx.lifecycle.inFlight
+}
+
+// WaitInFlight blocks until all in-flight operations tracked by TrackInFlight are finished,
+// an error with number of remaining operations will be returned if c is done before that.
+func (ctx *appContext) WaitInFlight(c context.Context) error {
+	ctx.lifecycle.lock.Lock()
+	if ctx.lifecycle.inFlight < 1 {
+		ctx.lifecycle.lock.Unlock()
+		return nil
+	}
+	if ctx.lifecycle.inFlightDone == nil {
+		ctx.lifecycle.inFlightDone = make(chan struct{})
+	}
+	done := ctx.lifecycle.inFlightDone
+	ctx.lifecycle.lock.Unlock()
+
+	select {
+	case <-done:
+		return nil
+	case <-c.Done():
+		return fmt.Errorf("in-flight operations didn't finish, remaining:%d, %v", ctx.GetInFlightCount(), c.Err())
+	}
+}
+
+// SetWaitInFlightOnInterrupt set whether InterruptAll waits for in-flight operations tracked by TrackInFlight
+// before interrupting entries, disabled by default. Waiting is bounded by context passed to InterruptAll,
+// which is limited by grace period in Run.
+func (ctx *appContext) SetWaitInFlightOnInterrupt(enabled bool) {
+	ctx.lifecycle.lock.Lock()
+	defer ctx.lifecycle.lock.Unlock()
+
+	ctx.lifecycle.waitInFlight = enabled
+}
+
+// SetRollbackOnBootstrapFailure set whether BootstrapAll interrupts entries it bootstrapped
+// if one of the following entries failed to bootstrap, disabled by default.
+func (ctx *appContext) SetRollbackOnBootstrapFailure(enabled bool) {
+	ctx.lifecycle.lock.Lock()
+	defer ctx.lifecycle.lock.Unlock()
+
+	ctx.lifecycle.rollbackOnFail = enabled
+}
+
+// rollbackBootstrap interrupt bootstrapped entries in reversed order, all entries will be interrupted and errors will be aggregated into MultiError.
+func (ctx *appContext) rollbackBootstrap(c context.Context, bootstrapped []PlanStep) error {
+	logger := ctx.GetLoggerEntryDefault()
+	logger.Warn("Rolling back bootstrapped entries", zap.Int("entries", len(bootstrapped)))
+
+	errs := make([]error, 0)
+	for i := len(bootstrapped) - 1; i >= 0; i-- {
+		step := bootstrapped[i]
+		logger.Info("Rolling back entry", zap.String("entryName", step.EntryName), zap.String("entryType", step.EntryType))
+
+		if err := ctx.transitEntry(step.entry, c, false); err != nil {
+			errs = append(errs, fmt.Errorf("entry:%s, %w", step, err))
+		}
+	}
+
+	return NewMultiError("failed to rollback entries", errs...)
+}
+
+// bootstrapValuesKey is key of values in context passed to Entry.Bootstrap
+type bootstrapValuesKey struct{}
+
+// BootstrapAllWithValues bootstrap all entries like BootstrapAll, values will be injected into
+// context passed to Entry.Bootstrap, use GetBootstrapValue to read them.
+func (ctx *appContext) BootstrapAllWithValues(c context.Context, values map[string]interface{}) error {
+	copied := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		copied[k] = v
+	}
+
+	return ctx.BootstrapAll(context.WithValue(c, bootstrapValuesKey{}, copied))
+}
+
+// GetBootstrapValue returns value injected by BootstrapAllWithValues, nil if missing.
+func GetBootstrapValue(c context.Context, key string) interface{} {
+	if c == nil {
+		return nil
+	}
+
+	if values, ok := c.Value(bootstrapValuesKey{}).(map[string]interface{}); ok {
+		return values[key]
+	}
+
+	return nil
+}
+
+// InterruptAll interrupt all entries in GlobalAppCtx with order of InterruptPlan, which is reversed order
+// of BootstrapPlan unless interrupt priorities are declared.
+//
+// Only entries which are running or failed in the middle of Bootstrap are interrupted, entries never bootstrapped,
+// stopped or failed otherwise are skipped, so InterruptAll could be called again safely.
+// Panic from Entry.Interrupt will be recovered, all entries will be interrupted and errors will be aggregated into MultiError.
+// An event will be logged with default EventEntry if Interrupt of an entry exceeds slow interrupt threshold,
+// refer to GetInterruptReport for elapsed time of each entry. Shutdown time is recorded at the first call.
+// In-flight operations are waited before interrupting if SetWaitInFlightOnInterrupt is enabled.
+func (ctx *appContext) InterruptAll(c context.Context) error {
+	ctx.markShutdownTime()
+
+	plan, err := ctx.InterruptPlan()
+	if err != nil {
+		return err
+	}
+
+	errs := make([]error, 0)
+
+	ctx.lifecycle.lock.Lock()
+	waitInFlight := ctx.lifecycle.waitInFlight
+	ctx.lifecycle.lock.Unlock()
+
+	// entries are interrupted anyway if in-flight operations didn't finish in time
+	if waitInFlight {
+		if err := ctx.WaitInFlight(c); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	ctx.lifecycle.lock.Lock()
+	threshold := ctx.lifecycle.slowThreshold
+	ctx.lifecycle.lock.Unlock()
+
+	records := make([]InterruptRecord, 0, len(plan))
+	for i := range plan {
+		step := plan[i]
+
+		// entries never bootstrapped or already interrupted are skipped
+		ctx.lifecycle.lock.Lock()
+		interruptible := ctx.lifecycle.interruptible(step.EntryType, step.EntryName)
+		ctx.lifecycle.lock.Unlock()
+		if !interruptible {
+			continue
+		}
+
+		record := InterruptRecord{
+			EntryName: step.EntryName,
+			EntryType: step.EntryType,
+		}
+
+		// report while entry is still interrupting, process may be killed before it returns
+		reported := make(chan struct{})
+		timer := time.AfterFunc(threshold, func() {
+			defer close(reported)
+			recordSlowInterrupt(step, threshold)
+		})
+
+		start := time.Now()
+		err := ctx.transitEntry(step.entry, c, false)
+		if !timer.Stop() {
+			// wait for report in progress
+			<-reported
+		}
+
+		record.Elapsed = time.Since(start)
+		record.Slow = record.Elapsed >= threshold
+		if err != nil {
+			record.Err = err.Error()
+			errs = append(errs, fmt.Errorf("entry:%s, %w", step, err))
+		}
+		records = append(records, record)
+	}
+
+	ctx.lifecycle.lock.Lock()
+	ctx.lifecycle.interruptRecords = records
+	ctx.lifecycle.lock.Unlock()
+
+	return NewMultiError("failed to interrupt entries", errs...)
+}
+
+// Run bootstrap all entries in GlobalAppCtx and block until shutdown signal is received or ctx is done,
+// then interrupt all entries and run shutdown hooks within grace period.
+// Traffic will be drained before interrupting if shutdown signal is received and WithDrainPeriod is provided.
+//
+// Entries bootstrapped will be interrupted if bootstrap failed. Errors of bootstrap and interrupt are aggregated into MultiError,
+// an error will also be returned if interrupt didn't finish within grace period.
+//
+// Settings of GlobalAppCtx provided with options like WithDefaultEntryTimeout and WithBootstrapGate are applied
+// when Run starts, previous settings are restored after it returns.
+func Run(ctx context.Context, opts ...RunOption) error {
+	opt := &runOption{
+		gracePeriod: DefaultGracePeriod,
+	}
+	for i := range opts {
+		opts[i](opt)
+	}
+
+	// settings provided with options are applied to GlobalAppCtx while running only
+	defer GlobalAppCtx.applyRunOption(opt)()
+
+	var recorder *kubeEventRecorder
+	if opt.kubeEvents {
+		var err error
+		if recorder, err = newKubeEventRecorder(); err != nil {
+			GlobalAppCtx.GetLoggerEntryDefault().Warn("Kubernetes events are disabled", zap.Error(err))
+		}
+	}
+
+	errs := make([]error, 0)
+	if len(opt.valuePath) > 0 {
+		if err := GlobalAppCtx.LoadValues(opt.valuePath); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := GlobalAppCtx.BootstrapAll(ctx); err != nil {
+		errs = append(errs, err)
+	} else {
+		recorder.recordBestEffort(KubeEventReasonBootstrapCompleted, kubeEventEntryCounts("Bootstrapped"))
+
+		if opt.logConfig {
+			if err := GlobalAppCtx.LogEffectiveConfig(); err != nil {
+				GlobalAppCtx.GetLoggerEntryDefault().Warn("Failed to log effective config", zap.Error(err))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			recorder.recordBestEffort(KubeEventReasonShutdownStarted, kubeEventEntryCounts("Interrupting"))
+		case <-GlobalAppCtx.GetShutdownSig():
+			recorder.recordBestEffort(KubeEventReasonShutdownStarted, kubeEventEntryCounts("Interrupting"))
+			GlobalAppCtx.drain(opt.drainPeriod)
+		}
+	}
+
+	// context passed to Interrupt should not be cancelled, ctx is cancelled already in most cases
+	interruptCtx, cancel := context.WithTimeout(GlobalAppCtx.GetShutdownContext(), opt.gracePeriod)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		err := GlobalAppCtx.InterruptAll(interruptCtx)
+		for _, hook := range GlobalAppCtx.ListShutdownHooks() {
+			hook()
+		}
+
+		if len(opt.valuePath) > 0 {
+			if saveErr := GlobalAppCtx.SaveValues(opt.valuePath); saveErr != nil {
+				GlobalAppCtx.GetLoggerEntryDefault().Warn("Failed to save values", zap.Error(saveErr))
+				if err == nil {
+					err = saveErr
+				}
+			}
+		}
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			errs = append(errs, err)
+		}
+	case <-interruptCtx.Done():
+		errs = append(errs, fmt.Errorf("failed to interrupt entries within grace period:%s", opt.gracePeriod))
+	}
+
+	return NewMultiError("run failed", errs...)
+}
+
+// applyRunOption applies settings of GlobalAppCtx provided with options, returns function which restores previous ones
+func (ctx *appContext) applyRunOption(opt *runOption) func() {
+	ctx.lifecycle.lock.Lock()
+	defer ctx.lifecycle.lock.Unlock()
+
+	defaultTimeout := ctx.lifecycle.defaultTimeout
+	summary := bootstrapLogSummary{everyN: ctx.lifecycle.summaryEveryN, interval: ctx.lifecycle.summaryInterval}
+	waitInFlight := ctx.lifecycle.waitInFlight
+	gates := make(map[string]*bootstrapGate)
+	priorities := make(map[string]*int)
+
+	if opt.defaultTimeout != nil {
+		ctx.lifecycle.defaultTimeout = *opt.defaultTimeout
+	}
+	if opt.logSummary != nil {
+		ctx.lifecycle.summaryEveryN, ctx.lifecycle.summaryInterval = opt.logSummary.everyN, opt.logSummary.interval
+	}
+	if opt.inFlightWait {
+		ctx.lifecycle.waitInFlight = true
+	}
+	for name, g := range opt.gates {
+		gates[name] = ctx.lifecycle.gates[name]
+		ctx.lifecycle.gates[name] = g
+	}
+	for name, priority := range opt.interruptPriorities {
+		if prev, ok := ctx.lifecycle.priorities[name]; ok {
+			priorities[name] = &prev
+		} else {
+			priorities[name] = nil
+		}
+		ctx.lifecycle.priorities[name] = priority
+	}
+
+	ctx.shutdownLock.Lock()
+	shutdownCtx := ctx.shutdownCtx
+	if opt.shutdownCtx != nil {
+		ctx.shutdownCtx = opt.shutdownCtx
+	}
+	ctx.shutdownLock.Unlock()
+
+	ctx.loggerReopenLock.Lock()
+	loggerReopen := ctx.loggerReopenSig != nil
+	ctx.loggerReopenLock.Unlock()
+	if opt.loggerReopen && !loggerReopen {
+		ctx.SetLoggerReopenOnSignal(true)
+	}
+
+	return func() {
+		if opt.loggerReopen && !loggerReopen {
+			ctx.SetLoggerReopenOnSignal(false)
+		}
+
+		ctx.shutdownLock.Lock()
+		ctx.shutdownCtx = shutdownCtx
+		ctx.shutdownLock.Unlock()
+
+		ctx.lifecycle.lock.Lock()
+		defer ctx.lifecycle.lock.Unlock()
+
+		ctx.lifecycle.defaultTimeout = defaultTimeout
+		ctx.lifecycle.summaryEveryN, ctx.lifecycle.summaryInterval = summary.everyN, summary.interval
+		ctx.lifecycle.waitInFlight = waitInFlight
+		for name, g := range gates {
+			if g == nil {
+				delete(ctx.lifecycle.gates, name)
+			} else {
+				ctx.lifecycle.gates[name] = g
+			}
+		}
+		for name, priority := range priorities {
+			if priority == nil {
+				delete(ctx.lifecycle.priorities, name)
+			} else {
+				ctx.lifecycle.priorities[name] = *priority
+			}
+		}
+	}
+}
+
+// BeginDrain mark application as draining, wait for d and interrupt all entries.
+//
+// Readiness handler of CommonServiceEntry returns 503 while draining, so that load balancers stop sending traffic.
+func (ctx *appContext) BeginDrain(d time.Duration) error {
+	ctx.drain(d)
+	return ctx.InterruptAll(ctx.GetShutdownContext())
+}
+
+// IsDraining returns true if BeginDrain was called.
+func (ctx *appContext) IsDraining() bool {
+	ctx.lifecycle.lock.Lock()
+	defer ctx.lifecycle.lock.Unlock()
+
+	return ctx.lifecycle.draining
+}
+
+// drain mark application as draining and wait for d, waiting is skipped if d is not positive
+func (ctx *appContext) drain(d time.Duration) {
+	ctx.setDraining(true)
+	if d <= 0 {
+		return
+	}
+
+	ctx.GetLoggerEntryDefault().Info("Draining traffic before shutdown", zap.Duration("drainPeriod", d))
+	time.Sleep(d)
+}
+
+// setDraining mark application as draining or not
+func (ctx *appContext) setDraining(draining bool) {
+	ctx.lifecycle.lock.Lock()
+	defer ctx.lifecycle.lock.Unlock()
+
+	ctx.lifecycle.draining = draining
+}
+
+// SetSlowInterruptThreshold set duration after which Interrupt of an entry is reported as slow.
+func (ctx *appContext) SetSlowInterruptThreshold(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+
+	ctx.lifecycle.lock.Lock()
+	defer ctx.lifecycle.lock.Unlock()
+
+	ctx.lifecycle.slowThreshold = d
+}
+
+// GetInterruptReport returns records of last InterruptAll in order of interruption.
+func (ctx *appContext) GetInterruptReport() []InterruptRecord {
+	ctx.lifecycle.lock.Lock()
+	defer ctx.lifecycle.lock.Unlock()
+
+	res := make([]InterruptRecord, len(ctx.lifecycle.interruptRecords))
+	copy(res, ctx.lifecycle.interruptRecords)
+
+	return res
+}
+
+// ShutdownReport is report of application shutdown.
+type ShutdownReport struct {
+	Signal       string            `json:"signal" yaml:"signal"`
+	ShutdownTime time.Time         `json:"shutdownTime" yaml:"shutdownTime"`
+	Interrupts   []InterruptRecord `json:"interrupts" yaml:"interrupts"`
+}
+
+// GetShutdownReport returns signal which triggered shutdown, shutdown time and records of last InterruptAll.
+// Signal is empty if shutdown was not triggered by signal or TriggerShutdown.
+func (ctx *appContext) GetShutdownReport() *ShutdownReport {
+	res := &ShutdownReport{
+		ShutdownTime: ctx.GetShutdownTime(),
+		Interrupts:   ctx.GetInterruptReport(),
+	}
+
+	if sig := ctx.GetShutdownSigReceived(); sig != nil {
+		res.Signal = sig.String()
+	}
+
+	return res
+}
+
+// GetEntryState returns lifecycle state of entry, EntryStateRegistered if it was never bootstrapped
+// with BootstrapAll or RestartEntry.
+func (ctx *appContext) GetEntryState(entryType, entryName string) EntryState {
+	ctx.lifecycle.lock.Lock()
+	defer ctx.lifecycle.lock.Unlock()
+
+	return ctx.lifecycle.getState(entryType, entryName)
+}
+
+// SubscribeLifecycle returns channel which receives LifecycleEvent while state of entries changes
+// with BootstrapAll, InterruptAll and RestartEntry.
+//
+// Every subscriber receives a copy of events. Channel is buffered with DefaultLifecycleEventBufferSize,
+// events are dropped instead of blocking lifecycle if channel is full, refer to GetLifecycleEventsDropped.
+// Call UnsubscribeLifecycle once events are not needed anymore.
+func (ctx *appContext) SubscribeLifecycle() <-chan LifecycleEvent {
+	sub := &lifecycleSubscriber{
+		ch: make(chan LifecycleEvent, DefaultLifecycleEventBufferSize),
+	}
+
+	ctx.lifecycle.lock.Lock()
+	defer ctx.lifecycle.lock.Unlock()
+	ctx.lifecycle.subscribers = append(ctx.lifecycle.subscribers, sub)
+
+	return sub.ch
+}
+
+// UnsubscribeLifecycle stops sending LifecycleEvent to channel returned by SubscribeLifecycle and closes it.
+func (ctx *appContext) UnsubscribeLifecycle(ch <-chan LifecycleEvent) {
+	ctx.lifecycle.lock.Lock()
+	defer ctx.lifecycle.lock.Unlock()
+
+	if i, sub := ctx.lifecycle.findSubscriber(ch); sub != nil {
+		ctx.lifecycle.subscribers = append(ctx.lifecycle.subscribers[:i], ctx.lifecycle.subscribers[i+1:]...)
+		close(sub.ch)
+	}
+}
+
+// GetLifecycleEventsDropped returns count of LifecycleEvent dropped since channel returned by SubscribeLifecycle was full.
+func (ctx *appContext) GetLifecycleEventsDropped(ch <-chan LifecycleEvent) int64 {
+	ctx.lifecycle.lock.Lock()
+	defer ctx.lifecycle.lock.Unlock()
+
+	if _, sub := ctx.lifecycle.findSubscriber(ch); sub != nil {
+		return sub.dropped
+	}
+
+	return 0
+}
+
+// RestartEntry interrupt and bootstrap entry with name again.
+//
+// An error will be returned if entry is missing, name is shared by entries with different types
+// or entry is bootstrapping or interrupting.
+// Entries which bootstrap only once, like LoggerEntry, need to reset themselves in Interrupt to support restart.
+func (ctx *appContext) RestartEntry(c context.Context, name string) error {
+	matched := make([]Entry, 0)
+	for _, entries := range ctx.ListEntries() {
+		if v, ok := entries[name]; ok {
+			matched = append(matched, v)
+		}
+	}
+
+	if len(matched) < 1 {
+		return fmt.Errorf("entry is missing, name:%s", name)
+	}
+
+	if len(matched) > 1 {
+		return fmt.Errorf("entry name is ambiguous, name:%s, count:%d", name, len(matched))
+	}
+
+	entry := matched[0]
+	logger := ctx.GetLoggerEntryDefault()
+	logger.Info("Restarting entry", zap.String("entryName", entry.GetName()), zap.String("entryType", entry.GetType()))
+
+	if err := ctx.transitEntry(entry, c, false); err != nil {
+		logger.Warn("Failed to restart entry", zap.String("entryName", entry.GetName()), zap.Error(err))
+		return fmt.Errorf("failed to interrupt entry:%s/%s, %v", entry.GetType(), entry.GetName(), err)
+	}
+
+	if err := ctx.transitEntry(entry, c, true); err != nil {
+		logger.Warn("Failed to restart entry", zap.String("entryName", entry.GetName()), zap.Error(err))
+		return fmt.Errorf("failed to bootstrap entry:%s/%s, %v", entry.GetType(), entry.GetName(), err)
+	}
+
+	logger.Info("Restarted entry", zap.String("entryName", entry.GetName()), zap.String("entryType", entry.GetType()))
+	return nil
+}
+
+// transitEntry bootstrap or interrupt entry and update its state.
+// Entry in the middle of transition will be rejected, entry will be failed if it didn't finish within timeout.
+// Bootstrapping a running entry is a no-op with warning, use RestartEntry instead.
+// Interrupting an entry which is neither running nor failed in the middle of Bootstrap is a no-op.
+func (ctx *appContext) transitEntry(entry Entry, c context.Context, bootstrap bool) error {
+	key := entry.GetType() + "/" + entry.GetName()
+
+	bootstrapTimeout, interruptTimeout := ctx.GetEntryTimeout(entry.GetType(), entry.GetName())
+
+	action, timeout := "interrupt", interruptTimeout
+	from, to, f := EntryStateInterrupting, EntryStateStopped, entry.Interrupt
+	if bootstrap {
+		action, timeout = "bootstrap", bootstrapTimeout
+		from, to, f = EntryStateBootstrapping, EntryStateRunning, entry.Bootstrap
+	}
+
+	ctx.lifecycle.lock.Lock()
+	if curr := ctx.lifecycle.states[key]; curr == EntryStateBootstrapping || curr == EntryStateInterrupting {
+		ctx.lifecycle.lock.Unlock()
+		return fmt.Errorf("entry is in transition, state:%s", curr)
+	}
+	if curr := ctx.lifecycle.states[key]; bootstrap && curr == EntryStateRunning {
+		ctx.lifecycle.lock.Unlock()
+		ctx.GetLoggerEntryDefault().Warn("Entry is already bootstrapped, skip bootstrap, use RestartEntry instead",
+			zap.String("entryName", entry.GetName()), zap.String("entryType", entry.GetType()))
+		return nil
+	}
+	if !bootstrap && !ctx.lifecycle.interruptible(entry.GetType(), entry.GetName()) {
+		curr := ctx.lifecycle.getState(entry.GetType(), entry.GetName())
+		ctx.lifecycle.lock.Unlock()
+		ctx.GetLoggerEntryDefault().Debug("Entry is not running, skip interrupt",
+			zap.String("entryName", entry.GetName()), zap.String("entryType", entry.GetType()), zap.String("state", string(curr)))
+		return nil
+	}
+	ctx.lifecycle.setState(entry.GetType(), entry.GetName(), from)
+	ctx.lifecycle.lock.Unlock()
+
+	err := runEntryFuncWithTimeout(f, c, action, timeout)
+
+	ctx.lifecycle.lock.Lock()
+	defer ctx.lifecycle.lock.Unlock()
+	if err != nil {
+		ctx.lifecycle.setState(entry.GetType(), entry.GetName(), EntryStateFailed)
+		// Bootstrap may have acquired resources before it failed
+		ctx.lifecycle.partial[key] = bootstrap
+	} else {
+		ctx.lifecycle.setState(entry.GetType(), entry.GetName(), to)
+	}
+
+	return err
+}
+
+// recordBootstrapPlan logs an event which summarizes order of bootstrap
+func recordBootstrapPlan(plan []PlanStep) {
+	eventEntry := GlobalAppCtx.GetEventEntryDefault()
+	event := eventEntry.Start("bootstrapPlan")
+	event.AddPair("steps", strconv.Itoa(len(plan)))
+	event.AddPayloads(zap.Any("plan", plan))
+	eventEntry.Finish(event)
+}
+
+// recordSlowInterrupt logs an event for entry which is still interrupting after threshold
+func recordSlowInterrupt(step PlanStep, elapsed time.Duration) {
+	eventEntry := GlobalAppCtx.GetEventEntryDefault()
+	event := eventEntry.Start("slowInterrupt")
+	event.AddPair("entryName", step.EntryName)
+	event.AddPair("entryType", step.EntryType)
+	event.AddPair("elapsed", elapsed.String())
+	eventEntry.Finish(event)
+
+	GlobalAppCtx.GetLoggerEntryDefault().Warn("Entry is interrupting slowly",
+		zap.String("entryName", step.EntryName),
+		zap.String("entryType", step.EntryType),
+		zap.Duration("elapsed", elapsed))
+}
+
+// getPlanReason returns reason of order of step
+func getPlanReason(step *PlanStep) string {
+	if len(step.Dependencies) > 0 {
+		return PlanReasonDependency
+	}
+
+	if step.Priority != 0 {
+		return PlanReasonPriority
+	}
+
+	return PlanReasonStable
+}
+
+// getEntryPriority returns priority of entry
+func getEntryPriority(entry Entry) int {
+	if v, ok := entry.(EntryPrioritized); ok {
+		return v.GetPriority()
+	}
+
+	return builtinEntryPriority[entry.GetType()]
+}
+
+// getEntryInterruptPriority returns interrupt priority of entry
+func (ctx *appContext) getEntryInterruptPriority(entry Entry) int {
+	ctx.lifecycle.lock.Lock()
+	priority, ok := ctx.lifecycle.priorities[entry.GetName()]
+	ctx.lifecycle.lock.Unlock()
+	if ok {
+		return priority
+	}
+
+	if v, ok := entry.(EntryInterruptPrioritized); ok {
+		return v.GetInterruptPriority()
+	}
+
+	return 0
+}
+
+// lessPlanStep compare steps with priority, type and name
+func lessPlanStep(left, right *PlanStep) bool {
+	if left.Priority != right.Priority {
+		return left.Priority < right.Priority
+	}
+
+	if left.EntryType != right.EntryType {
+		return left.EntryType < right.EntryType
+	}
+
+	return left.EntryName < right.EntryName
+}
+
+// runEntryFunc runs Bootstrap or Interrupt function and recover panic as error
+func runEntryFunc(f func(context.Context), c context.Context) (err error) {
+	defer func() {
+		if recv := recover(); recv != nil {
+			if v, ok := recv.(error); ok {
+				err = v
+			} else {
+				err = fmt.Errorf("%v", recv)
+			}
+		}
+	}()
+
+	f(c)
+	return nil
+}
+
+// runEntryFuncWithTimeout runs Bootstrap or Interrupt function with deadline, an error will be returned
+// if it didn't return within timeout, function keeps running in background. Zero timeout means no timeout.
+func runEntryFuncWithTimeout(f func(context.Context), c context.Context, action string, timeout time.Duration) error {
+	if timeout <= 0 {
+		return runEntryFunc(f, c)
+	}
+
+	funcCtx, cancel := context.WithTimeout(c, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- runEntryFunc(f, funcCtx)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("entry didn't %s within %s", action, timeout)
+	}
+}
@@ -0,0 +1,1009 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/rookie-ninja/rk-query"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type lifecycleEntryMock struct {
+	EntryMock
+	deps      []string
+	priority  int
+	panicBoot bool
+	trace     *[]string
+}
+
+func (entry *lifecycleEntryMock) Bootstrap(context.Context) {
+	if entry.panicBoot {
+		panic("ut-panic")
+	}
+	*entry.trace = append(*entry.trace, "bootstrap:"+entry.Name)
+}
+
+func (entry *lifecycleEntryMock) Interrupt(context.Context) {
+	*entry.trace = append(*entry.trace, "interrupt:"+entry.Name)
+}
+
+func (entry *lifecycleEntryMock) GetDependencies() []string {
+	return entry.deps
+}
+
+func (entry *lifecycleEntryMock) GetPriority() int {
+	return entry.priority
+}
+
+func TestAppContext_BootstrapPlan(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	GlobalAppCtx.clearEntries()
+
+	trace := make([]string, 0)
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "c"}, deps: []string{"a"}, trace: &trace})
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "b"}, priority: -1, deps: []string{"c"}, trace: &trace})
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "a"}, trace: &trace})
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "d"}, priority: -1, trace: &trace})
+
+	plan, err := GlobalAppCtx.BootstrapPlan()
+	assert.Nil(t, err)
+	assert.Len(t, plan, 4)
+
+	names := make([]string, 0)
+	for i := range plan {
+		assert.Equal(t, i, plan[i].Order)
+		assert.Equal(t, "mock", plan[i].EntryType)
+		names = append(names, plan[i].EntryName)
+	}
+	assert.Equal(t, []string{"d", "a", "c", "b"}, names)
+	assert.Equal(t, []string{"c"}, plan[3].Dependencies)
+	assert.Equal(t, PlanReasonPriority, plan[0].Reason)
+	assert.Equal(t, PlanReasonStable, plan[1].Reason)
+	assert.Equal(t, PlanReasonDependency, plan[3].Reason)
+
+	// plan should not run anything
+	assert.Empty(t, trace)
+
+	// bootstrap and interrupt
+	assert.Nil(t, GlobalAppCtx.BootstrapAll(context.TODO()))
+	assert.Nil(t, GlobalAppCtx.InterruptAll(context.TODO()))
+	assert.Equal(t, []string{
+		"bootstrap:d", "bootstrap:a", "bootstrap:c", "bootstrap:b",
+		"interrupt:b", "interrupt:c", "interrupt:a", "interrupt:d",
+	}, trace)
+}
+
+func TestAppContext_BootstrapAll_WithPlanEvent(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	GlobalAppCtx.clearEntries()
+
+	buf := &bytes.Buffer{}
+	GlobalAppCtx.AddEntry(&EventEntry{
+		entryName: "ut-event-plan",
+		entryType: EventEntryType,
+		IsDefault: true,
+		EventHelper: rkquery.NewEventHelper(rkquery.NewEventFactory(
+			rkquery.WithZapLogger(zap.New(zapcore.NewCore(
+				zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(buf), zap.DebugLevel))),
+			rkquery.WithEncoding(rkquery.JSON))),
+	})
+	trace := make([]string, 0)
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "ut-plan-event"}, trace: &trace})
+
+	assert.Nil(t, GlobalAppCtx.BootstrapAll(context.TODO()))
+	assert.Nil(t, GlobalAppCtx.InterruptAll(context.TODO()))
+
+	assert.Contains(t, buf.String(), "bootstrapPlan")
+	assert.Contains(t, buf.String(), "ut-plan-event")
+	assert.Contains(t, buf.String(), PlanReasonPriority)
+	assert.Contains(t, buf.String(), PlanReasonStable)
+}
+
+func TestAppContext_BootstrapAll_WithLogSummary(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	defer GlobalAppCtx.SetBootstrapLogSummary(0, 0)
+	GlobalAppCtx.clearEntries()
+
+	buf := &bytes.Buffer{}
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(buf), zap.DebugLevel)
+	GlobalAppCtx.AddEntry(&EventEntry{
+		entryName: "ut-event-summary",
+		entryType: EventEntryType,
+		IsDefault: true,
+		EventHelper: rkquery.NewEventHelper(rkquery.NewEventFactory(
+			rkquery.WithZapLogger(zap.New(core)),
+			rkquery.WithEncoding(rkquery.JSON))),
+	})
+	GlobalAppCtx.AddEntry(&LoggerEntry{
+		entryName: "ut-logger-summary",
+		entryType: LoggerEntryType,
+		IsDefault: true,
+		Logger:    zap.New(core),
+	})
+	trace := make([]string, 0)
+	for i := 0; i < 4; i++ {
+		GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: fmt.Sprintf("ut-summary-%d", i)}, trace: &trace})
+	}
+
+	// without summary
+	assert.False(t, GlobalAppCtx.IsBootstrapLogSummarized())
+	assert.Nil(t, GlobalAppCtx.BootstrapAll(context.TODO()))
+	assert.Nil(t, GlobalAppCtx.InterruptAll(context.TODO()))
+	assert.Equal(t, 6, strings.Count(buf.String(), "Bootstrapped entry"))
+	assert.NotContains(t, buf.String(), "Bootstrap in progress")
+	assert.NotContains(t, buf.String(), "bootstrapCompleted")
+
+	// with summary every 3 entries
+	buf.Reset()
+	GlobalAppCtx.SetBootstrapLogSummary(3, 0)
+	assert.True(t, GlobalAppCtx.IsBootstrapLogSummarized())
+	assert.Nil(t, GlobalAppCtx.BootstrapAll(context.TODO()))
+	assert.Nil(t, GlobalAppCtx.InterruptAll(context.TODO()))
+	assert.Equal(t, 6, strings.Count(buf.String(), "Bootstrapped entry"))
+	assert.Equal(t, 2, strings.Count(buf.String(), "Bootstrap in progress"))
+	assert.Equal(t, 1, strings.Count(buf.String(), "bootstrapCompleted"))
+}
+
+func TestAppContext_TrackInFlight(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	defer GlobalAppCtx.SetWaitInFlightOnInterrupt(false)
+	GlobalAppCtx.clearEntries()
+
+	// nothing in flight
+	assert.Nil(t, GlobalAppCtx.WaitInFlight(context.TODO()))
+
+	done := GlobalAppCtx.TrackInFlight()
+	assert.Equal(t, int64(1), GlobalAppCtx.GetInFlightCount())
+
+	// timed out
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.NotNil(t, GlobalAppCtx.WaitInFlight(ctx))
+
+	// entries are interrupted after in-flight operations finished
+	trace := make([]string, 0)
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "ut-in-flight"}, trace: &trace})
+	assert.Nil(t, GlobalAppCtx.BootstrapAll(context.TODO()))
+
+	GlobalAppCtx.SetWaitInFlightOnInterrupt(true)
+	finished := atomic.NewBool(false)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		finished.Store(true)
+		done()
+		done()
+	}()
+	assert.Nil(t, GlobalAppCtx.InterruptAll(context.TODO()))
+	assert.True(t, finished.Load())
+	assert.Equal(t, int64(0), GlobalAppCtx.GetInFlightCount())
+	assert.Equal(t, []string{"bootstrap:ut-in-flight", "interrupt:ut-in-flight"}, trace)
+
+	// entries are interrupted anyway if timed out
+	defer GlobalAppCtx.TrackInFlight()()
+	assert.Nil(t, GlobalAppCtx.BootstrapAll(context.TODO()))
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.NotNil(t, GlobalAppCtx.InterruptAll(ctx))
+	assert.Equal(t, EntryStateStopped, GlobalAppCtx.GetEntryState("mock", "ut-in-flight"))
+}
+
+type valueEntryMock struct {
+	EntryMock
+	value interface{}
+}
+
+func (entry *valueEntryMock) Bootstrap(ctx context.Context) {
+	entry.value = GetBootstrapValue(ctx, "region")
+}
+
+func TestAppContext_BootstrapAllWithValues(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	GlobalAppCtx.clearEntries()
+
+	entry := &valueEntryMock{EntryMock: EntryMock{Name: "ut-bootstrap-values"}}
+	GlobalAppCtx.AddEntry(entry)
+
+	values := map[string]interface{}{"region": "us-east-1"}
+	assert.Nil(t, GlobalAppCtx.BootstrapAllWithValues(context.TODO(), values))
+	assert.Nil(t, GlobalAppCtx.InterruptAll(context.TODO()))
+	assert.Equal(t, "us-east-1", entry.value)
+
+	// missing
+	assert.Nil(t, GetBootstrapValue(context.TODO(), "region"))
+}
+
+func TestAppContext_BootstrapPlan_WithBuiltinPriority(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	GlobalAppCtx.clearEntries()
+
+	GlobalAppCtx.AddEntry(&EntryMock{Name: "ut-mock"})
+	GlobalAppCtx.AddEntry(NewLoggerEntryNoop())
+
+	plan, err := GlobalAppCtx.BootstrapPlan()
+	assert.Nil(t, err)
+	assert.Len(t, plan, 2)
+	assert.Equal(t, LoggerEntryType, plan[0].EntryType)
+	assert.Equal(t, "ut-mock", plan[1].EntryName)
+}
+
+func TestAppContext_BootstrapPlan_WithError(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	GlobalAppCtx.clearEntries()
+
+	trace := make([]string, 0)
+
+	// missing dependency
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "a"}, deps: []string{"missing"}, trace: &trace})
+	_, err := GlobalAppCtx.BootstrapPlan()
+	assert.NotNil(t, err)
+	assert.NotNil(t, GlobalAppCtx.BootstrapAll(context.TODO()))
+	assert.NotNil(t, GlobalAppCtx.InterruptAll(context.TODO()))
+
+	// cycle
+	GlobalAppCtx.clearEntries()
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "a"}, deps: []string{"b"}, trace: &trace})
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "b"}, deps: []string{"a"}, trace: &trace})
+	_, err = GlobalAppCtx.BootstrapPlan()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "mock/a,mock/b")
+
+	// panic while bootstrap
+	GlobalAppCtx.clearEntries()
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "a"}, panicBoot: true, trace: &trace})
+	err = GlobalAppCtx.BootstrapAll(context.TODO())
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "ut-panic")
+	assert.Empty(t, trace)
+}
+
+type interruptPrioritizedEntryMock struct {
+	lifecycleEntryMock
+	interruptPriority int
+}
+
+func (entry *interruptPrioritizedEntryMock) GetInterruptPriority() int {
+	return entry.interruptPriority
+}
+
+func TestAppContext_InterruptPlan(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	defer GlobalAppCtx.RemoveInterruptPriority("ut-db")
+	GlobalAppCtx.clearEntries()
+
+	trace := make([]string, 0)
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "ut-db"}, priority: -2, trace: &trace})
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "ut-cache"}, priority: -1, trace: &trace})
+	GlobalAppCtx.AddEntry(&interruptPrioritizedEntryMock{
+		lifecycleEntryMock: lifecycleEntryMock{EntryMock: EntryMock{Name: "ut-server"}, deps: []string{"ut-db"}, trace: &trace},
+		interruptPriority:  100,
+	})
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "ut-worker"}, priority: 1, trace: &trace})
+
+	// empty name is ignored
+	GlobalAppCtx.SetInterruptPriority("", 10)
+	assert.Nil(t, GlobalAppCtx.BootstrapAll(context.TODO()))
+	assert.Equal(t, []string{"bootstrap:ut-db", "bootstrap:ut-cache", "bootstrap:ut-server", "bootstrap:ut-worker"}, trace)
+
+	// server stops first and db stops last
+	GlobalAppCtx.SetInterruptPriority("ut-db", -100)
+	plan, err := GlobalAppCtx.InterruptPlan()
+	assert.Nil(t, err)
+	assert.Len(t, plan, 4)
+	assert.Equal(t, "ut-server", plan[0].EntryName)
+	assert.Equal(t, 100, plan[0].InterruptPriority)
+	assert.Equal(t, 0, plan[0].Order)
+	assert.Equal(t, 3, plan[3].Order)
+
+	trace = trace[:0]
+	assert.Nil(t, GlobalAppCtx.InterruptAll(context.TODO()))
+	assert.Equal(t, []string{"interrupt:ut-server", "interrupt:ut-worker", "interrupt:ut-cache", "interrupt:ut-db"}, trace)
+
+	// priority set by name overrides interface
+	GlobalAppCtx.SetInterruptPriority("ut-server", -200)
+	defer GlobalAppCtx.RemoveInterruptPriority("ut-server")
+	plan, err = GlobalAppCtx.InterruptPlan()
+	assert.Nil(t, err)
+	assert.Equal(t, "ut-server", plan[3].EntryName)
+
+	assert.True(t, GlobalAppCtx.RemoveInterruptPriority("ut-db"))
+	assert.False(t, GlobalAppCtx.RemoveInterruptPriority("ut-db"))
+}
+
+func TestAppContext_BootstrapAll_WithFailurePolicy(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	defer GlobalAppCtx.SetEntryFailurePolicy("mock", "ut-policy-a", nil)
+	GlobalAppCtx.clearEntries()
+
+	trace := make([]string, 0)
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "ut-policy-a"}, priority: -1, panicBoot: true, trace: &trace})
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "ut-policy-b"}, trace: &trace})
+
+	// startup fails by default
+	assert.Equal(t, FailurePolicyFail, GlobalAppCtx.GetEntryFailurePolicy("mock", "ut-policy-a"))
+	assert.NotNil(t, GlobalAppCtx.BootstrapAll(context.TODO()))
+	assert.Empty(t, trace)
+
+	// continue with warning
+	assert.Nil(t, GlobalAppCtx.SetEntryFailurePolicy("mock", "ut-policy-a", &BootFailurePolicy{FailurePolicy: "WARN"}))
+	assert.Equal(t, FailurePolicyWarn, GlobalAppCtx.GetEntryFailurePolicy("mock", "ut-policy-a"))
+	assert.Nil(t, GlobalAppCtx.BootstrapAll(context.TODO()))
+	assert.Equal(t, []string{"bootstrap:ut-policy-b"}, trace)
+	assert.Equal(t, EntryStateFailed, GlobalAppCtx.GetEntryState("mock", "ut-policy-a"))
+	assert.Equal(t, EntryStateRunning, GlobalAppCtx.GetEntryState("mock", "ut-policy-b"))
+
+	// ignore
+	assert.Nil(t, GlobalAppCtx.SetEntryFailurePolicy("mock", "ut-policy-a", &BootFailurePolicy{FailurePolicy: "ignore"}))
+	assert.Nil(t, GlobalAppCtx.BootstrapAll(context.TODO()))
+
+	// invalid value
+	assert.NotNil(t, GlobalAppCtx.SetEntryFailurePolicy("mock", "ut-policy-a", &BootFailurePolicy{FailurePolicy: "invalid"}))
+	assert.Equal(t, FailurePolicyIgnore, GlobalAppCtx.GetEntryFailurePolicy("mock", "ut-policy-a"))
+}
+
+func TestAppContext_BootstrapAll_WithFailedDependency(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	GlobalAppCtx.clearEntries()
+
+	trace := make([]string, 0)
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "ut-dep-a"}, panicBoot: true, trace: &trace})
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "ut-dep-b"}, deps: []string{"ut-dep-a"}, trace: &trace})
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "ut-dep-c"}, deps: []string{"ut-dep-b"}, trace: &trace})
+	assert.Nil(t, GlobalAppCtx.SetEntryFailurePolicy("mock", "ut-dep-a", &BootFailurePolicy{FailurePolicy: "warn"}))
+	assert.Nil(t, GlobalAppCtx.SetEntryFailurePolicy("mock", "ut-dep-c", &BootFailurePolicy{FailurePolicy: "ignore"}))
+
+	// dependent follows its own policy which is fail
+	err := GlobalAppCtx.BootstrapAll(context.TODO())
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "ut-dep-a")
+	assert.Empty(t, trace)
+	assert.Equal(t, EntryStateFailed, GlobalAppCtx.GetEntryState("mock", "ut-dep-a"))
+	assert.Equal(t, EntryStateFailed, GlobalAppCtx.GetEntryState("mock", "ut-dep-b"))
+	assert.Equal(t, EntryStateRegistered, GlobalAppCtx.GetEntryState("mock", "ut-dep-c"))
+
+	// dependents are skipped transitively
+	assert.Nil(t, GlobalAppCtx.SetEntryFailurePolicy("mock", "ut-dep-b", &BootFailurePolicy{FailurePolicy: "warn"}))
+	assert.Nil(t, GlobalAppCtx.BootstrapAll(context.TODO()))
+	assert.Empty(t, trace)
+	assert.Equal(t, EntryStateFailed, GlobalAppCtx.GetEntryState("mock", "ut-dep-b"))
+	assert.Equal(t, EntryStateFailed, GlobalAppCtx.GetEntryState("mock", "ut-dep-c"))
+}
+
+func TestRegisterLoggerEntryYAML_WithFailurePolicy(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(LoggerEntryType)
+	defer GlobalAppCtx.SetEntryFailurePolicy(LoggerEntryType, "ut-logger-policy", nil)
+
+	RegisterLoggerEntryYAML([]byte(`
+logger:
+  - name: ut-logger-policy
+    failurePolicy: warn
+`))
+	assert.Equal(t, FailurePolicyWarn, GlobalAppCtx.GetEntryFailurePolicy(LoggerEntryType, "ut-logger-policy"))
+
+	// invalid value
+	defer assertPanic(t)
+	RegisterLoggerEntryYAML([]byte(`
+logger:
+  - name: ut-logger-policy-invalid
+    failurePolicy: invalid
+`))
+}
+
+func TestAppContext_BootstrapAll_WithRollback(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	defer GlobalAppCtx.SetRollbackOnBootstrapFailure(false)
+	GlobalAppCtx.clearEntries()
+
+	trace := make([]string, 0)
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "ut-rollback-running"}, priority: -3, trace: &trace})
+	assert.Nil(t, GlobalAppCtx.BootstrapAll(context.TODO()))
+
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "ut-rollback-a"}, priority: -2, trace: &trace})
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "ut-rollback-b"}, priority: -1, trace: &trace})
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "ut-rollback-c"}, panicBoot: true, trace: &trace})
+
+	// leave as is by default
+	trace = trace[:0]
+	assert.NotNil(t, GlobalAppCtx.BootstrapAll(context.TODO()))
+	assert.Equal(t, []string{"bootstrap:ut-rollback-a", "bootstrap:ut-rollback-b"}, trace)
+	assert.Equal(t, EntryStateRunning, GlobalAppCtx.GetEntryState("mock", "ut-rollback-b"))
+
+	// rollback entries bootstrapped by this call in reversed order
+	GlobalAppCtx.removeEntryLifecycle("mock", "ut-rollback-a", "ut-rollback-b")
+
+	GlobalAppCtx.SetRollbackOnBootstrapFailure(true)
+	trace = trace[:0]
+	err := GlobalAppCtx.BootstrapAll(context.TODO())
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "ut-panic")
+	assert.Equal(t, []string{
+		"bootstrap:ut-rollback-a",
+		"bootstrap:ut-rollback-b",
+		"interrupt:ut-rollback-b",
+		"interrupt:ut-rollback-a",
+	}, trace)
+	assert.Equal(t, EntryStateStopped, GlobalAppCtx.GetEntryState("mock", "ut-rollback-a"))
+	assert.Equal(t, EntryStateRunning, GlobalAppCtx.GetEntryState("mock", "ut-rollback-running"))
+}
+
+type blockingEntryMock struct {
+	EntryMock
+	started chan struct{}
+	release chan struct{}
+}
+
+func (entry *blockingEntryMock) Bootstrap(context.Context) {
+	close(entry.started)
+	<-entry.release
+}
+
+func TestAppContext_RemoveEntry_WithLifecycle(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	GlobalAppCtx.clearEntries()
+
+	trace := make([]string, 0)
+	entry := &lifecycleEntryMock{EntryMock: EntryMock{Name: "ut-remove-lifecycle"}, trace: &trace}
+	GlobalAppCtx.AddEntry(entry)
+	assert.Nil(t, GlobalAppCtx.SetEntryTimeout("mock", "ut-remove-lifecycle", &BootTimeout{BootstrapTimeout: "1s"}))
+	assert.Nil(t, GlobalAppCtx.SetEntryFailurePolicy("mock", "ut-remove-lifecycle", &BootFailurePolicy{FailurePolicy: "warn"}))
+	assert.Nil(t, GlobalAppCtx.BootstrapAll(context.TODO()))
+	assert.Equal(t, EntryStateRunning, GlobalAppCtx.GetEntryState("mock", "ut-remove-lifecycle"))
+
+	// states and settings of entry are cleared
+	GlobalAppCtx.RemoveEntry(entry)
+	assert.Equal(t, EntryStateRegistered, GlobalAppCtx.GetEntryState("mock", "ut-remove-lifecycle"))
+	bootstrap, _ := GlobalAppCtx.GetEntryTimeout("mock", "ut-remove-lifecycle")
+	assert.Zero(t, bootstrap)
+	assert.Equal(t, FailurePolicyFail, GlobalAppCtx.GetEntryFailurePolicy("mock", "ut-remove-lifecycle"))
+}
+
+func TestAppContext_BootstrapAll_WithRunningEntry(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	GlobalAppCtx.clearEntries()
+
+	trace := make([]string, 0)
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "ut-bootstrap-twice"}, trace: &trace})
+
+	assert.Nil(t, GlobalAppCtx.BootstrapAll(context.TODO()))
+	// second bootstrap should be skipped
+	assert.Nil(t, GlobalAppCtx.BootstrapAll(context.TODO()))
+	assert.Equal(t, []string{"bootstrap:ut-bootstrap-twice"}, trace)
+	assert.Equal(t, EntryStateRunning, GlobalAppCtx.GetEntryState("mock", "ut-bootstrap-twice"))
+
+	// bootstrap after interrupt
+	assert.Nil(t, GlobalAppCtx.InterruptAll(context.TODO()))
+	assert.Nil(t, GlobalAppCtx.BootstrapAll(context.TODO()))
+	assert.Equal(t, []string{"bootstrap:ut-bootstrap-twice", "interrupt:ut-bootstrap-twice", "bootstrap:ut-bootstrap-twice"}, trace)
+}
+
+func TestAppContext_RestartEntry(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	GlobalAppCtx.clearEntries()
+
+	trace := make([]string, 0)
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "ut-restart"}, trace: &trace})
+
+	assert.Equal(t, EntryStateRegistered, GlobalAppCtx.GetEntryState("mock", "ut-restart"))
+	assert.Nil(t, GlobalAppCtx.BootstrapAll(context.TODO()))
+	assert.Equal(t, EntryStateRunning, GlobalAppCtx.GetEntryState("mock", "ut-restart"))
+
+	// happy case
+	assert.Nil(t, GlobalAppCtx.RestartEntry(context.TODO(), "ut-restart"))
+	assert.Equal(t, []string{"bootstrap:ut-restart", "interrupt:ut-restart", "bootstrap:ut-restart"}, trace)
+	assert.Equal(t, EntryStateRunning, GlobalAppCtx.GetEntryState("mock", "ut-restart"))
+
+	// missing entry
+	assert.NotNil(t, GlobalAppCtx.RestartEntry(context.TODO(), "ut-missing"))
+
+	// failed to bootstrap
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "ut-panic"}, panicBoot: true, trace: &trace})
+	assert.NotNil(t, GlobalAppCtx.RestartEntry(context.TODO(), "ut-panic"))
+	assert.Equal(t, EntryStateFailed, GlobalAppCtx.GetEntryState("mock", "ut-panic"))
+
+	// entry in transition
+	entry := &blockingEntryMock{
+		EntryMock: EntryMock{Name: "ut-blocking"},
+		started:   make(chan struct{}),
+		release:   make(chan struct{}),
+	}
+	GlobalAppCtx.AddEntry(entry)
+	restarted := make(chan struct{})
+	go func() {
+		defer close(restarted)
+		GlobalAppCtx.RestartEntry(context.TODO(), "ut-blocking")
+	}()
+	<-entry.started
+	assert.Equal(t, EntryStateBootstrapping, GlobalAppCtx.GetEntryState("mock", "ut-blocking"))
+	err := GlobalAppCtx.RestartEntry(context.TODO(), "ut-blocking")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "in transition")
+	close(entry.release)
+	<-restarted
+}
+
+func TestAppContext_SubscribeLifecycle(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	GlobalAppCtx.clearEntries()
+
+	trace := make([]string, 0)
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "ut-subscribe"}, trace: &trace})
+
+	a := GlobalAppCtx.SubscribeLifecycle()
+	b := GlobalAppCtx.SubscribeLifecycle()
+	defer GlobalAppCtx.UnsubscribeLifecycle(a)
+
+	assert.Nil(t, GlobalAppCtx.BootstrapAll(context.TODO()))
+	assert.Nil(t, GlobalAppCtx.InterruptAll(context.TODO()))
+
+	// every subscriber receives a copy
+	for _, ch := range []<-chan LifecycleEvent{a, b} {
+		events := make([]string, 0)
+		for len(ch) > 0 {
+			event := <-ch
+			assert.Equal(t, "mock", event.EntryType)
+			assert.False(t, event.Time.IsZero())
+			events = append(events, fmt.Sprintf("%s:%s->%s", event.EntryName, event.FromState, event.ToState))
+		}
+		assert.Equal(t, []string{
+			"ut-subscribe:Registered->Bootstrapping",
+			"ut-subscribe:Bootstrapping->Running",
+			"ut-subscribe:Running->Interrupting",
+			"ut-subscribe:Interrupting->Stopped",
+		}, events)
+	}
+
+	// unsubscribe closes channel
+	GlobalAppCtx.UnsubscribeLifecycle(b)
+	_, ok := <-b
+	assert.False(t, ok)
+	assert.Zero(t, GlobalAppCtx.GetLifecycleEventsDropped(b))
+
+	// slow subscriber doesn't block lifecycle
+	assert.Nil(t, GlobalAppCtx.BootstrapAll(context.TODO()))
+	for len(a) > 0 {
+		<-a
+	}
+	for i := 0; i < DefaultLifecycleEventBufferSize; i++ {
+		assert.Nil(t, GlobalAppCtx.RestartEntry(context.TODO(), "ut-subscribe"))
+	}
+	assert.Len(t, a, DefaultLifecycleEventBufferSize)
+	assert.Equal(t, int64(3*DefaultLifecycleEventBufferSize), GlobalAppCtx.GetLifecycleEventsDropped(a))
+}
+
+type slowInterruptEntryMock struct {
+	EntryMock
+	delay time.Duration
+}
+
+func (entry *slowInterruptEntryMock) Interrupt(context.Context) {
+	time.Sleep(entry.delay)
+}
+
+func TestRun(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	defer GlobalAppCtx.clearShutdownHooks()
+	GlobalAppCtx.clearEntries()
+
+	trace := make([]string, 0)
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "ut-run"}, trace: &trace})
+	GlobalAppCtx.AddShutdownHook("ut-hook", func() {
+		trace = append(trace, "hook")
+	})
+
+	// cancelled context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Nil(t, Run(ctx))
+	assert.Equal(t, []string{"bootstrap:ut-run", "interrupt:ut-run", "hook"}, trace)
+}
+
+type shutdownTraceKey struct{}
+
+type shutdownCtxEntryMock struct {
+	EntryMock
+	value    interface{}
+	deadline bool
+}
+
+func (entry *shutdownCtxEntryMock) Interrupt(ctx context.Context) {
+	entry.value = ctx.Value(shutdownTraceKey{})
+	_, entry.deadline = ctx.Deadline()
+}
+
+func TestRun_WithShutdownContext(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	defer GlobalAppCtx.SetShutdownContext(nil)
+	GlobalAppCtx.clearEntries()
+
+	assert.Equal(t, context.Background(), GlobalAppCtx.GetShutdownContext())
+
+	entry := &shutdownCtxEntryMock{EntryMock: EntryMock{Name: "ut-shutdown-ctx"}}
+	GlobalAppCtx.AddEntry(entry)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Nil(t, Run(ctx, WithShutdownContext(context.WithValue(context.Background(), shutdownTraceKey{}, "ut-trace"))))
+	assert.True(t, entry.deadline)
+	assert.Equal(t, "ut-trace", entry.value)
+
+	// restored after Run returned
+	assert.Equal(t, context.Background(), GlobalAppCtx.GetShutdownContext())
+}
+
+type runOptionEntryMock struct {
+	EntryMock
+	timeout  time.Duration
+	priority int
+	summary  bool
+}
+
+func (entry *runOptionEntryMock) Bootstrap(context.Context) {
+	entry.timeout, _ = GlobalAppCtx.GetEntryTimeout(entry.GetType(), entry.GetName())
+	entry.priority = GlobalAppCtx.getEntryInterruptPriority(entry)
+	entry.summary = GlobalAppCtx.IsBootstrapLogSummarized()
+}
+
+func TestRun_WithRunOptions(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	GlobalAppCtx.clearEntries()
+
+	entry := &runOptionEntryMock{EntryMock: EntryMock{Name: "ut-run-option"}}
+	GlobalAppCtx.AddEntry(entry)
+	GlobalAppCtx.SetInterruptPriority("ut-run-option", 1)
+	defer GlobalAppCtx.RemoveInterruptPriority("ut-run-option")
+
+	gated := false
+	opts := []RunOption{
+		WithDefaultEntryTimeout(time.Minute, time.Minute),
+		WithInterruptPriority("ut-run-option", 10),
+		WithBootstrapLogSummary(10, 0),
+		WithBootstrapGate("ut-run-option", func(context.Context) error {
+			gated = true
+			return nil
+		}, time.Second),
+	}
+
+	// applying options has no side effect
+	for i := range opts {
+		opts[i](&runOption{})
+	}
+	timeout, _ := GlobalAppCtx.GetEntryTimeout("mock", "ut-run-option")
+	assert.Zero(t, timeout)
+	assert.False(t, GlobalAppCtx.RemoveBootstrapGate("ut-run-option"))
+
+	// applied while running
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Nil(t, Run(ctx, opts...))
+	assert.Equal(t, time.Minute, entry.timeout)
+	assert.Equal(t, 10, entry.priority)
+	assert.True(t, entry.summary)
+	assert.True(t, gated)
+
+	// restored after Run returned
+	timeout, _ = GlobalAppCtx.GetEntryTimeout("mock", "ut-run-option")
+	assert.Zero(t, timeout)
+	assert.Equal(t, 1, GlobalAppCtx.getEntryInterruptPriority(entry))
+	assert.False(t, GlobalAppCtx.IsBootstrapLogSummarized())
+	assert.False(t, GlobalAppCtx.RemoveBootstrapGate("ut-run-option"))
+}
+
+func TestRun_WithUserValuePersistence(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	defer GlobalAppCtx.ClearValues()
+	GlobalAppCtx.clearEntries()
+	GlobalAppCtx.ClearValues()
+
+	filePath := filepath.Join(t.TempDir(), "values.json")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// values should be saved after shutdown
+	GlobalAppCtx.AddValue("ut-key", "ut-value")
+	assert.Nil(t, Run(ctx, WithUserValuePersistence(filePath)))
+	assert.FileExists(t, filePath)
+
+	// values should be loaded before bootstrap
+	GlobalAppCtx.ClearValues()
+	assert.Nil(t, Run(ctx, WithUserValuePersistence(filePath)))
+	assert.Equal(t, "ut-value", GlobalAppCtx.GetValue("ut-key"))
+}
+
+func TestRun_WithTriggerShutdown(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	defer func() {
+		GlobalAppCtx.shutdownTime = time.Time{}
+		GlobalAppCtx.shutdownSigRecv = nil
+		GlobalAppCtx.setDraining(false)
+	}()
+	GlobalAppCtx.clearEntries()
+	GlobalAppCtx.shutdownTime = time.Time{}
+	GlobalAppCtx.shutdownSigRecv = nil
+
+	trace := make([]string, 0)
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "ut-trigger-shutdown"}, trace: &trace})
+
+	assert.Empty(t, GlobalAppCtx.GetShutdownReport().Signal)
+
+	go GlobalAppCtx.TriggerShutdown()
+	assert.Nil(t, Run(context.Background()))
+	assert.Equal(t, []string{"bootstrap:ut-trigger-shutdown", "interrupt:ut-trigger-shutdown"}, trace)
+	assert.True(t, GlobalAppCtx.IsDraining())
+
+	report := GlobalAppCtx.GetShutdownReport()
+	assert.Equal(t, "manual", report.Signal)
+	assert.False(t, report.ShutdownTime.IsZero())
+	assert.Len(t, report.Interrupts, 1)
+	assert.Equal(t, "ut-trigger-shutdown", report.Interrupts[0].EntryName)
+}
+
+func TestRun_WithError(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	GlobalAppCtx.clearEntries()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// failed to bootstrap, entry should be interrupted
+	trace := make([]string, 0)
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "ut-run"}, panicBoot: true, trace: &trace})
+	err := Run(ctx)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "ut-panic")
+	assert.Equal(t, []string{"interrupt:ut-run"}, trace)
+
+	// exceed grace period
+	GlobalAppCtx.clearEntries()
+	GlobalAppCtx.AddEntry(&slowInterruptEntryMock{EntryMock: EntryMock{Name: "ut-slow"}, delay: time.Second})
+	err = Run(ctx, WithGracePeriod(10*time.Millisecond))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "grace period")
+}
+
+func TestRun_WithBootstrapFailure(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	GlobalAppCtx.clearEntries()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	trace := make([]string, 0)
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "ut-fail-a"}, trace: &trace})
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "ut-fail-b"}, deps: []string{"ut-fail-a"}, panicBoot: true, trace: &trace})
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "ut-fail-c"}, deps: []string{"ut-fail-b"}, trace: &trace})
+
+	// running entry and entry failed in the middle of bootstrap are interrupted, entry never bootstrapped is skipped
+	assert.NotNil(t, Run(ctx))
+	assert.Equal(t, []string{"bootstrap:ut-fail-a", "interrupt:ut-fail-b", "interrupt:ut-fail-a"}, trace)
+	assert.Equal(t, EntryStateStopped, GlobalAppCtx.GetEntryState("mock", "ut-fail-a"))
+	assert.Equal(t, EntryStateStopped, GlobalAppCtx.GetEntryState("mock", "ut-fail-b"))
+	assert.Equal(t, EntryStateRegistered, GlobalAppCtx.GetEntryState("mock", "ut-fail-c"))
+
+	// stopped entries are not interrupted again
+	assert.Nil(t, GlobalAppCtx.InterruptAll(context.TODO()))
+	assert.Len(t, trace, 3)
+	assert.Empty(t, GlobalAppCtx.GetInterruptReport())
+}
+
+func TestAppContext_BeginDrain(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	defer GlobalAppCtx.setDraining(false)
+	GlobalAppCtx.clearEntries()
+
+	trace := make([]string, 0)
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "ut-drain"}, trace: &trace})
+	assert.False(t, GlobalAppCtx.IsDraining())
+	assert.Nil(t, GlobalAppCtx.BootstrapAll(context.TODO()))
+
+	start := time.Now()
+	assert.Nil(t, GlobalAppCtx.BeginDrain(20*time.Millisecond))
+	assert.True(t, GlobalAppCtx.IsDraining())
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	assert.Equal(t, []string{"bootstrap:ut-drain", "interrupt:ut-drain"}, trace)
+
+	// without drain period
+	GlobalAppCtx.setDraining(false)
+	assert.Nil(t, GlobalAppCtx.BeginDrain(0))
+	assert.True(t, GlobalAppCtx.IsDraining())
+}
+
+func TestAppContext_InterruptAll_WithSlowEntry(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	defer GlobalAppCtx.SetSlowInterruptThreshold(DefaultSlowInterruptThreshold)
+	GlobalAppCtx.clearEntries()
+
+	buf := &bytes.Buffer{}
+	GlobalAppCtx.AddEntry(&EventEntry{
+		entryName: "ut-event",
+		entryType: EventEntryType,
+		IsDefault: true,
+		EventHelper: rkquery.NewEventHelper(rkquery.NewEventFactory(
+			rkquery.WithZapLogger(zap.New(zapcore.NewCore(
+				zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(buf), zap.DebugLevel))),
+			rkquery.WithEncoding(rkquery.JSON))),
+	})
+	GlobalAppCtx.AddEntry(&slowInterruptEntryMock{EntryMock: EntryMock{Name: "ut-slow-interrupt"}, delay: 100 * time.Millisecond})
+
+	assert.Nil(t, GlobalAppCtx.BootstrapAll(context.TODO()))
+	GlobalAppCtx.SetSlowInterruptThreshold(10 * time.Millisecond)
+	assert.Nil(t, GlobalAppCtx.InterruptAll(context.TODO()))
+
+	assert.Contains(t, buf.String(), "slowInterrupt")
+	assert.Contains(t, buf.String(), "ut-slow-interrupt")
+
+	report := GlobalAppCtx.GetInterruptReport()
+	assert.Len(t, report, 2)
+	assert.Equal(t, "ut-slow-interrupt", report[0].EntryName)
+	assert.True(t, report[0].Slow)
+	assert.True(t, report[0].Elapsed >= 100*time.Millisecond)
+	assert.Equal(t, "ut-event", report[1].EntryName)
+	assert.False(t, report[1].Slow)
+}
+
+func TestAppContext_BootstrapAll_WithRequiredEnv(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	defer func() { GlobalAppCtx.lifecycle.requiredEnvs = make([]string, 0) }()
+	defer os.Unsetenv("UT_REQUIRED_A")
+	GlobalAppCtx.clearEntries()
+
+	trace := make([]string, 0)
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "ut-required-env"}, trace: &trace})
+
+	RequireEnv("UT_REQUIRED_A", "UT_REQUIRED_B", "UT_REQUIRED_A", "")
+	assert.Equal(t, []string{"UT_REQUIRED_A", "UT_REQUIRED_B"}, GlobalAppCtx.lifecycle.requiredEnvs)
+
+	// all missing ones are reported and nothing is bootstrapped
+	assert.Nil(t, os.Setenv("UT_REQUIRED_A", ""))
+	err := GlobalAppCtx.BootstrapAll(context.TODO())
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "UT_REQUIRED_A,UT_REQUIRED_B")
+	assert.Empty(t, trace)
+
+	// registered from boot config
+	registerAppInfoEntryYAML([]byte("app:\n  requiredEnv: [UT_REQUIRED_C]"))
+	defer func() { GlobalAppCtx.appInfoEntry = appInfoEntryDefault() }()
+	assert.Contains(t, GlobalAppCtx.lifecycle.requiredEnvs, "UT_REQUIRED_C")
+
+	GlobalAppCtx.lifecycle.requiredEnvs = []string{"UT_REQUIRED_A"}
+	assert.Nil(t, os.Setenv("UT_REQUIRED_A", "value"))
+	assert.Nil(t, GlobalAppCtx.BootstrapAll(context.TODO()))
+	assert.Equal(t, []string{"bootstrap:ut-required-env"}, trace)
+}
+
+func TestAppContext_BootstrapAll_WithBootstrapGate(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	defer GlobalAppCtx.RemoveBootstrapGate("ut-gate")
+	GlobalAppCtx.clearEntries()
+
+	trace := make([]string, 0)
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "ut-gate"}, trace: &trace})
+
+	// gate passes after a few polls
+	polls := 0
+	GlobalAppCtx.AddBootstrapGate("ut-gate", func(context.Context) error {
+		if polls++; polls < 3 {
+			return errors.New("ut-not-ready")
+		}
+		return nil
+	}, time.Second)
+
+	assert.Nil(t, GlobalAppCtx.BootstrapAll(context.TODO()))
+	assert.Equal(t, 3, polls)
+	assert.Equal(t, []string{"bootstrap:ut-gate"}, trace)
+	assert.Nil(t, GlobalAppCtx.InterruptAll(context.TODO()))
+
+	// gate times out
+	trace = trace[:0]
+	GlobalAppCtx.AddBootstrapGate("ut-gate", func(context.Context) error {
+		return errors.New("ut-not-ready")
+	}, 100*time.Millisecond)
+
+	err := GlobalAppCtx.BootstrapAll(context.TODO())
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "ut-not-ready")
+	assert.Contains(t, err.Error(), "bootstrap gate didn't pass within 100ms")
+	assert.Empty(t, trace)
+	assert.Equal(t, EntryStateFailed, GlobalAppCtx.GetEntryState("mock", "ut-gate"))
+
+	// parent context is canceled
+	c, cancel := context.WithCancel(context.Background())
+	GlobalAppCtx.AddBootstrapGate("ut-gate", func(context.Context) error {
+		cancel()
+		return errors.New("ut-not-ready")
+	}, time.Minute)
+
+	err = GlobalAppCtx.BootstrapAll(c)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), context.Canceled.Error())
+	assert.NotContains(t, err.Error(), "bootstrap gate didn't pass")
+	assert.Empty(t, trace)
+
+	assert.True(t, GlobalAppCtx.RemoveBootstrapGate("ut-gate"))
+	assert.False(t, GlobalAppCtx.RemoveBootstrapGate("ut-gate"))
+}
+
+type slowBootstrapEntryMock struct {
+	EntryMock
+	delay time.Duration
+}
+
+func (entry *slowBootstrapEntryMock) Bootstrap(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(entry.delay):
+	}
+}
+
+func TestAppContext_SetEntryTimeout(t *testing.T) {
+	defer GlobalAppCtx.SetDefaultEntryTimeout(0, 0)
+	defer GlobalAppCtx.SetEntryTimeout("mock", "ut-timeout", nil)
+
+	// no timeout by default
+	bootstrap, interrupt := GlobalAppCtx.GetEntryTimeout("mock", "ut-timeout")
+	assert.Zero(t, bootstrap)
+	assert.Zero(t, interrupt)
+
+	// fall back to defaults
+	GlobalAppCtx.SetDefaultEntryTimeout(time.Second, 2*time.Second)
+	assert.Nil(t, GlobalAppCtx.SetEntryTimeout("mock", "ut-timeout", &BootTimeout{InterruptTimeout: "5s"}))
+	bootstrap, interrupt = GlobalAppCtx.GetEntryTimeout("mock", "ut-timeout")
+	assert.Equal(t, time.Second, bootstrap)
+	assert.Equal(t, 5*time.Second, interrupt)
+
+	// invalid values
+	assert.NotNil(t, GlobalAppCtx.SetEntryTimeout("mock", "ut-timeout", &BootTimeout{BootstrapTimeout: "invalid"}))
+	assert.NotNil(t, GlobalAppCtx.SetEntryTimeout("mock", "ut-timeout", &BootTimeout{BootstrapTimeout: "-1s"}))
+	_, interrupt = GlobalAppCtx.GetEntryTimeout("mock", "ut-timeout")
+	assert.Equal(t, 5*time.Second, interrupt)
+}
+
+func TestAppContext_BootstrapAll_WithEntryTimeout(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	defer GlobalAppCtx.SetEntryTimeout("mock", "ut-timeout-bootstrap", nil)
+	GlobalAppCtx.clearEntries()
+
+	GlobalAppCtx.AddEntry(&slowBootstrapEntryMock{EntryMock: EntryMock{Name: "ut-timeout-bootstrap"}, delay: time.Minute})
+	assert.Nil(t, GlobalAppCtx.SetEntryTimeout("mock", "ut-timeout-bootstrap", &BootTimeout{BootstrapTimeout: "10ms"}))
+
+	err := GlobalAppCtx.BootstrapAll(context.TODO())
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "entry didn't bootstrap within 10ms")
+	assert.Equal(t, EntryStateFailed, GlobalAppCtx.GetEntryState("mock", "ut-timeout-bootstrap"))
+}
+
+func TestRegisterLoggerEntryYAML_WithTimeout(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(LoggerEntryType)
+	defer GlobalAppCtx.SetEntryTimeout(LoggerEntryType, "ut-logger-timeout", nil)
+
+	RegisterLoggerEntryYAML([]byte(`
+logger:
+  - name: ut-logger-timeout
+    bootstrapTimeout: 3s
+    interruptTimeout: 4s
+`))
+
+	bootstrap, interrupt := GlobalAppCtx.GetEntryTimeout(LoggerEntryType, "ut-logger-timeout")
+	assert.Equal(t, 3*time.Second, bootstrap)
+	assert.Equal(t, 4*time.Second, interrupt)
+
+	// invalid value
+	defer assertPanic(t)
+	RegisterLoggerEntryYAML([]byte(`
+logger:
+  - name: ut-logger-timeout-invalid
+    bootstrapTimeout: invalid
+`))
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"sync"
+	"time"
+)
+
+const (
+	// LimitGoroutines name of limit of running goroutines
+	LimitGoroutines = "goroutines"
+	// LimitLogsPerSec name of limit of logs per second
+	LimitLogsPerSec = "logsPerSec"
+)
+
+// EntryLimits is soft limits of entry, zero means unlimited.
+type EntryLimits struct {
+	MaxGoroutines int64 `yaml:"maxGoroutines" json:"maxGoroutines"`
+	MaxLogsPerSec int64 `yaml:"maxLogsPerSec" json:"maxLogsPerSec"`
+}
+
+// EntryUsage is sampled usage of entry.
+type EntryUsage struct {
+	// Goroutines is number of running goroutines started with EntryLimitsBase.Go
+	Goroutines int64 `yaml:"goroutines" json:"goroutines"`
+	// Logs is total number of logs written by logger wrapped with EntryLimitsBase.WrapLogger
+	Logs int64 `yaml:"logs" json:"logs"`
+}
+
+// EntryLimited is an optional interface of Entry, limits will be checked by CheckEntryLimits.
+//
+// Embed EntryLimitsBase to implement it.
+type EntryLimited interface {
+	GetEntryLimits() EntryLimits
+	GetEntryUsage() EntryUsage
+}
+
+// LimitViolation describes an entry which exceeds its soft limit.
+type LimitViolation struct {
+	EntryName string `yaml:"entryName" json:"entryName"`
+	EntryType string `yaml:"entryType" json:"entryType"`
+	Limit     string `yaml:"limit" json:"limit"`
+	Max       int64  `yaml:"max" json:"max"`
+	Actual    int64  `yaml:"actual" json:"actual"`
+}
+
+// EntryLimitsBase implements EntryLimited, usage is tracked with Go and WrapLogger.
+type EntryLimitsBase struct {
+	Limits     EntryLimits  `yaml:"-" json:"-"`
+	goroutines atomic.Int64 `yaml:"-" json:"-"`
+	logs       atomic.Int64 `yaml:"-" json:"-"`
+}
+
+// GetEntryLimits returns soft limits of entry.
+func (base *EntryLimitsBase) GetEntryLimits() EntryLimits {
+	return base.Limits
+}
+
+// GetEntryUsage returns current usage of entry.
+func (base *EntryLimitsBase) GetEntryUsage() EntryUsage {
+	return EntryUsage{
+		Goroutines: base.goroutines.Load(),
+		Logs:       base.logs.Load(),
+	}
+}
+
+// Go runs f in a new goroutine which is counted as usage of entry.
+func (base *EntryLimitsBase) Go(f func()) {
+	base.goroutines.Inc()
+	go func() {
+		defer base.goroutines.Dec()
+		f()
+	}()
+}
+
+// WrapLogger returns logger whose logs are counted as usage of entry, logs filtered by level are not counted.
+func (base *EntryLimitsBase) WrapLogger(logger *zap.Logger) *zap.Logger {
+	return logger.WithOptions(zap.Hooks(func(zapcore.Entry) error {
+		base.logs.Inc()
+		return nil
+	}))
+}
+
+// limitSample is last sampled logs of entry, used to calculate log rate
+type limitSample struct {
+	logs int64
+	at   time.Time
+}
+
+// limitsWatcher checks limits of entries periodically
+type limitsWatcher struct {
+	lock    sync.Mutex
+	samples map[string]*limitSample
+	quitCh  chan struct{}
+}
+
+// newLimitsWatcher creates limitsWatcher without samples
+func newLimitsWatcher() *limitsWatcher {
+	return &limitsWatcher{
+		samples: make(map[string]*limitSample),
+	}
+}
+
+// CheckEntryLimits sample usage of entries which implement EntryLimited and returns violations.
+//
+// Log rate is calculated from logs since last check, so it is reported from the second check.
+func (ctx *appContext) CheckEntryLimits() []LimitViolation {
+	res := make([]LimitViolation, 0)
+	now := time.Now()
+
+	ctx.limitsWatcher.lock.Lock()
+	defer ctx.limitsWatcher.lock.Unlock()
+
+	for _, entries := range ctx.ListEntries() {
+		for _, entry := range entries {
+			limited, ok := entry.(EntryLimited)
+			if !ok {
+				continue
+			}
+
+			limits, usage := limited.GetEntryLimits(), limited.GetEntryUsage()
+			newViolation := func(limit string, max, actual int64) LimitViolation {
+				return LimitViolation{
+					EntryName: entry.GetName(),
+					EntryType: entry.GetType(),
+					Limit:     limit,
+					Max:       max,
+					Actual:    actual,
+				}
+			}
+
+			if limits.MaxGoroutines > 0 && usage.Goroutines > limits.MaxGoroutines {
+				res = append(res, newViolation(LimitGoroutines, limits.MaxGoroutines, usage.Goroutines))
+			}
+
+			key := entry.GetType() + "/" + entry.GetName()
+			if prev, ok := ctx.limitsWatcher.samples[key]; ok && limits.MaxLogsPerSec > 0 {
+				if elapsed := now.Sub(prev.at).Seconds(); elapsed > 0 {
+					rate := int64(float64(usage.Logs-prev.logs) / elapsed)
+					if rate > limits.MaxLogsPerSec {
+						res = append(res, newViolation(LimitLogsPerSec, limits.MaxLogsPerSec, rate))
+					}
+				}
+			}
+			ctx.limitsWatcher.samples[key] = &limitSample{logs: usage.Logs, at: now}
+		}
+	}
+
+	return res
+}
+
+// StartEntryLimitsWatcher check limits of entries with interval in background and log warnings for violations.
+// Previous watcher will be stopped.
+func (ctx *appContext) StartEntryLimitsWatcher(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ctx.StopEntryLimitsWatcher()
+
+	ctx.limitsWatcher.lock.Lock()
+	quitCh := make(chan struct{})
+	ctx.limitsWatcher.quitCh = quitCh
+	ctx.limitsWatcher.lock.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-quitCh:
+				return
+			case <-ticker.C:
+				for _, v := range ctx.CheckEntryLimits() {
+					ctx.GetLoggerEntryDefault().Warn("Entry exceeds soft limit",
+						zap.String("entryName", v.EntryName),
+						zap.String("entryType", v.EntryType),
+						zap.String("limit", v.Limit),
+						zap.Int64("max", v.Max),
+						zap.Int64("actual", v.Actual))
+				}
+			}
+		}
+	}()
+}
+
+// StopEntryLimitsWatcher stop watcher started with StartEntryLimitsWatcher.
+func (ctx *appContext) StopEntryLimitsWatcher() {
+	ctx.limitsWatcher.lock.Lock()
+	defer ctx.limitsWatcher.lock.Unlock()
+
+	if ctx.limitsWatcher.quitCh != nil {
+		close(ctx.limitsWatcher.quitCh)
+		ctx.limitsWatcher.quitCh = nil
+	}
+}
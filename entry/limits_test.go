@@ -0,0 +1,70 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"io"
+	"testing"
+	"time"
+)
+
+type limitedEntryMock struct {
+	EntryMock
+	EntryLimitsBase
+}
+
+func TestAppContext_CheckEntryLimits(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	GlobalAppCtx.clearEntries()
+
+	entry := &limitedEntryMock{EntryMock: EntryMock{Name: "ut-limited"}}
+	entry.Limits = EntryLimits{MaxGoroutines: 1, MaxLogsPerSec: 1}
+	GlobalAppCtx.AddEntry(entry)
+	GlobalAppCtx.AddEntry(&EntryMock{Name: "ut-unlimited"})
+
+	// within limits, first check only samples logs
+	assert.Empty(t, GlobalAppCtx.CheckEntryLimits())
+
+	// exceed goroutines
+	release := make(chan struct{})
+	for i := 0; i < 2; i++ {
+		entry.Go(func() { <-release })
+	}
+	assert.Equal(t, int64(2), entry.GetEntryUsage().Goroutines)
+
+	// exceed logs
+	logger := entry.WrapLogger(zap.New(zapcore.NewCore(
+		zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(io.Discard), zap.DebugLevel)))
+	for i := 0; i < 100; i++ {
+		logger.Info("ut-log")
+	}
+	assert.Equal(t, int64(100), entry.GetEntryUsage().Logs)
+
+	time.Sleep(10 * time.Millisecond)
+	violations := GlobalAppCtx.CheckEntryLimits()
+	assert.Len(t, violations, 2)
+	for _, v := range violations {
+		assert.Equal(t, "ut-limited", v.EntryName)
+		assert.Contains(t, []string{LimitGoroutines, LimitLogsPerSec}, v.Limit)
+	}
+
+	// back to normal
+	close(release)
+	assert.Eventually(t, func() bool {
+		return entry.GetEntryUsage().Goroutines == 0
+	}, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	assert.Empty(t, GlobalAppCtx.CheckEntryLimits())
+
+	// with watcher
+	GlobalAppCtx.StartEntryLimitsWatcher(time.Millisecond)
+	GlobalAppCtx.StartEntryLimitsWatcher(time.Millisecond)
+	GlobalAppCtx.StopEntryLimitsWatcher()
+	GlobalAppCtx.StopEntryLimitsWatcher()
+}
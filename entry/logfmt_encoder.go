@@ -0,0 +1,322 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// LoggerEncodingLogfmt is encoding of zap config which writes logs as key=value pairs
+const LoggerEncodingLogfmt = "logfmt"
+
+var logfmtBufferPool = buffer.NewPool()
+
+// logfmtEncoder is a zapcore.Encoder which encodes entry and fields as key=value pairs.
+//
+// Keys of entry like time and level are taken from EncoderConfig, arrays, objects and reflected values
+// are encoded as quoted JSON.
+type logfmtEncoder struct {
+	zapcore.EncoderConfig
+	buf       *buffer.Buffer
+	namespace string
+}
+
+// newLogfmtEncoder creates logfmtEncoder with config
+func newLogfmtEncoder(config zapcore.EncoderConfig) *logfmtEncoder {
+	return &logfmtEncoder{
+		EncoderConfig: config,
+		buf:           logfmtBufferPool.Get(),
+	}
+}
+
+// Clone copies encoder with fields added already
+func (enc *logfmtEncoder) Clone() zapcore.Encoder {
+	return enc.clone()
+}
+
+func (enc *logfmtEncoder) clone() *logfmtEncoder {
+	res := newLogfmtEncoder(enc.EncoderConfig)
+	res.namespace = enc.namespace
+	res.buf.Write(enc.buf.Bytes())
+	return res
+}
+
+// EncodeEntry encodes entry, fields added already and fields as a line.
+func (enc *logfmtEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	line := newLogfmtEncoder(enc.EncoderConfig)
+
+	if len(enc.TimeKey) > 0 {
+		if enc.EncodeTime != nil {
+			line.addPrimitive(enc.TimeKey, func(arr zapcore.PrimitiveArrayEncoder) { enc.EncodeTime(ent.Time, arr) })
+		} else {
+			line.AddString(enc.TimeKey, ent.Time.Format(time.RFC3339Nano))
+		}
+	}
+
+	if len(enc.LevelKey) > 0 {
+		if enc.EncodeLevel != nil {
+			line.addPrimitive(enc.LevelKey, func(arr zapcore.PrimitiveArrayEncoder) { enc.EncodeLevel(ent.Level, arr) })
+		} else {
+			line.AddString(enc.LevelKey, ent.Level.String())
+		}
+	}
+
+	if len(enc.NameKey) > 0 && len(ent.LoggerName) > 0 {
+		line.AddString(enc.NameKey, ent.LoggerName)
+	}
+
+	if len(enc.CallerKey) > 0 && ent.Caller.Defined {
+		if enc.EncodeCaller != nil {
+			line.addPrimitive(enc.CallerKey, func(arr zapcore.PrimitiveArrayEncoder) { enc.EncodeCaller(ent.Caller, arr) })
+		} else {
+			line.AddString(enc.CallerKey, ent.Caller.TrimmedPath())
+		}
+	}
+
+	if len(enc.MessageKey) > 0 {
+		line.AddString(enc.MessageKey, ent.Message)
+	}
+
+	// fields added with With
+	if enc.buf.Len() > 0 {
+		line.addSeparator()
+		line.buf.Write(enc.buf.Bytes())
+	}
+	line.namespace = enc.namespace
+
+	for i := range fields {
+		fields[i].AddTo(line)
+	}
+
+	line.namespace = ""
+	if len(enc.StacktraceKey) > 0 && len(ent.Stack) > 0 {
+		line.AddString(enc.StacktraceKey, ent.Stack)
+	}
+
+	lineEnding := enc.LineEnding
+	if len(lineEnding) < 1 {
+		lineEnding = zapcore.DefaultLineEnding
+	}
+	line.buf.AppendString(lineEnding)
+
+	return line.buf, nil
+}
+
+// AddArray adds array as quoted JSON
+func (enc *logfmtEncoder) AddArray(key string, arr zapcore.ArrayMarshaler) error {
+	m := zapcore.NewMapObjectEncoder()
+	if err := m.AddArray(key, arr); err != nil {
+		return err
+	}
+	return enc.AddReflected(key, m.Fields[key])
+}
+
+// AddObject adds object as quoted JSON
+func (enc *logfmtEncoder) AddObject(key string, obj zapcore.ObjectMarshaler) error {
+	m := zapcore.NewMapObjectEncoder()
+	if err := obj.MarshalLogObject(m); err != nil {
+		return err
+	}
+	return enc.AddReflected(key, m.Fields)
+}
+
+// AddBinary adds bytes as base64 string
+func (enc *logfmtEncoder) AddBinary(key string, value []byte) {
+	enc.AddString(key, base64.StdEncoding.EncodeToString(value))
+}
+
+// AddByteString adds UTF-8 bytes as string
+func (enc *logfmtEncoder) AddByteString(key string, value []byte) {
+	enc.AddString(key, string(value))
+}
+
+// AddBool adds bool
+func (enc *logfmtEncoder) AddBool(key string, value bool) {
+	enc.addRaw(key, strconv.FormatBool(value))
+}
+
+// AddComplex128 adds complex128
+func (enc *logfmtEncoder) AddComplex128(key string, value complex128) {
+	enc.addRaw(key, strconv.FormatComplex(value, 'g', -1, 128))
+}
+
+// AddComplex64 adds complex64
+func (enc *logfmtEncoder) AddComplex64(key string, value complex64) {
+	enc.addRaw(key, strconv.FormatComplex(complex128(value), 'g', -1, 64))
+}
+
+// AddDuration adds duration with EncodeDuration of config, string of duration by default
+func (enc *logfmtEncoder) AddDuration(key string, value time.Duration) {
+	if enc.EncodeDuration != nil {
+		enc.addPrimitive(key, func(arr zapcore.PrimitiveArrayEncoder) { enc.EncodeDuration(value, arr) })
+		return
+	}
+	enc.addRaw(key, value.String())
+}
+
+// AddFloat64 adds float64
+func (enc *logfmtEncoder) AddFloat64(key string, value float64) {
+	enc.addRaw(key, strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// AddFloat32 adds float32
+func (enc *logfmtEncoder) AddFloat32(key string, value float32) {
+	enc.addRaw(key, strconv.FormatFloat(float64(value), 'g', -1, 32))
+}
+
+// AddInt adds int
+func (enc *logfmtEncoder) AddInt(key string, value int) { enc.AddInt64(key, int64(value)) }
+
+// AddInt64 adds int64
+func (enc *logfmtEncoder) AddInt64(key string, value int64) {
+	enc.addRaw(key, strconv.FormatInt(value, 10))
+}
+
+// AddInt32 adds int32
+func (enc *logfmtEncoder) AddInt32(key string, value int32) { enc.AddInt64(key, int64(value)) }
+
+// AddInt16 adds int16
+func (enc *logfmtEncoder) AddInt16(key string, value int16) { enc.AddInt64(key, int64(value)) }
+
+// AddInt8 adds int8
+func (enc *logfmtEncoder) AddInt8(key string, value int8) { enc.AddInt64(key, int64(value)) }
+
+// AddString adds string, quoted if needed
+func (enc *logfmtEncoder) AddString(key, value string) {
+	enc.addRaw(key, logfmtQuote(value))
+}
+
+// AddTime adds time with EncodeTime of config, RFC3339 by default
+func (enc *logfmtEncoder) AddTime(key string, value time.Time) {
+	if enc.EncodeTime != nil {
+		enc.addPrimitive(key, func(arr zapcore.PrimitiveArrayEncoder) { enc.EncodeTime(value, arr) })
+		return
+	}
+	enc.AddString(key, value.Format(time.RFC3339Nano))
+}
+
+// AddUint adds uint
+func (enc *logfmtEncoder) AddUint(key string, value uint) { enc.AddUint64(key, uint64(value)) }
+
+// AddUint64 adds uint64
+func (enc *logfmtEncoder) AddUint64(key string, value uint64) {
+	enc.addRaw(key, strconv.FormatUint(value, 10))
+}
+
+// AddUint32 adds uint32
+func (enc *logfmtEncoder) AddUint32(key string, value uint32) { enc.AddUint64(key, uint64(value)) }
+
+// AddUint16 adds uint16
+func (enc *logfmtEncoder) AddUint16(key string, value uint16) { enc.AddUint64(key, uint64(value)) }
+
+// AddUint8 adds uint8
+func (enc *logfmtEncoder) AddUint8(key string, value uint8) { enc.AddUint64(key, uint64(value)) }
+
+// AddUintptr adds uintptr
+func (enc *logfmtEncoder) AddUintptr(key string, value uintptr) { enc.AddUint64(key, uint64(value)) }
+
+// AddReflected adds value as quoted JSON
+func (enc *logfmtEncoder) AddReflected(key string, value interface{}) error {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	enc.AddString(key, string(bytes))
+	return nil
+}
+
+// OpenNamespace prefix keys added afterwards with key and dot
+func (enc *logfmtEncoder) OpenNamespace(key string) {
+	enc.namespace = enc.namespace + key + "."
+}
+
+// addPrimitive adds value appended by f, multiple values are joined with comma
+func (enc *logfmtEncoder) addPrimitive(key string, f func(arr zapcore.PrimitiveArrayEncoder)) {
+	arr := &logfmtArrayEncoder{values: make([]string, 0, 1)}
+	f(arr)
+	enc.AddString(key, strings.Join(arr.values, ","))
+}
+
+// addRaw adds key and value without quoting
+func (enc *logfmtEncoder) addRaw(key, value string) {
+	enc.addSeparator()
+	enc.buf.AppendString(logfmtKey(enc.namespace + key))
+	enc.buf.AppendByte('=')
+	enc.buf.AppendString(value)
+}
+
+// addSeparator adds space between pairs
+func (enc *logfmtEncoder) addSeparator() {
+	if enc.buf.Len() > 0 {
+		enc.buf.AppendByte(' ')
+	}
+}
+
+// logfmtQuote quotes value if it is empty or contains space, quote, equal sign or control characters
+func logfmtQuote(value string) string {
+	if len(value) < 1 {
+		return `""`
+	}
+
+	for _, r := range value {
+		if r == ' ' || r == '"' || r == '=' || !unicode.IsPrint(r) {
+			return strconv.Quote(value)
+		}
+	}
+
+	return value
+}
+
+// logfmtKey replaces characters which are not allowed in key with underscore
+func logfmtKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '"' || r == '=' || !unicode.IsPrint(r) {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+// logfmtArrayEncoder is a zapcore.PrimitiveArrayEncoder which collects values as strings,
+// used for encoders in EncoderConfig like EncodeTime and EncodeLevel.
+type logfmtArrayEncoder struct {
+	values []string
+}
+
+func (arr *logfmtArrayEncoder) append(value string) { arr.values = append(arr.values, value) }
+
+func (arr *logfmtArrayEncoder) AppendBool(v bool)             { arr.append(strconv.FormatBool(v)) }
+func (arr *logfmtArrayEncoder) AppendByteString(v []byte)     { arr.append(string(v)) }
+func (arr *logfmtArrayEncoder) AppendComplex128(v complex128) { arr.append(fmt.Sprint(v)) }
+func (arr *logfmtArrayEncoder) AppendComplex64(v complex64)   { arr.append(fmt.Sprint(v)) }
+func (arr *logfmtArrayEncoder) AppendFloat64(v float64) {
+	arr.append(strconv.FormatFloat(v, 'g', -1, 64))
+}
+func (arr *logfmtArrayEncoder) AppendFloat32(v float32) {
+	arr.append(strconv.FormatFloat(float64(v), 'g', -1, 32))
+}
+func (arr *logfmtArrayEncoder) AppendInt(v int)       { arr.append(strconv.Itoa(v)) }
+func (arr *logfmtArrayEncoder) AppendInt64(v int64)   { arr.append(strconv.FormatInt(v, 10)) }
+func (arr *logfmtArrayEncoder) AppendInt32(v int32)   { arr.append(strconv.FormatInt(int64(v), 10)) }
+func (arr *logfmtArrayEncoder) AppendInt16(v int16)   { arr.append(strconv.FormatInt(int64(v), 10)) }
+func (arr *logfmtArrayEncoder) AppendInt8(v int8)     { arr.append(strconv.FormatInt(int64(v), 10)) }
+func (arr *logfmtArrayEncoder) AppendString(v string) { arr.append(v) }
+func (arr *logfmtArrayEncoder) AppendUint(v uint)     { arr.append(strconv.FormatUint(uint64(v), 10)) }
+func (arr *logfmtArrayEncoder) AppendUint64(v uint64) { arr.append(strconv.FormatUint(v, 10)) }
+func (arr *logfmtArrayEncoder) AppendUint32(v uint32) { arr.append(strconv.FormatUint(uint64(v), 10)) }
+func (arr *logfmtArrayEncoder) AppendUint16(v uint16) { arr.append(strconv.FormatUint(uint64(v), 10)) }
+func (arr *logfmtArrayEncoder) AppendUint8(v uint8)   { arr.append(strconv.FormatUint(uint64(v), 10)) }
+func (arr *logfmtArrayEncoder) AppendUintptr(v uintptr) {
+	arr.append(strconv.FormatUint(uint64(v), 10))
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"testing"
+	"time"
+)
+
+func newLogfmtEncoderForTest() *logfmtEncoder {
+	return newLogfmtEncoder(zapcore.EncoderConfig{
+		MessageKey:  "msg",
+		LevelKey:    "level",
+		EncodeLevel: zapcore.LowercaseLevelEncoder,
+	})
+}
+
+func TestLogfmtEncoder_EncodeEntry(t *testing.T) {
+	enc := newLogfmtEncoderForTest()
+
+	buf, err := enc.EncodeEntry(zapcore.Entry{
+		Level:   zapcore.InfoLevel,
+		Message: "hello world",
+		Time:    time.Now(),
+	}, []zapcore.Field{
+		zap.String("plain", "value"),
+		zap.String("quoted", `a "b" c`),
+		zap.Int("code", 200),
+		zap.Bool("ok", true),
+		zap.Strings("list", []string{"a", "b"}),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t,
+		`level=info msg="hello world" plain=value quoted="a \"b\" c" code=200 ok=true list="[\"a\",\"b\"]"`+"\n",
+		buf.String())
+}
+
+func TestLogfmtEncoder_WithFieldsAndNamespace(t *testing.T) {
+	enc := newLogfmtEncoderForTest()
+	zap.String("service", "ut").AddTo(enc)
+
+	clone := enc.Clone()
+	clone.OpenNamespace("req")
+
+	buf, err := clone.EncodeEntry(zapcore.Entry{
+		Level:   zapcore.WarnLevel,
+		Message: "msg",
+	}, []zapcore.Field{zap.String("id", "1")})
+	assert.Nil(t, err)
+	assert.Equal(t, "level=warn msg=msg service=ut req.id=1\n", buf.String())
+
+	// original encoder is not affected by clone
+	buf, err = enc.EncodeEntry(zapcore.Entry{Level: zapcore.InfoLevel, Message: "msg"}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "level=info msg=msg service=ut\n", buf.String())
+}
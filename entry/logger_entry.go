@@ -7,17 +7,27 @@ package rkentry
 
 import (
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/rookie-ninja/rk-entry/v2/middleware"
 	"github.com/rookie-ninja/rk-logger"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"gopkg.in/natefinch/lumberjack.v2"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"sync"
 	"time"
 )
 
+// LoggerLevelEnvPrefix is prefix of environment variable which overrides level of LoggerEntry with name
+const LoggerLevelEnvPrefix = "RK_LOG_LEVEL_"
+
 // NewLoggerEntryNoop create zap logger entry with noop.
 func NewLoggerEntryNoop() *LoggerEntry {
 	return &LoggerEntry{
@@ -36,16 +46,59 @@ func NewLoggerEntryStdout() *LoggerEntry {
 		entryName:        "LoggerEntryStdout",
 		entryType:        LoggerEntryType,
 		entryDescription: "Internal RK entry which is used for noop logging with zap.Logger.",
-		Logger:           rklogger.StdoutLogger,
+		Logger:           rklogger.StdoutLogger.WithOptions(zap.WrapCore(wrapSecretCore)),
 		LoggerConfig:     rklogger.StdoutLoggerConfig,
 		LumberjackConfig: nil,
 	}
 }
 
+// LoggerEntryOption option for RegisterLoggerEntry
+type LoggerEntryOption func(*loggerEntryRegOption)
+
+type loggerEntryRegOption struct {
+	allowOverride  bool
+	redactPatterns []string
+	sharedOutput   bool
+}
+
+// WithAllowOverrideLoggerEntry allow LoggerEntry with the same name registered before to be overridden.
+func WithAllowOverrideLoggerEntry() LoggerEntryOption {
+	return func(opt *loggerEntryRegOption) {
+		opt.allowOverride = true
+	}
+}
+
+// WithRedactPatternsLoggerEntry provide regex patterns whose matches in message and string fields
+// will be replaced with *** in every LoggerEntry registered, appended to patterns in boot config.
+func WithRedactPatternsLoggerEntry(patterns ...string) LoggerEntryOption {
+	return func(opt *loggerEntryRegOption) {
+		opt.redactPatterns = append(opt.redactPatterns, patterns...)
+	}
+}
+
+// WithSharedOutputLoggerEntry allow LoggerEntry to write to files in outputs of other LoggerEntry.
+// Entries share a single writer of the same file, rotation config of them must be identical.
+func WithSharedOutputLoggerEntry() LoggerEntryOption {
+	return func(opt *loggerEntryRegOption) {
+		opt.sharedOutput = true
+	}
+}
+
 // RegisterLoggerEntry create event logger entry with options.
-func RegisterLoggerEntry(boot *BootLogger) []*LoggerEntry {
+//
+// Registration will fail if a LoggerEntry with the same name was defined twice for the same domain,
+// or was already registered into GlobalAppCtx, unless WithAllowOverrideLoggerEntry was provided.
+//
+// Registration will also fail if a file in outputs is used by another LoggerEntry, since files rotated
+// independently corrupt each other, unless WithSharedOutputLoggerEntry was provided.
+func RegisterLoggerEntry(boot *BootLogger, opts ...LoggerEntryOption) []*LoggerEntry {
 	res := make([]*LoggerEntry, 0)
 
+	regOpt := &loggerEntryRegOption{}
+	for i := range opts {
+		opts[i](regOpt)
+	}
+
 	// filter out based domain
 	configMap := make(map[string]*BootLoggerE)
 	for _, config := range boot.Logger {
@@ -59,12 +112,18 @@ func RegisterLoggerEntry(boot *BootLogger) []*LoggerEntry {
 
 		// * or matching domain
 		// 1: add it to map if missing
-		if _, ok := configMap[config.Name]; !ok {
+		prev, ok := configMap[config.Name]
+		if !ok {
 			configMap[config.Name] = config
 			continue
 		}
 
-		// 2: already has an entry, then compare domain,
+		// 2: defined twice for the same domain
+		if !regOpt.allowOverride && getDefaultIfEmptyString(prev.Domain, "*") == getDefaultIfEmptyString(config.Domain, "*") {
+			ShutdownWithError(fmt.Errorf("duplicate logger entry, name:%s, domain:%s", config.Name, getDefaultIfEmptyString(config.Domain, "*")))
+		}
+
+		// 3: already has an entry, then compare domain,
 		//    only one case would occur, previous one is already the correct one, continue
 		if config.Domain == "" || config.Domain == "*" {
 			continue
@@ -74,68 +133,36 @@ func RegisterLoggerEntry(boot *BootLogger) []*LoggerEntry {
 	}
 
 	for _, logger := range configMap {
-		entry := &LoggerEntry{
-			entryName:        logger.Name,
-			entryType:        LoggerEntryType,
-			entryDescription: logger.Description,
-			IsDefault:        logger.Default,
-		}
-
-		// Assign default zap config and lumberjack config
-		zapLoggerConfig := rklogger.NewZapStdoutConfig()
-		zapLoggerLumberjackConfig := rklogger.NewLumberjackConfigDefault()
-
-		// Override with user provided zap config and lumberjack config
-		overrideZapConfig(zapLoggerConfig, rklogger.TransformToZapConfig(logger.Zap))
-		overrideLumberjackConfig(zapLoggerLumberjackConfig, logger.Lumberjack)
-
-		// Loki Syncer
-		syncers := make([]zapcore.WriteSyncer, 0)
-		var lokiSyncer *rklogger.LokiSyncer
-		if logger.Loki.Enabled {
-			opts := []rklogger.LokiSyncerOption{
-				rklogger.WithLokiAddr(logger.Loki.Addr),
-				rklogger.WithLokiPath(logger.Loki.Path),
-				rklogger.WithLokiUsername(logger.Loki.Username),
-				rklogger.WithLokiPassword(logger.Loki.Password),
-				rklogger.WithLokiMaxBatchSize(logger.Loki.MaxBatchSize),
-				rklogger.WithLokiMaxBatchWaitMs(time.Duration(logger.Loki.MaxBatchWaitMs) * time.Millisecond),
-			}
-
-			// labels
-			for k, v := range logger.Loki.Labels {
-				opts = append(opts, rklogger.WithLokiLabel(k, v))
-			}
+		// logger with the same name was registered by previous call
+		if !regOpt.allowOverride && GlobalAppCtx.getLoggerEntryStrict(logger.Name) != nil {
+			ShutdownWithError(fmt.Errorf("logger entry is already registered, name:%s", logger.Name))
+		}
+	}
 
-			// default labels
-			opts = append(opts,
-				rklogger.WithLokiLabel(rkmid.Domain.Key, rkmid.Domain.String),
-				rklogger.WithLokiLabel("app_name", GlobalAppCtx.GetAppInfoEntry().AppName),
-				rklogger.WithLokiLabel("app_version", GlobalAppCtx.GetAppInfoEntry().Version),
-				rklogger.WithLokiLabel("logger_type", "zap"),
-			)
-
-			if logger.Loki.InsecureSkipVerify {
-				opts = append(opts, rklogger.WithLokiClientTls(&tls.Config{
-					InsecureSkipVerify: true,
-				}))
-			}
+	// files of entries to be overridden are not taken into account
+	names := make([]string, 0, len(configMap))
+	for name := range configMap {
+		names = append(names, name)
+	}
+	outputs := newLoggerOutputFiles(regOpt.sharedOutput, names...)
 
-			lokiSyncer = rklogger.NewLokiSyncer(opts...)
-			syncers = append(syncers, lokiSyncer)
-		}
+	for _, logger := range configMap {
+		entry := newLoggerEntry(logger, regOpt, outputs)
 
-		// Create app logger with config
-		zapLogger, err := rklogger.NewZapLoggerWithConfAndSyncer(zapLoggerConfig, zapLoggerLumberjackConfig, syncers, zap.AddCaller())
+		// underlying core can be swapped by Reconfigure
+		entry.regOpt = regOpt
+		entry.configRef = logger.ConfigRef
+		entry.swap = newSwapCore(entry.Logger.Core())
+		entry.Logger = entry.Logger.WithOptions(zap.WrapCore(func(zapcore.Core) zapcore.Core {
+			return entry.swap
+		}))
 
-		if err != nil {
+		if err := GlobalAppCtx.SetEntryTimeout(LoggerEntryType, logger.Name, &logger.BootTimeout); err != nil {
+			ShutdownWithError(err)
+		}
+		if err := GlobalAppCtx.SetEntryFailurePolicy(LoggerEntryType, logger.Name, &logger.BootFailurePolicy); err != nil {
 			ShutdownWithError(err)
 		}
-
-		entry.Logger = zapLogger
-		entry.LoggerConfig = zapLoggerConfig
-		entry.LumberjackConfig = zapLoggerLumberjackConfig
-		entry.lokiSyncer = lokiSyncer
 
 		GlobalAppCtx.AddEntry(entry)
 		res = append(res, entry)
@@ -144,6 +171,165 @@ func RegisterLoggerEntry(boot *BootLogger) []*LoggerEntry {
 	return res
 }
 
+// newLoggerEntry creates LoggerEntry with boot config, ShutdownWithError will be called if config is invalid.
+func newLoggerEntry(logger *BootLoggerE, regOpt *loggerEntryRegOption, outputs *loggerOutputFiles) *LoggerEntry {
+	validateBootConfig(logger)
+
+	entry := &LoggerEntry{
+		entryName:        logger.Name,
+		entryType:        LoggerEntryType,
+		entryDescription: logger.Description,
+		IsDefault:        logger.Default,
+	}
+
+	// Assign default zap config and lumberjack config
+	zapLoggerConfig := rklogger.NewZapStdoutConfig()
+	zapLoggerLumberjackConfig := rklogger.NewLumberjackConfigDefault()
+
+	// Override with user provided zap config and lumberjack config
+	overrideZapConfig(zapLoggerConfig, rklogger.TransformToZapConfig(logger.Zap))
+	overrideLumberjackConfig(zapLoggerLumberjackConfig, logger.Lumberjack)
+
+	// Override level with environment variable of RK_LOG_LEVEL_<name>
+	overrideLoggerLevelFromEnv(logger.Name, zapLoggerConfig)
+
+	// Rename fields in encoder config
+	if err := overrideLoggerFieldNames(logger.Name, zapLoggerConfig, logger.FieldNames); err != nil {
+		ShutdownWithError(err)
+	}
+
+	// Validate encoding
+	switch zapLoggerConfig.Encoding {
+	case "", rklogger.EncodingConsole, rklogger.EncodingJson, LoggerEncodingLogfmt:
+	default:
+		ShutdownWithError(fmt.Errorf("invalid encoding of logger entry, name:%s, encoding:%s",
+			logger.Name, zapLoggerConfig.Encoding))
+	}
+
+	// Loki Syncer
+	syncers := make([]zapcore.WriteSyncer, 0)
+	var lokiSyncer *rklogger.LokiSyncer
+	if logger.Loki.Enabled {
+		opts := []rklogger.LokiSyncerOption{
+			rklogger.WithLokiAddr(logger.Loki.Addr),
+			rklogger.WithLokiPath(logger.Loki.Path),
+			rklogger.WithLokiUsername(logger.Loki.Username),
+			rklogger.WithLokiPassword(logger.Loki.Password),
+			rklogger.WithLokiMaxBatchSize(logger.Loki.MaxBatchSize),
+			rklogger.WithLokiMaxBatchWaitMs(time.Duration(logger.Loki.MaxBatchWaitMs) * time.Millisecond),
+		}
+
+		// labels
+		for k, v := range logger.Loki.Labels {
+			opts = append(opts, rklogger.WithLokiLabel(k, v))
+		}
+
+		// default labels
+		opts = append(opts,
+			rklogger.WithLokiLabel(rkmid.Domain.Key, rkmid.Domain.String),
+			rklogger.WithLokiLabel("app_name", GlobalAppCtx.GetAppInfoEntry().AppName),
+			rklogger.WithLokiLabel("app_version", GlobalAppCtx.GetAppInfoEntry().Version),
+			rklogger.WithLokiLabel("logger_type", "zap"),
+		)
+
+		if logger.Loki.InsecureSkipVerify {
+			opts = append(opts, rklogger.WithLokiClientTls(&tls.Config{
+				InsecureSkipVerify: true,
+			}))
+		}
+
+		lokiSyncer = rklogger.NewLokiSyncer(opts...)
+		syncers = append(syncers, lokiSyncer)
+	}
+
+	// Files are opened here and removed from output paths of config passed to logger builder,
+	// so that they could be reopened, refer to Reopen
+	buildConfig, files := newFileWriters(logger.Name, zapLoggerConfig, zapLoggerLumberjackConfig, outputs)
+	var bufferedSyncers []*zapcore.BufferedWriteSyncer
+	for i := range files {
+		if !logger.Buffer.Enabled {
+			syncers = append(syncers, zapcore.AddSync(files[i]))
+			continue
+		}
+
+		// Buffer writes to files
+		buffered := &zapcore.BufferedWriteSyncer{
+			WS:            zapcore.AddSync(files[i]),
+			Size:          logger.Buffer.Size,
+			FlushInterval: time.Duration(logger.Buffer.FlushIntervalMs) * time.Millisecond,
+		}
+		bufferedSyncers = append(bufferedSyncers, buffered)
+		syncers = append(syncers, buffered)
+	}
+
+	// Replace core with logfmt encoder, outputs are opened here since logger builder supports console and json only
+	zapOpts := []zap.Option{zap.AddCaller()}
+	if zapLoggerConfig.Encoding == LoggerEncodingLogfmt {
+		var outputSyncers []zapcore.WriteSyncer
+		var err error
+		if buildConfig, outputSyncers, err = newOutputSyncers(buildConfig, zapLoggerLumberjackConfig); err != nil {
+			ShutdownWithError(err)
+		}
+
+		core := zapcore.NewCore(
+			newLogfmtEncoder(zapLoggerConfig.EncoderConfig),
+			zap.CombineWriteSyncers(append(outputSyncers, syncers...)...),
+			zapLoggerConfig.Level)
+		zapOpts = append(zapOpts, zap.WrapCore(func(zapcore.Core) zapcore.Core {
+			return core
+		}))
+	}
+
+	// Route levels with dedicated paths to their own files, other levels keep output paths
+	if len(logger.LevelOutput.Paths) > 0 {
+		levelCores, levelFiles := newLevelOutputCores(logger.Name, zapLoggerConfig, zapLoggerLumberjackConfig, logger.LevelOutput, lokiSyncer, outputs)
+		files = append(files, levelFiles...)
+		zapOpts = append(zapOpts, zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+			return zapcore.NewTee(append([]zapcore.Core{newLevelFilterCore(c, levelCores)}, levelCores.cores()...)...)
+		}))
+	}
+
+	// Create app logger with config
+	zapLogger, err := rklogger.NewZapLoggerWithConfAndSyncer(buildConfig, zapLoggerLumberjackConfig, syncers, zapOpts...)
+
+	if err != nil {
+		ShutdownWithError(err)
+	}
+
+	// redact values matching patterns
+	exprs := append(append([]string{}, logger.Redact.Patterns...), regOpt.redactPatterns...)
+	patterns := make([]*regexp.Regexp, 0, len(exprs))
+	for _, v := range exprs {
+		pattern, err := regexp.Compile(v)
+		if err != nil {
+			ShutdownWithError(fmt.Errorf("invalid redact pattern of logger entry, name:%s, pattern:%s, %v", logger.Name, v, err))
+		}
+		patterns = append(patterns, pattern)
+	}
+
+	// secret values are always redacted, refer to ConfigEntry.MarkSecret
+	zapLogger = zapLogger.WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		return newRedactCore(c, patterns)
+	}))
+
+	// tee logs into EventEntry
+	if logger.Event.Enabled {
+		core := newRedactCore(newLogEventCore(logger.Event), patterns)
+		zapLogger = zapLogger.WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+			return zapcore.NewTee(c, core)
+		}))
+	}
+
+	entry.Logger = zapLogger
+	entry.LoggerConfig = zapLoggerConfig
+	entry.LumberjackConfig = zapLoggerLumberjackConfig
+	entry.lokiSyncer = lokiSyncer
+	entry.bufferedSyncers = bufferedSyncers
+	entry.files = files
+
+	return entry
+}
+
 // RegisterLoggerEntryYAML register function
 func RegisterLoggerEntryYAML(raw []byte) map[string]Entry {
 	boot := &BootLogger{}
@@ -167,26 +353,109 @@ type BootLogger struct {
 
 // BootLoggerE bootstrap element of LoggerEntry
 type BootLoggerE struct {
-	Name        string                  `yaml:"name" json:"name"`
-	Description string                  `yaml:"description" json:"description"`
-	Domain      string                  `yaml:"domain" json:"domain"`
-	Default     bool                    `yaml:"default" json:"default"`
-	Zap         *rklogger.ZapConfigWrap `yaml:"zap" json:"zap"`
-	Lumberjack  *lumberjack.Logger      `yaml:"lumberjack" json:"lumberjack"`
-	Loki        BootLoki                `yaml:"loki" json:"loki"`
+	Name              string                  `yaml:"name" json:"name"`
+	Description       string                  `yaml:"description" json:"description"`
+	Domain            string                  `yaml:"domain" json:"domain"`
+	Default           bool                    `yaml:"default" json:"default"`
+	Zap               *rklogger.ZapConfigWrap `yaml:"zap" json:"zap"`
+	Lumberjack        *lumberjack.Logger      `yaml:"lumberjack" json:"lumberjack"`
+	Loki              BootLoki                `yaml:"loki" json:"loki"`
+	Event             BootLoggerEvent         `yaml:"event" json:"event"`
+	Redact            BootLoggerRedact        `yaml:"redact" json:"redact"`
+	Buffer            BootLoggerBuffer        `yaml:"buffer" json:"buffer"`
+	LevelOutput       BootLoggerLevelOutput   `yaml:"levelOutput" json:"levelOutput"`
+	FieldNames        BootLoggerFieldNames    `yaml:"fieldNames" json:"fieldNames"`
+	ConfigRef         BootLoggerConfigRef     `yaml:"configRef" json:"configRef"`
+	BootTimeout       `yaml:",inline" json:",inline" mapstructure:",squash"`
+	BootFailurePolicy `yaml:",inline" json:",inline" mapstructure:",squash"`
+}
+
+// BootLoggerLevelOutput bootstrap config of routing levels into separate outputs.
+//
+// Paths is keyed by level like error, logs of the level are written to its paths only,
+// levels missing in Paths are written to output paths of zap config.
+// Files are rotated with lumberjack config and are not buffered.
+type BootLoggerLevelOutput struct {
+	Paths map[string][]string `yaml:"paths" json:"paths"`
+}
+
+// BootLoggerFieldNames bootstrap config of renaming fields of time, level, message and caller in logs.
+//
+// Names override keys in encoder config of zap, fields keep names of zap config if missing.
+// Names must not be blank and must be unique among keys of encoder config.
+//
+//	logger:
+//	  - name: my-logger
+//	    fieldNames:
+//	      time: "@timestamp"
+//	      level: severity
+type BootLoggerFieldNames struct {
+	Time    string `yaml:"time" json:"time"`
+	Level   string `yaml:"level" json:"level"`
+	Message string `yaml:"message" json:"message"`
+	Caller  string `yaml:"caller" json:"caller"`
+}
+
+// BootLoggerBuffer bootstrap config of buffering writes to files in output paths.
+//
+// Buffer is flushed when it is full, every FlushIntervalMs, and on Sync or Interrupt of LoggerEntry.
+// Logs in buffer will be lost if process crashed or exited without Sync, which is at most Size bytes
+// or FlushIntervalMs of logs. 256KB and 30 seconds are used by default.
+type BootLoggerBuffer struct {
+	Enabled         bool  `yaml:"enabled" json:"enabled"`
+	Size            int   `yaml:"size" json:"size"`
+	FlushIntervalMs int64 `yaml:"flushIntervalMs" json:"flushIntervalMs"`
+}
+
+// BootLoggerConfigRef bootstrap config of sourcing settings of LoggerEntry from keys of ConfigEntry.
+//
+// Keys under Prefix of ConfigEntry with name of Entry are decoded as settings of logger like boot config,
+// whole config is used if Prefix is empty. Settings replace the ones in boot config at Bootstrap except
+// name, description and default, and logger is reconfigured once keys changed while ConfigEntry reloaded.
+//
+//	logger:
+//	  - name: my-logger
+//	    configRef:
+//	      entry: my-config
+//	      prefix: log
+type BootLoggerConfigRef struct {
+	Entry  string `yaml:"entry" json:"entry"`
+	Prefix string `yaml:"prefix" json:"prefix"`
+}
+
+// BootLoggerRedact bootstrap config of redacting values matching regex patterns in message and string fields.
+type BootLoggerRedact struct {
+	Patterns []string `yaml:"patterns" json:"patterns"`
+}
+
+// BootLoggerEvent bootstrap config of teeing logs into EventEntry.
+//
+// Logs with level above or equal to Level will be recorded as events of EventEntry with EntryName,
+// default EventEntry will be used if EntryName is empty or missing.
+type BootLoggerEvent struct {
+	Enabled   bool   `yaml:"enabled" json:"enabled"`
+	EntryName string `yaml:"entryName" json:"entryName"`
+	Level     string `yaml:"level" json:"level" validate:"omitempty,oneofci=debug info warn error dpanic panic fatal"`
 }
 
 // LoggerEntry contains bellow fields.
 type LoggerEntry struct {
 	*zap.Logger
-	entryName        string               `yaml:"-" json:"-"`
-	entryType        string               `yaml:"-" json:"-"`
-	entryDescription string               `yaml:"-" json:"-"`
-	IsDefault        bool                 `yaml:"-" json:"-"`
-	LoggerConfig     *zap.Config          `yaml:"-" json:"-"`
-	LumberjackConfig *lumberjack.Logger   `yaml:"-" json:"-"`
-	lokiSyncer       *rklogger.LokiSyncer `yaml:"-" json:"-"`
-	bootstrapOnce    sync.Once            `yaml:"-" json:"-"`
+	entryName        string                         `yaml:"-" json:"-"`
+	entryType        string                         `yaml:"-" json:"-"`
+	entryDescription string                         `yaml:"-" json:"-"`
+	IsDefault        bool                           `yaml:"-" json:"-"`
+	LoggerConfig     *zap.Config                    `yaml:"-" json:"-"`
+	LumberjackConfig *lumberjack.Logger             `yaml:"-" json:"-"`
+	lokiSyncer       *rklogger.LokiSyncer           `yaml:"-" json:"-"`
+	bootstrapOnce    sync.Once                      `yaml:"-" json:"-"`
+	bufferedSyncers  []*zapcore.BufferedWriteSyncer `yaml:"-" json:"-"`
+	regOpt           *loggerEntryRegOption          `yaml:"-" json:"-"`
+	swap             *swapCore                      `yaml:"-" json:"-"`
+	reconfigureLock  sync.Mutex                     `yaml:"-" json:"-"`
+	files            []*lumberjack.Logger           `yaml:"-" json:"-"`
+	configRef        BootLoggerConfigRef            `yaml:"-" json:"-"`
+	configRefSum     string                         `yaml:"-" json:"-"`
 }
 
 // Bootstrap entry.
@@ -195,14 +464,39 @@ func (entry *LoggerEntry) Bootstrap(ctx context.Context) {
 		if entry.lokiSyncer != nil {
 			entry.lokiSyncer.Bootstrap(ctx)
 		}
+
+		if len(entry.configRef.Entry) < 1 {
+			return
+		}
+
+		if err := entry.applyConfigRef(); err != nil {
+			ShutdownWithError(err)
+		}
+
+		GlobalAppCtx.GetConfigEntry(entry.configRef.Entry).OnChange(func(context.Context, *ConfigEntry) {
+			if err := entry.applyConfigRef(); err != nil {
+				LoggerEntryStdout.Warn("Failed to reconfigure logger with changed config",
+					zap.String("loggerEntry", entry.entryName),
+					zap.String("configEntry", entry.configRef.Entry),
+					zap.Error(err))
+			}
+		})
 	})
 }
 
 // Interrupt entry.
 func (entry *LoggerEntry) Interrupt(ctx context.Context) {
+	entry.reconfigureLock.Lock()
+	defer entry.reconfigureLock.Unlock()
+
 	if entry.lokiSyncer != nil {
 		entry.lokiSyncer.Interrupt(ctx)
 	}
+
+	// flush buffered logs
+	for i := range entry.bufferedSyncers {
+		entry.bufferedSyncers[i].Sync()
+	}
 }
 
 // GetName returns name of entry.
@@ -272,9 +566,820 @@ func (entry *LoggerEntry) AddLabelToLokiSyncer(k, v string) {
 	}
 }
 
+// Reconfigure rebuilds underlying core of logger with new config and swaps it in place.
+//
+// Loggers derived from entry.Logger with With() follow the new core as well.
+// Logs written before swapping are flushed into outputs of old config, nothing is dropped.
+// Name of entry can not be changed, error will be returned if config is invalid.
+func (entry *LoggerEntry) Reconfigure(newConfig *BootLoggerE) (err error) {
+	if entry.swap == nil {
+		return fmt.Errorf("logger entry is not reconfigurable, name:%s", entry.entryName)
+	}
+	if newConfig == nil {
+		return fmt.Errorf("nil config of logger entry, name:%s", entry.entryName)
+	}
+
+	entry.reconfigureLock.Lock()
+	defer entry.reconfigureLock.Unlock()
+
+	config := *newConfig
+	config.Name = entry.entryName
+
+	// newLoggerEntry calls ShutdownWithError which panics with invalid config
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("failed to reconfigure logger entry, name:%s, %v", entry.entryName, recovered)
+		}
+	}()
+
+	next := newLoggerEntry(&config, entry.regOpt, newLoggerOutputFiles(entry.regOpt.sharedOutput, entry.entryName))
+	if next.lokiSyncer != nil {
+		next.lokiSyncer.Bootstrap(context.Background())
+	}
+
+	// in-flight writes finish before swap returns, flush them into old outputs
+	prev := entry.swap.swap(next.Logger.Core())
+	prev.Sync()
+	for i := range entry.bufferedSyncers {
+		entry.bufferedSyncers[i].Stop()
+	}
+	for i := range entry.files {
+		entry.files[i].Close()
+	}
+	if entry.lokiSyncer != nil {
+		entry.lokiSyncer.Interrupt(context.Background())
+	}
+
+	entry.IsDefault = next.IsDefault
+	entry.entryDescription = next.entryDescription
+	entry.LoggerConfig = next.LoggerConfig
+	entry.LumberjackConfig = next.LumberjackConfig
+	entry.lokiSyncer = next.lokiSyncer
+	entry.bufferedSyncers = next.bufferedSyncers
+	entry.files = next.files
+
+	return nil
+}
+
+// applyConfigRef reconfigure entry with settings in ConfigEntry referenced by boot config,
+// nothing happens if settings didn't change since last applied.
+func (entry *LoggerEntry) applyConfigRef() error {
+	ref := entry.configRef
+
+	config := GlobalAppCtx.GetConfigEntry(ref.Entry)
+	if config == nil {
+		return fmt.Errorf("config entry referenced by logger entry is missing, name:%s, configEntry:%s", entry.entryName, ref.Entry)
+	}
+
+	var raw interface{} = config.AllSettings()
+	if len(ref.Prefix) > 0 {
+		raw = config.Get(ref.Prefix)
+	}
+	bytes, err := json.Marshal(raw)
+	if err != nil {
+		return fmt.Errorf("failed to read config of logger entry, name:%s, %v", entry.entryName, err)
+	}
+	sum := fmt.Sprintf("%x", sha256.Sum256(bytes))
+	if sum == entry.configRefSum {
+		return nil
+	}
+
+	boot := &BootLoggerE{}
+	if err := config.Unmarshal(ref.Prefix, boot); err != nil {
+		return err
+	}
+	boot.Description = entry.entryDescription
+	boot.Default = entry.IsDefault
+
+	if err := entry.Reconfigure(boot); err != nil {
+		return err
+	}
+	entry.configRefSum = sum
+
+	return nil
+}
+
+// Reopen closes files in outputs of entry after flushing buffered logs, files will be opened again
+// at next write. Call it after files were moved by external rotation tools like logrotate.
+func (entry *LoggerEntry) Reopen() error {
+	entry.reconfigureLock.Lock()
+	defer entry.reconfigureLock.Unlock()
+
+	for i := range entry.bufferedSyncers {
+		entry.bufferedSyncers[i].Sync()
+	}
+
+	errs := make([]string, 0)
+	for i := range entry.files {
+		if err := entry.files[i].Close(); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", entry.files[i].Filename, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to reopen files of logger entry, name:%s, [%s]", entry.entryName, strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// GetLevel returns current level of entry, error will be returned if level is not changeable at runtime.
+func (entry *LoggerEntry) GetLevel() (zapcore.Level, error) {
+	entry.reconfigureLock.Lock()
+	defer entry.reconfigureLock.Unlock()
+
+	if entry.LoggerConfig == nil || entry.LoggerConfig.Level == (zap.AtomicLevel{}) {
+		return zapcore.InfoLevel, fmt.Errorf("level of logger entry is not changeable, name:%s", entry.entryName)
+	}
+
+	return entry.LoggerConfig.Level.Level(), nil
+}
+
+// SetLevel changes level of entry at runtime, other entries are not affected.
+// Loggers derived from entry.Logger with With() follow the new level as well.
+func (entry *LoggerEntry) SetLevel(level zapcore.Level) error {
+	entry.reconfigureLock.Lock()
+	defer entry.reconfigureLock.Unlock()
+
+	if entry.LoggerConfig == nil || entry.LoggerConfig.Level == (zap.AtomicLevel{}) {
+		return fmt.Errorf("level of logger entry is not changeable, name:%s", entry.entryName)
+	}
+
+	entry.LoggerConfig.Level.SetLevel(level)
+	return nil
+}
+
 // Sync underlying logger
 func (entry *LoggerEntry) Sync() {
 	if entry.Logger != nil {
 		entry.Logger.Sync()
 	}
 }
+
+// overrideLoggerLevelFromEnv override level of zap config with environment variable of LoggerLevelEnvPrefix and name.
+//
+// Name is looked up as it is first, then upper-cased, like RK_LOG_LEVEL_dbLogger and RK_LOG_LEVEL_DBLOGGER.
+// Invalid level will be ignored with a warning.
+func overrideLoggerLevelFromEnv(name string, config *zap.Config) {
+	key := LoggerLevelEnvPrefix + name
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		key = LoggerLevelEnvPrefix + strings.ToUpper(name)
+		if value, ok = os.LookupEnv(key); !ok {
+			return
+		}
+	}
+
+	level := zapcore.InfoLevel
+	if err := level.UnmarshalText([]byte(strings.ToLower(value))); err != nil {
+		LoggerEntryStdout.Warn("Invalid logger level in environment variable, ignoring",
+			zap.String("loggerEntry", name),
+			zap.String("env", key),
+			zap.String("level", value))
+		return
+	}
+
+	LoggerEntryStdout.Info("Override logger level with environment variable",
+		zap.String("loggerEntry", name),
+		zap.String("env", key),
+		zap.String("from", config.Level.String()),
+		zap.String("to", level.String()))
+	config.Level = zap.NewAtomicLevelAt(level)
+}
+
+// overrideLoggerFieldNames overrides keys in encoder config with field names,
+// error will be returned if names are blank or keys conflict with each other.
+func overrideLoggerFieldNames(name string, config *zap.Config, names BootLoggerFieldNames) error {
+	overrides := []struct {
+		field string
+		value string
+		key   *string
+	}{
+		{"time", names.Time, &config.EncoderConfig.TimeKey},
+		{"level", names.Level, &config.EncoderConfig.LevelKey},
+		{"message", names.Message, &config.EncoderConfig.MessageKey},
+		{"caller", names.Caller, &config.EncoderConfig.CallerKey},
+	}
+
+	overridden := false
+	for _, v := range overrides {
+		if len(v.value) < 1 {
+			continue
+		}
+		if len(strings.TrimSpace(v.value)) < 1 {
+			return fmt.Errorf("blank field name of logger entry, name:%s, field:%s", name, v.field)
+		}
+		*v.key = v.value
+		overridden = true
+	}
+
+	if !overridden {
+		return nil
+	}
+
+	enc := config.EncoderConfig
+	keys := []string{enc.TimeKey, enc.LevelKey, enc.NameKey, enc.CallerKey, enc.FunctionKey, enc.MessageKey, enc.StacktraceKey}
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if len(key) < 1 {
+			continue
+		}
+		if seen[key] {
+			return fmt.Errorf("duplicate field name of logger entry, name:%s, key:%s", name, key)
+		}
+		seen[key] = true
+	}
+
+	return nil
+}
+
+// newFileWriters opens lumberjack for files in output paths with outputs, returns copy of config without files in output paths.
+func newFileWriters(name string, config *zap.Config, lumber *lumberjack.Logger, outputs *loggerOutputFiles) (*zap.Config, []*lumberjack.Logger) {
+	res := *config
+	res.OutputPaths = make([]string, 0)
+	files := make([]*lumberjack.Logger, 0)
+
+	for _, p := range config.OutputPaths {
+		if p == "stdout" || p == "stderr" {
+			res.OutputPaths = append(res.OutputPaths, p)
+			continue
+		}
+
+		files = append(files, outputs.open(name, p, lumber))
+	}
+
+	return &res, files
+}
+
+// newOutputSyncers opens stdout, stderr and lumberjack of files in output paths,
+// returns copy of config without output paths.
+func newOutputSyncers(config *zap.Config, lumber *lumberjack.Logger) (*zap.Config, []zapcore.WriteSyncer, error) {
+	res := *config
+	res.OutputPaths = make([]string, 0)
+	syncers := make([]zapcore.WriteSyncer, 0)
+
+	for _, p := range config.OutputPaths {
+		if p == "stdout" || p == "stderr" {
+			sink, _, err := zap.Open(p)
+			if err != nil {
+				return nil, nil, err
+			}
+			syncers = append(syncers, sink)
+			continue
+		}
+
+		syncers = append(syncers, zapcore.AddSync(newLumberjackOfPath(p, lumber)))
+	}
+
+	return &res, syncers, nil
+}
+
+// levelOutputCores is cores of levels routed to dedicated outputs
+type levelOutputCores map[zapcore.Level]zapcore.Core
+
+// cores returns cores in order of level
+func (c levelOutputCores) cores() []zapcore.Core {
+	res := make([]zapcore.Core, 0, len(c))
+	for lvl := zapcore.DebugLevel; lvl <= zapcore.FatalLevel; lvl++ {
+		if core, ok := c[lvl]; ok {
+			res = append(res, core)
+		}
+	}
+
+	return res
+}
+
+// newLevelOutputCores creates a core for each level in boot config, files are checked for writability.
+// Loki syncer receives logs of all levels. Files opened by cores are returned as well.
+func newLevelOutputCores(name string, config *zap.Config, lumber *lumberjack.Logger, boot BootLoggerLevelOutput, loki *rklogger.LokiSyncer, outputs *loggerOutputFiles) (levelOutputCores, []*lumberjack.Logger) {
+	res := make(levelOutputCores)
+	files := make([]*lumberjack.Logger, 0)
+
+	for k, paths := range boot.Paths {
+		var lvl zapcore.Level
+		if err := lvl.UnmarshalText([]byte(k)); err != nil {
+			ShutdownWithError(fmt.Errorf("invalid level of level output, name:%s, level:%s", name, k))
+		}
+
+		for _, p := range paths {
+			if err := checkFileWritable(p); err != nil {
+				ShutdownWithError(fmt.Errorf("level output is not writable, name:%s, level:%s, path:%s, %v", name, k, p, err))
+			}
+		}
+
+		outputConfig, levelFiles := newFileWriters(name, &zap.Config{OutputPaths: paths}, lumber, outputs)
+		_, syncers, err := newOutputSyncers(outputConfig, lumber)
+		if err != nil {
+			ShutdownWithError(err)
+		}
+		for i := range levelFiles {
+			syncers = append(syncers, zapcore.AddSync(levelFiles[i]))
+		}
+		files = append(files, levelFiles...)
+		if loki != nil {
+			syncers = append(syncers, loki)
+		}
+
+		enabler := config.Level
+		target := lvl
+		res[lvl] = zapcore.NewCore(
+			newEncoderOfConfig(config),
+			zap.CombineWriteSyncers(syncers...),
+			zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+				return l == target && enabler.Enabled(l)
+			}))
+	}
+
+	return res, files
+}
+
+// checkFileWritable creates parent directory and opens file in append mode, stdout and stderr are skipped
+func checkFileWritable(filePath string) error {
+	if filePath == "stdout" || filePath == "stderr" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(filePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+// newEncoderOfConfig creates encoder with encoding and encoder config of zap config
+func newEncoderOfConfig(config *zap.Config) zapcore.Encoder {
+	switch config.Encoding {
+	case rklogger.EncodingJson:
+		return zapcore.NewJSONEncoder(config.EncoderConfig)
+	case LoggerEncodingLogfmt:
+		return newLogfmtEncoder(config.EncoderConfig)
+	default:
+		return zapcore.NewConsoleEncoder(config.EncoderConfig)
+	}
+}
+
+// levelFilterCore is a zapcore.Core which drops levels routed to dedicated outputs
+type levelFilterCore struct {
+	zapcore.Core
+	excluded levelOutputCores
+}
+
+// newLevelFilterCore wraps core and drops levels in excluded
+func newLevelFilterCore(core zapcore.Core, excluded levelOutputCores) *levelFilterCore {
+	return &levelFilterCore{
+		Core:     core,
+		excluded: excluded,
+	}
+}
+
+// Enabled returns false for excluded levels
+func (core *levelFilterCore) Enabled(lvl zapcore.Level) bool {
+	if _, ok := core.excluded[lvl]; ok {
+		return false
+	}
+
+	return core.Core.Enabled(lvl)
+}
+
+// With returns a copy of core with fields
+func (core *levelFilterCore) With(fields []zapcore.Field) zapcore.Core {
+	return newLevelFilterCore(core.Core.With(fields), core.excluded)
+}
+
+// Check drops excluded levels and delegates to underlying core
+func (core *levelFilterCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if _, ok := core.excluded[ent.Level]; ok {
+		return ce
+	}
+
+	return core.Core.Check(ent, ce)
+}
+
+// newLumberjackOfPath creates lumberjack with file path and rotation config of lumber
+func newLumberjackOfPath(filePath string, lumber *lumberjack.Logger) *lumberjack.Logger {
+	return &lumberjack.Logger{
+		Filename:   filePath,
+		MaxAge:     lumber.MaxAge,
+		MaxBackups: lumber.MaxBackups,
+		MaxSize:    lumber.MaxSize,
+		Compress:   lumber.Compress,
+		LocalTime:  lumber.LocalTime,
+	}
+}
+
+// loggerOutputFiles opens lumberjack of files in outputs of LoggerEntry, file used by multiple paths
+// of an entry is opened once, file used by other entries is shared only if allowed.
+type loggerOutputFiles struct {
+	shared bool
+	owners map[string]string
+	files  map[string]*lumberjack.Logger
+}
+
+// newLoggerOutputFiles creates loggerOutputFiles with files of LoggerEntry in GlobalAppCtx except excluded names
+func newLoggerOutputFiles(shared bool, excluded ...string) *loggerOutputFiles {
+	res := &loggerOutputFiles{
+		shared: shared,
+		owners: make(map[string]string),
+		files:  make(map[string]*lumberjack.Logger),
+	}
+
+	skip := make(map[string]bool)
+	for i := range excluded {
+		skip[excluded[i]] = true
+	}
+
+	for name, v := range GlobalAppCtx.ListEntriesByType(LoggerEntryType) {
+		entry, ok := v.(*LoggerEntry)
+		if !ok || skip[name] {
+			continue
+		}
+		for _, file := range entry.files {
+			path := absLoggerFilePath(file.Filename)
+			if _, ok := res.files[path]; !ok {
+				res.owners[path] = name
+				res.files[path] = file
+			}
+		}
+	}
+
+	return res
+}
+
+// open returns lumberjack of file path for entry, ShutdownWithError will be called if file is used by
+// another entry while sharing is not allowed, or rotation config is different.
+func (f *loggerOutputFiles) open(name, filePath string, lumber *lumberjack.Logger) *lumberjack.Logger {
+	path := absLoggerFilePath(filePath)
+
+	file, ok := f.files[path]
+	if !ok {
+		file = newLumberjackOfPath(filePath, lumber)
+		f.owners[path] = name
+		f.files[path] = file
+		return file
+	}
+
+	owner := f.owners[path]
+	if owner != name && !f.shared {
+		ShutdownWithError(fmt.Errorf("output file is shared by logger entries, path:%s, entries:[%s, %s], "+
+			"provide WithSharedOutputLoggerEntry to share a single writer", path, owner, name))
+	}
+
+	if file.MaxAge != lumber.MaxAge || file.MaxBackups != lumber.MaxBackups || file.MaxSize != lumber.MaxSize ||
+		file.Compress != lumber.Compress || file.LocalTime != lumber.LocalTime {
+		ShutdownWithError(fmt.Errorf("output file is shared with different rotation config, path:%s, entries:[%s, %s]",
+			path, owner, name))
+	}
+
+	return file
+}
+
+// absLoggerFilePath returns absolute and cleaned path of file, path itself is returned if failed
+func absLoggerFilePath(filePath string) string {
+	if res, err := filepath.Abs(filePath); err == nil {
+		return res
+	}
+
+	return filepath.Clean(filePath)
+}
+
+// logEventCore is a zapcore.Core which records logs as events of EventEntry
+type logEventCore struct {
+	zapcore.LevelEnabler
+	eventEntryName string
+	fields         []zapcore.Field
+}
+
+// newLogEventCore creates logEventCore, warn level is used by default
+func newLogEventCore(boot BootLoggerEvent) *logEventCore {
+	level := zapcore.WarnLevel
+	if len(boot.Level) > 0 {
+		level.UnmarshalText([]byte(strings.ToLower(boot.Level)))
+	}
+
+	return &logEventCore{
+		LevelEnabler:   level,
+		eventEntryName: boot.EntryName,
+		fields:         make([]zapcore.Field, 0),
+	}
+}
+
+// With returns a copy of core with fields
+func (core *logEventCore) With(fields []zapcore.Field) zapcore.Core {
+	res := &logEventCore{
+		LevelEnabler:   core.LevelEnabler,
+		eventEntryName: core.eventEntryName,
+		fields:         make([]zapcore.Field, 0, len(core.fields)+len(fields)),
+	}
+	res.fields = append(res.fields, core.fields...)
+	res.fields = append(res.fields, fields...)
+
+	return res
+}
+
+// Check adds core if level is enabled
+func (core *logEventCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if core.Enabled(ent.Level) {
+		return ce.AddCore(ent, core)
+	}
+
+	return ce
+}
+
+// Write records log as event with message and fields as pairs
+func (core *logEventCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	eventEntry := GlobalAppCtx.GetEventEntry(core.eventEntryName)
+	if eventEntry == nil {
+		eventEntry = GlobalAppCtx.GetEventEntryDefault()
+	}
+
+	enc := zapcore.NewMapObjectEncoder()
+	for i := range core.fields {
+		core.fields[i].AddTo(enc)
+	}
+	for i := range fields {
+		fields[i].AddTo(enc)
+	}
+
+	event := eventEntry.Start("log")
+	event.AddPair("level", ent.Level.String())
+	event.AddPair("message", ent.Message)
+	if len(ent.LoggerName) > 0 {
+		event.AddPair("logger", ent.LoggerName)
+	}
+	if ent.Caller.Defined {
+		event.AddPair("caller", ent.Caller.TrimmedPath())
+	}
+	for k, v := range enc.Fields {
+		event.AddPair(k, fmt.Sprintf("%v", v))
+	}
+	if ent.Level >= zapcore.ErrorLevel {
+		event.AddErr(errors.New(ent.Message))
+	}
+	eventEntry.Finish(event)
+
+	return nil
+}
+
+// Sync is a noop, events are flushed by EventEntry
+func (core *logEventCore) Sync() error {
+	return nil
+}
+
+// redactValue is replacement of values matching redact patterns
+const redactValue = "***"
+
+// minSecretLength is min length of secret values to redact, shorter values would redact unrelated text
+const minSecretLength = 4
+
+// secretValues keeps resolved values of config keys marked as secret, they are redacted by every redactCore
+var secretValues = &secretRegistry{
+	values: make(map[string]string),
+}
+
+// secretRegistry is known secret values with key of source
+type secretRegistry struct {
+	lock   sync.RWMutex
+	values map[string]string
+}
+
+// set value of source, value shorter than minSecretLength will be removed
+func (r *secretRegistry) set(source, value string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if len(value) < minSecretLength {
+		delete(r.values, source)
+		return
+	}
+
+	r.values[source] = value
+}
+
+// clear removes all values
+func (r *secretRegistry) clear() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.values = make(map[string]string)
+}
+
+// len returns count of values
+func (r *secretRegistry) len() int {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return len(r.values)
+}
+
+// redact replaces secret values in value
+func (r *secretRegistry) redact(value string) string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	for _, v := range r.values {
+		value = strings.ReplaceAll(value, v, redactValue)
+	}
+
+	return value
+}
+
+// wrapSecretCore wraps core with redactCore which redacts secret values only
+func wrapSecretCore(core zapcore.Core) zapcore.Core {
+	return newRedactCore(core, nil)
+}
+
+// redactCore is a zapcore.Core which replaces values matching patterns and secret values in message and fields before writing
+type redactCore struct {
+	zapcore.Core
+	patterns []*regexp.Regexp
+}
+
+// newRedactCore wraps core with redact patterns
+func newRedactCore(core zapcore.Core, patterns []*regexp.Regexp) *redactCore {
+	return &redactCore{
+		Core:     core,
+		patterns: patterns,
+	}
+}
+
+// With returns a copy of core with redacted fields
+func (core *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return newRedactCore(core.Core.With(core.redactFields(fields)), core.patterns)
+}
+
+// skip returns true if there is nothing to redact
+func (core *redactCore) skip() bool {
+	return len(core.patterns) < 1 && secretValues.len() < 1
+}
+
+// Check adds core if level is enabled
+func (core *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if core.Enabled(ent.Level) {
+		return ce.AddCore(ent, core)
+	}
+
+	return ce
+}
+
+// Write redacts message and fields, then write to underlying core
+func (core *redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if core.skip() {
+		return core.write(ent, fields)
+	}
+
+	ent.Message = core.redact(ent.Message)
+	return core.write(ent, core.redactFields(fields))
+}
+
+// write checks entry with underlying core again, so that cores of tee only write levels they enabled
+func (core *redactCore) write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if ce := core.Core.Check(ent, nil); ce != nil {
+		ce.Write(fields...)
+	}
+
+	return nil
+}
+
+// redact replaces matches of patterns and secret values in value
+func (core *redactCore) redact(value string) string {
+	for i := range core.patterns {
+		value = core.patterns[i].ReplaceAllString(value, redactValue)
+	}
+
+	return secretValues.redact(value)
+}
+
+// redactFields redacts string, byte string, error and stringer fields, other fields are kept as they are
+func (core *redactCore) redactFields(fields []zapcore.Field) []zapcore.Field {
+	if core.skip() {
+		return fields
+	}
+
+	res := make([]zapcore.Field, len(fields))
+	for i, field := range fields {
+		switch field.Type {
+		case zapcore.StringType:
+			field.String = core.redact(field.String)
+		case zapcore.ByteStringType:
+			if v, ok := field.Interface.([]byte); ok {
+				field.Interface = []byte(core.redact(string(v)))
+			}
+		case zapcore.ErrorType:
+			if v, ok := field.Interface.(error); ok {
+				field = zap.String(field.Key, core.redact(v.Error()))
+			}
+		case zapcore.StringerType:
+			if v, ok := field.Interface.(fmt.Stringer); ok {
+				field = zap.String(field.Key, core.redact(v.String()))
+			}
+		}
+		res[i] = field
+	}
+
+	return res
+}
+
+// swapState is shared by swapCore and cores derived from it with With()
+type swapState struct {
+	lock sync.RWMutex
+	core zapcore.Core
+	gen  uint64
+}
+
+// swapCore is a zapcore.Core whose underlying core can be replaced at runtime, refer to LoggerEntry.Reconfigure
+type swapCore struct {
+	state  *swapState
+	fields []zapcore.Field
+
+	// underlying core with fields, rebuilt after swap
+	lock    sync.Mutex
+	derived zapcore.Core
+	gen     uint64
+}
+
+// newSwapCore wraps core with swapCore
+func newSwapCore(core zapcore.Core) *swapCore {
+	return &swapCore{
+		state:   &swapState{core: core},
+		derived: core,
+	}
+}
+
+// swap replaces underlying core and returns previous one, it waits for in-flight writes
+func (core *swapCore) swap(next zapcore.Core) zapcore.Core {
+	core.state.lock.Lock()
+	defer core.state.lock.Unlock()
+
+	prev := core.state.core
+	core.state.core = next
+	core.state.gen++
+
+	return prev
+}
+
+// current returns underlying core with fields, caller must hold read lock of state
+func (core *swapCore) current() zapcore.Core {
+	if len(core.fields) < 1 {
+		return core.state.core
+	}
+
+	core.lock.Lock()
+	defer core.lock.Unlock()
+
+	if core.derived == nil || core.gen != core.state.gen {
+		core.derived = core.state.core.With(core.fields)
+		core.gen = core.state.gen
+	}
+
+	return core.derived
+}
+
+// Enabled checks level with underlying core
+func (core *swapCore) Enabled(level zapcore.Level) bool {
+	core.state.lock.RLock()
+	defer core.state.lock.RUnlock()
+
+	return core.current().Enabled(level)
+}
+
+// With returns a copy of core with fields which follows swap
+func (core *swapCore) With(fields []zapcore.Field) zapcore.Core {
+	return &swapCore{
+		state:  core.state,
+		fields: append(append(make([]zapcore.Field, 0, len(core.fields)+len(fields)), core.fields...), fields...),
+	}
+}
+
+// Check adds core if level is enabled
+func (core *swapCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if core.Enabled(ent.Level) {
+		return ce.AddCore(ent, core)
+	}
+
+	return ce
+}
+
+// Write checks entry with current underlying core again and writes to it
+func (core *swapCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	core.state.lock.RLock()
+	defer core.state.lock.RUnlock()
+
+	if ce := core.current().Check(ent, nil); ce != nil {
+		ce.Write(fields...)
+	}
+
+	return nil
+}
+
+// Sync flushes current underlying core
+func (core *swapCore) Sync() error {
+	core.state.lock.RLock()
+	defer core.state.lock.RUnlock()
+
+	return core.state.core.Sync()
+}
@@ -6,10 +6,20 @@
 package rkentry
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"github.com/rookie-ninja/rk-logger"
+	"github.com/rookie-ninja/rk-query"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestNewLoggerEntryNoop(t *testing.T) {
@@ -29,6 +39,8 @@ func TestNewLoggerEntryStdout(t *testing.T) {
 }
 
 func TestRegisterLoggerEntry(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(LoggerEntryType)
+
 	entries := RegisterLoggerEntry(&BootLogger{
 		Logger: []*BootLoggerE{
 			{
@@ -89,6 +101,7 @@ func TestZapEntry_Bootstrap_Interrupt(t *testing.T) {
 
 func TestLoggerEntry_Syncer(t *testing.T) {
 	defer assertNotPanic(t)
+	defer GlobalAppCtx.RemoveEntryByType(LoggerEntryType)
 
 	entries := RegisterLoggerEntry(&BootLogger{
 		Logger: []*BootLoggerE{
@@ -111,3 +124,680 @@ func TestLoggerEntry_Syncer(t *testing.T) {
 	entries[0].AddLabelToLokiSyncer("key", "value")
 	entries[0].Sync()
 }
+
+func TestRegisterLoggerEntry_WithDuplicateInOneConfig(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(LoggerEntryType)
+	defer assertPanic(t)
+
+	RegisterLoggerEntry(&BootLogger{
+		Logger: []*BootLoggerE{
+			{Name: "ut-logger"},
+			{Name: "ut-logger", Domain: "*"},
+		},
+	})
+}
+
+func TestRegisterLoggerEntry_WithDuplicateRegistered(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(LoggerEntryType)
+
+	boot := &BootLogger{
+		Logger: []*BootLoggerE{
+			{Name: "ut-logger"},
+		},
+	}
+
+	assert.Len(t, RegisterLoggerEntry(boot), 1)
+
+	// override explicitly
+	assert.Len(t, RegisterLoggerEntry(boot, WithAllowOverrideLoggerEntry()), 1)
+
+	// override silently
+	defer assertPanic(t)
+	RegisterLoggerEntry(boot)
+}
+
+func TestRegisterLoggerEntry_WithRedact(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(LoggerEntryType)
+
+	logPath := filepath.Join(t.TempDir(), "ut.log")
+	entries := RegisterLoggerEntry(&BootLogger{
+		Logger: []*BootLoggerE{
+			{
+				Name: "ut-logger-redact",
+				Zap: &rklogger.ZapConfigWrap{
+					OutputPaths: []string{logPath},
+				},
+				Redact: BootLoggerRedact{
+					Patterns: []string{`\d{4}-\d{4}-\d{4}-\d{4}`},
+				},
+			},
+		},
+	}, WithRedactPatternsLoggerEntry(`token-[a-z]+`))
+	assert.Len(t, entries, 1)
+
+	entries[0].With(zap.String("ut-with", "token-with")).Info("card 1234-5678-1234-5678",
+		zap.String("ut-key", "token-abc"),
+		zap.Error(errors.New("invalid token-err")),
+		zap.Int("ut-int", 1))
+	entries[0].Sync()
+
+	content, err := os.ReadFile(logPath)
+	assert.Nil(t, err)
+	assert.Contains(t, string(content), "card ***")
+	assert.Contains(t, string(content), `"ut-key": "***"`)
+	assert.Contains(t, string(content), `"ut-with": "***"`)
+	assert.Contains(t, string(content), "invalid ***")
+	assert.Contains(t, string(content), `"ut-int": 1`)
+	assert.NotContains(t, string(content), "token-")
+	assert.NotContains(t, string(content), "1234-5678")
+}
+
+func TestRegisterLoggerEntry_WithBuffer(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(LoggerEntryType)
+
+	logPath := filepath.Join(t.TempDir(), "ut.log")
+	entries := RegisterLoggerEntry(&BootLogger{
+		Logger: []*BootLoggerE{
+			{
+				Name: "ut-logger-buffer",
+				Zap: &rklogger.ZapConfigWrap{
+					OutputPaths: []string{logPath},
+				},
+				Buffer: BootLoggerBuffer{
+					Enabled:         true,
+					FlushIntervalMs: time.Hour.Milliseconds(),
+				},
+			},
+		},
+	})
+	assert.Len(t, entries, 1)
+	assert.Len(t, entries[0].bufferedSyncers, 1)
+	assert.Equal(t, []string{logPath}, entries[0].LoggerConfig.OutputPaths)
+
+	// logs are buffered
+	entries[0].Info("ut-buffered")
+	content, _ := os.ReadFile(logPath)
+	assert.NotContains(t, string(content), "ut-buffered")
+
+	// flushed on interrupt
+	entries[0].Interrupt(context.TODO())
+	content, err := os.ReadFile(logPath)
+	assert.Nil(t, err)
+	assert.Contains(t, string(content), "ut-buffered")
+}
+
+func TestRegisterLoggerEntry_WithLogfmt(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(LoggerEntryType)
+
+	logPath := filepath.Join(t.TempDir(), "ut.log")
+	entries := RegisterLoggerEntry(&BootLogger{
+		Logger: []*BootLoggerE{
+			{
+				Name: "ut-logger-logfmt",
+				Zap: &rklogger.ZapConfigWrap{
+					Encoding:    LoggerEncodingLogfmt,
+					OutputPaths: []string{logPath},
+				},
+			},
+		},
+	})
+	assert.Len(t, entries, 1)
+
+	entries[0].With(zap.String("service", "ut")).Info("ut logfmt", zap.Int("code", 200))
+	entries[0].Sync()
+
+	content, err := os.ReadFile(logPath)
+	assert.Nil(t, err)
+	assert.Contains(t, string(content), `msg="ut logfmt"`)
+	assert.Contains(t, string(content), "service=ut code=200")
+}
+
+func TestRegisterLoggerEntry_WithInvalidEncoding(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(LoggerEntryType)
+	defer assertPanic(t)
+
+	RegisterLoggerEntry(&BootLogger{
+		Logger: []*BootLoggerE{
+			{
+				Name: "ut-logger-encoding-invalid",
+				Zap: &rklogger.ZapConfigWrap{
+					Encoding: "xml",
+				},
+			},
+		},
+	})
+}
+
+func TestRegisterLoggerEntry_WithFieldNames(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(LoggerEntryType)
+
+	logPath := filepath.Join(t.TempDir(), "ut.log")
+	entries := RegisterLoggerEntry(&BootLogger{
+		Logger: []*BootLoggerE{
+			{
+				Name: "ut-logger-field-names",
+				Zap: &rklogger.ZapConfigWrap{
+					Encoding:    rklogger.EncodingJson,
+					OutputPaths: []string{logPath},
+				},
+				FieldNames: BootLoggerFieldNames{
+					Time:    "@timestamp",
+					Level:   "severity",
+					Message: "message",
+					Caller:  "source",
+				},
+			},
+		},
+	})
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "@timestamp", entries[0].LoggerConfig.EncoderConfig.TimeKey)
+
+	entries[0].Info("ut-field-names")
+	entries[0].Sync()
+
+	content, err := os.ReadFile(logPath)
+	assert.Nil(t, err)
+	m := make(map[string]interface{})
+	assert.Nil(t, json.Unmarshal(content, &m))
+	assert.Equal(t, "ut-field-names", m["message"])
+	assert.Equal(t, "INFO", m["severity"])
+	assert.Contains(t, m, "@timestamp")
+	assert.Contains(t, m, "source")
+	assert.NotContains(t, m, "msg")
+}
+
+func TestRegisterLoggerEntry_WithInvalidFieldNames(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(LoggerEntryType)
+
+	// blank name
+	func() {
+		defer assertPanic(t)
+		RegisterLoggerEntry(&BootLogger{
+			Logger: []*BootLoggerE{
+				{
+					Name:       "ut-logger-field-names-blank",
+					FieldNames: BootLoggerFieldNames{Level: " "},
+				},
+			},
+		})
+	}()
+
+	// duplicate names
+	func() {
+		defer assertPanic(t)
+		RegisterLoggerEntry(&BootLogger{
+			Logger: []*BootLoggerE{
+				{
+					Name:       "ut-logger-field-names-duplicate",
+					FieldNames: BootLoggerFieldNames{Level: "severity", Message: "severity"},
+				},
+			},
+		})
+	}()
+
+	// name conflicts with key of zap config
+	func() {
+		defer assertPanic(t)
+		RegisterLoggerEntry(&BootLogger{
+			Logger: []*BootLoggerE{
+				{
+					Name:       "ut-logger-field-names-conflict",
+					FieldNames: BootLoggerFieldNames{Time: "msg"},
+				},
+			},
+		})
+	}()
+}
+
+func TestRegisterLoggerEntry_WithLevelOutput(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(LoggerEntryType)
+
+	dir := t.TempDir()
+	appPath := filepath.Join(dir, "app.log")
+	errorPath := filepath.Join(dir, "error", "error.log")
+	entries := RegisterLoggerEntry(&BootLogger{
+		Logger: []*BootLoggerE{
+			{
+				Name: "ut-logger-level-output",
+				Zap: &rklogger.ZapConfigWrap{
+					OutputPaths: []string{appPath},
+				},
+				LevelOutput: BootLoggerLevelOutput{
+					Paths: map[string][]string{
+						"error": {errorPath},
+					},
+				},
+			},
+		},
+	})
+	assert.Len(t, entries, 1)
+
+	entries[0].Info("ut-info")
+	entries[0].With(zap.String("key", "value")).Error("ut-error")
+	entries[0].Sync()
+
+	content, err := os.ReadFile(appPath)
+	assert.Nil(t, err)
+	assert.Contains(t, string(content), "ut-info")
+	assert.NotContains(t, string(content), "ut-error")
+
+	content, err = os.ReadFile(errorPath)
+	assert.Nil(t, err)
+	assert.Contains(t, string(content), "ut-error")
+	assert.Contains(t, string(content), "value")
+	assert.NotContains(t, string(content), "ut-info")
+}
+
+func TestRegisterLoggerEntry_WithInvalidLevelOutput(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(LoggerEntryType)
+
+	// invalid level
+	func() {
+		defer assertPanic(t)
+		RegisterLoggerEntry(&BootLogger{
+			Logger: []*BootLoggerE{
+				{
+					Name: "ut-logger-level-output-invalid",
+					LevelOutput: BootLoggerLevelOutput{
+						Paths: map[string][]string{"unknown": {"stdout"}},
+					},
+				},
+			},
+		})
+	}()
+
+	// not writable
+	func() {
+		defer assertPanic(t)
+		RegisterLoggerEntry(&BootLogger{
+			Logger: []*BootLoggerE{
+				{
+					Name: "ut-logger-level-output-invalid",
+					LevelOutput: BootLoggerLevelOutput{
+						Paths: map[string][]string{"error": {t.TempDir()}},
+					},
+				},
+			},
+		})
+	}()
+}
+
+func TestLoggerEntry_Reconfigure(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(LoggerEntryType)
+
+	dir := t.TempDir()
+	prevPath := filepath.Join(dir, "prev.log")
+	nextPath := filepath.Join(dir, "next.log")
+	entries := RegisterLoggerEntry(&BootLogger{
+		Logger: []*BootLoggerE{
+			{
+				Name: "ut-logger-reconfigure",
+				Zap: &rklogger.ZapConfigWrap{
+					OutputPaths: []string{prevPath},
+				},
+				Buffer: BootLoggerBuffer{
+					Enabled:         true,
+					FlushIntervalMs: time.Hour.Milliseconds(),
+				},
+			},
+		},
+	})
+	assert.Len(t, entries, 1)
+	entry := entries[0]
+	derived := entry.With(zap.String("key", "value"))
+
+	// buffered logs are flushed into previous output
+	entry.Info("ut-before")
+	derived.Info("ut-derived-before")
+	assert.Nil(t, entry.Reconfigure(&BootLoggerE{
+		Name: "ignored",
+		Zap: &rklogger.ZapConfigWrap{
+			OutputPaths: []string{nextPath},
+		},
+	}))
+	assert.Equal(t, "ut-logger-reconfigure", entry.GetName())
+	assert.Equal(t, []string{nextPath}, entry.LoggerConfig.OutputPaths)
+	assert.Empty(t, entry.bufferedSyncers)
+
+	entry.Info("ut-after")
+	derived.Info("ut-derived-after")
+	entry.Sync()
+
+	content, err := os.ReadFile(prevPath)
+	assert.Nil(t, err)
+	assert.Contains(t, string(content), "ut-before")
+	assert.Contains(t, string(content), "ut-derived-before")
+	assert.NotContains(t, string(content), "ut-after")
+
+	content, err = os.ReadFile(nextPath)
+	assert.Nil(t, err)
+	assert.Contains(t, string(content), "ut-after")
+	assert.Contains(t, string(content), "ut-derived-after")
+	assert.Contains(t, string(content), "value")
+	assert.NotContains(t, string(content), "ut-before")
+
+	// invalid config keeps current core
+	assert.NotNil(t, entry.Reconfigure(nil))
+	assert.NotNil(t, entry.Reconfigure(&BootLoggerE{
+		Zap: &rklogger.ZapConfigWrap{
+			Encoding: "invalid",
+		},
+	}))
+	assert.Equal(t, []string{nextPath}, entry.LoggerConfig.OutputPaths)
+
+	// entry not registered with RegisterLoggerEntry
+	assert.NotNil(t, NewLoggerEntryNoop().Reconfigure(&BootLoggerE{}))
+}
+
+func TestLoggerEntry_Reopen(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(LoggerEntryType)
+
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "app.log")
+	errorPath := filepath.Join(dir, "error.log")
+	entries := RegisterLoggerEntry(&BootLogger{
+		Logger: []*BootLoggerE{
+			{
+				Name: "ut-logger-reopen",
+				Zap: &rklogger.ZapConfigWrap{
+					OutputPaths: []string{logPath},
+				},
+				Buffer: BootLoggerBuffer{
+					Enabled: true,
+				},
+				LevelOutput: BootLoggerLevelOutput{
+					Paths: map[string][]string{
+						"error": {errorPath},
+					},
+				},
+			},
+		},
+	})
+	assert.Len(t, entries, 1)
+	entry := entries[0]
+	assert.Len(t, entry.files, 2)
+
+	// file moved by logrotate
+	entry.Info("ut-before")
+	entry.Error("ut-error-before")
+	assert.Nil(t, entry.Reopen())
+	assert.Nil(t, os.Rename(logPath, logPath+".1"))
+	assert.Nil(t, os.Rename(errorPath, errorPath+".1"))
+
+	entry.Info("ut-after")
+	entry.Error("ut-error-after")
+	entry.Sync()
+
+	content, err := os.ReadFile(logPath + ".1")
+	assert.Nil(t, err)
+	assert.Contains(t, string(content), "ut-before")
+	assert.NotContains(t, string(content), "ut-after")
+
+	content, err = os.ReadFile(logPath)
+	assert.Nil(t, err)
+	assert.Contains(t, string(content), "ut-after")
+
+	content, err = os.ReadFile(errorPath)
+	assert.Nil(t, err)
+	assert.Contains(t, string(content), "ut-error-after")
+	assert.NotContains(t, string(content), "ut-error-before")
+
+	assert.Nil(t, GlobalAppCtx.ReopenLoggerEntries())
+}
+
+func TestRegisterLoggerEntry_WithSharedOutput(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(LoggerEntryType)
+
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "app.log")
+	newBoot := func(name string, lumber *lumberjack.Logger) *BootLogger {
+		return &BootLogger{
+			Logger: []*BootLoggerE{
+				{
+					Name: name,
+					Zap: &rklogger.ZapConfigWrap{
+						OutputPaths: []string{logPath},
+					},
+					Lumberjack: lumber,
+				},
+			},
+		}
+	}
+
+	// same file within one entry is opened once
+	entries := RegisterLoggerEntry(&BootLogger{
+		Logger: []*BootLoggerE{
+			{
+				Name: "ut-logger-owner",
+				Zap: &rklogger.ZapConfigWrap{
+					OutputPaths: []string{logPath},
+				},
+				LevelOutput: BootLoggerLevelOutput{
+					Paths: map[string][]string{
+						"error": {filepath.Join(dir, ".", "app.log")},
+					},
+				},
+			},
+		},
+	})
+	assert.Len(t, entries, 1)
+	owner := entries[0]
+	assert.Len(t, owner.files, 2)
+	assert.Same(t, owner.files[0], owner.files[1])
+
+	// shared with other entry without option
+	func() {
+		defer assertPanic(t)
+		RegisterLoggerEntry(newBoot("ut-logger-other", nil))
+	}()
+
+	// shared with different rotation config
+	func() {
+		defer assertPanic(t)
+		RegisterLoggerEntry(newBoot("ut-logger-other", &lumberjack.Logger{MaxSize: 10}), WithSharedOutputLoggerEntry())
+	}()
+
+	// shared with option, writer is coalesced
+	entries = RegisterLoggerEntry(newBoot("ut-logger-other", nil), WithSharedOutputLoggerEntry())
+	assert.Len(t, entries, 1)
+	assert.Same(t, owner.files[0], entries[0].files[0])
+}
+
+func TestLoggerEntry_WithConfigRef(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(LoggerEntryType)
+	defer GlobalAppCtx.RemoveEntryByType(ConfigEntryType)
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	firstPath := filepath.Join(dir, "first.log")
+	secondPath := filepath.Join(dir, "second.log")
+	writeConfig := func(level, path string) {
+		assert.Nil(t, os.WriteFile(configPath, []byte(`
+log:
+  zap:
+    level: `+level+`
+    outputPaths: ["`+path+`"]
+`), os.ModePerm))
+	}
+	writeConfig("debug", firstPath)
+
+	entries := RegisterLoggerEntry(&BootLogger{
+		Logger: []*BootLoggerE{
+			{
+				Name:    "ut-logger-ref",
+				Default: true,
+				ConfigRef: BootLoggerConfigRef{
+					Entry:  "ut-config-ref",
+					Prefix: "log",
+				},
+			},
+		},
+	})
+	assert.Len(t, entries, 1)
+	entry := entries[0]
+
+	RegisterConfigEntry(&BootConfig{
+		Config: []*BootConfigE{
+			{
+				Name: "ut-config-ref",
+				Path: configPath,
+			},
+		},
+	})
+
+	// settings are sourced at bootstrap
+	entry.Bootstrap(context.TODO())
+	entry.Debug("ut-first")
+	entry.Sync()
+	content, err := os.ReadFile(firstPath)
+	assert.Nil(t, err)
+	assert.Contains(t, string(content), "ut-first")
+	assert.True(t, entry.IsDefault)
+
+	// reconfigured once keys changed
+	writeConfig("error", secondPath)
+	assert.Nil(t, GlobalAppCtx.GetConfigEntry("ut-config-ref").Reload())
+	entry.Info("ut-second-info")
+	entry.Error("ut-second-error")
+	entry.Sync()
+	content, err = os.ReadFile(secondPath)
+	assert.Nil(t, err)
+	assert.Contains(t, string(content), "ut-second-error")
+	assert.NotContains(t, string(content), "ut-second-info")
+	content, _ = os.ReadFile(firstPath)
+	assert.NotContains(t, string(content), "ut-second")
+}
+
+func TestLoggerEntry_WithMissingConfigRef(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(LoggerEntryType)
+
+	entries := RegisterLoggerEntry(&BootLogger{
+		Logger: []*BootLoggerE{
+			{
+				Name: "ut-logger-ref-missing",
+				ConfigRef: BootLoggerConfigRef{
+					Entry: "ut-config-missing",
+				},
+			},
+		},
+	})
+	assert.Len(t, entries, 1)
+
+	defer assertPanic(t)
+	entries[0].Bootstrap(context.TODO())
+}
+
+func TestRegisterLoggerEntry_WithInvalidRedactPattern(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(LoggerEntryType)
+	defer assertPanic(t)
+
+	RegisterLoggerEntry(&BootLogger{
+		Logger: []*BootLoggerE{
+			{
+				Name: "ut-logger-redact-invalid",
+				Redact: BootLoggerRedact{
+					Patterns: []string{"("},
+				},
+			},
+		},
+	})
+}
+
+func TestRegisterLoggerEntry_WithEvent(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(LoggerEntryType)
+	defer GlobalAppCtx.RemoveEntryByType(EventEntryType)
+
+	buf := &bytes.Buffer{}
+	eventEntry := &EventEntry{
+		entryName: "ut-event-tee",
+		entryType: EventEntryType,
+		EventHelper: rkquery.NewEventHelper(rkquery.NewEventFactory(
+			rkquery.WithZapLogger(zap.New(zapcore.NewCore(
+				zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(buf), zap.DebugLevel))),
+			rkquery.WithEncoding(rkquery.JSON))),
+	}
+	GlobalAppCtx.AddEntry(eventEntry)
+
+	entries := RegisterLoggerEntry(&BootLogger{
+		Logger: []*BootLoggerE{
+			{
+				Name: "ut-logger",
+				Zap: &rklogger.ZapConfigWrap{
+					OutputPaths: []string{"stdout"},
+				},
+				Event: BootLoggerEvent{
+					Enabled:   true,
+					EntryName: "ut-event-tee",
+				},
+			},
+		},
+	})
+	assert.Len(t, entries, 1)
+
+	// below warn level
+	entries[0].Info("ut-info")
+	assert.Empty(t, buf.String())
+
+	entries[0].With(zap.String("ut-with", "with")).Error("ut-error", zap.Int("ut-key", 1))
+	assert.Contains(t, buf.String(), "ut-error")
+	assert.Contains(t, buf.String(), `"ut-key":"1"`)
+	assert.Contains(t, buf.String(), `"ut-with":"with"`)
+	assert.Contains(t, buf.String(), `"level":"error"`)
+}
+
+func TestRegisterLoggerEntry_WithInvalidEventLevel(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(LoggerEntryType)
+	defer assertPanic(t)
+
+	RegisterLoggerEntry(&BootLogger{
+		Logger: []*BootLoggerE{
+			{
+				Name:  "ut-logger",
+				Event: BootLoggerEvent{Enabled: true, Level: "invalid"},
+			},
+		},
+	})
+}
+
+func TestRegisterLoggerEntry_WithLevelEnv(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(LoggerEntryType)
+
+	t.Setenv(LoggerLevelEnvPrefix+"utLogger", "debug")
+	t.Setenv(LoggerLevelEnvPrefix+"UTLOGGERUPPER", "error")
+	t.Setenv(LoggerLevelEnvPrefix+"utLoggerInvalid", "invalid")
+
+	entries := RegisterLoggerEntry(&BootLogger{
+		Logger: []*BootLoggerE{
+			{Name: "utLogger"},
+			{Name: "utLoggerUpper"},
+			{Name: "utLoggerInvalid"},
+		},
+	})
+	assert.Len(t, entries, 3)
+
+	logger := GlobalAppCtx.GetLoggerEntry("utLogger")
+	assert.Equal(t, zapcore.DebugLevel, logger.LoggerConfig.Level.Level())
+	assert.True(t, logger.Core().Enabled(zapcore.DebugLevel))
+
+	logger = GlobalAppCtx.GetLoggerEntry("utLoggerUpper")
+	assert.Equal(t, zapcore.ErrorLevel, logger.LoggerConfig.Level.Level())
+	assert.False(t, logger.Core().Enabled(zapcore.WarnLevel))
+
+	// keep default level
+	logger = GlobalAppCtx.GetLoggerEntry("utLoggerInvalid")
+	assert.Equal(t, zapcore.InfoLevel, logger.LoggerConfig.Level.Level())
+}
+
+func TestRegisterLoggerEntry_WithLevelEnvAndStrictBootConfig(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(LoggerEntryType)
+
+	t.Setenv(LoggerLevelEnvPrefix+"utLogger", "debug")
+
+	// level env is not taken as override of boot config
+	boot := &BootLogger{}
+	assert.Nil(t, UnmarshalBootConfigStrict([]byte("logger:\n  - name: utLogger"), boot))
+
+	RegisterLoggerEntry(boot)
+	logger := GlobalAppCtx.GetLoggerEntry("utLogger")
+	assert.Equal(t, zapcore.DebugLevel, logger.LoggerConfig.Level.Level())
+}
@@ -0,0 +1,16 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+
+package rkentry
+
+import (
+	"os"
+	"syscall"
+)
+
+// loggerReopenSignals are signals sent by log rotation tools like logrotate
+var loggerReopenSignals = []os.Signal{syscall.SIGUSR1}
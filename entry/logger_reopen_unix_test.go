@@ -0,0 +1,53 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+
+package rkentry
+
+import (
+	"github.com/rookie-ninja/rk-logger"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestAppContext_SetLoggerReopenOnSignal(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(LoggerEntryType)
+	defer GlobalAppCtx.SetLoggerReopenOnSignal(false)
+
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	entry := RegisterLoggerEntry(&BootLogger{
+		Logger: []*BootLoggerE{
+			{
+				Name: "ut-logger-signal",
+				Zap: &rklogger.ZapConfigWrap{
+					OutputPaths: []string{logPath},
+				},
+			},
+		},
+	})[0]
+
+	entry.Info("ut-before")
+	assert.Nil(t, os.Rename(logPath, logPath+".1"))
+
+	GlobalAppCtx.SetLoggerReopenOnSignal(true)
+	GlobalAppCtx.SetLoggerReopenOnSignal(true)
+	assert.Nil(t, syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+
+	// file is reopened at next write after signal is handled
+	assert.Eventually(t, func() bool {
+		entry.Info("ut-after")
+		content, err := os.ReadFile(logPath)
+		return err == nil && strings.Contains(string(content), "ut-after")
+	}, 5*time.Second, 10*time.Millisecond)
+
+	GlobalAppCtx.SetLoggerReopenOnSignal(false)
+	GlobalAppCtx.SetLoggerReopenOnSignal(false)
+}
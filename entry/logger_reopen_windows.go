@@ -0,0 +1,11 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import "os"
+
+// loggerReopenSignals is empty since SIGUSR1 is not supported on windows
+var loggerReopenSignals []os.Signal
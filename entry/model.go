@@ -22,6 +22,47 @@ type readyResp struct {
 	Ready bool `json:"ready" yaml:"ready" example:"true"`
 }
 
+const (
+	readyProbeStatusReady    = "ready"
+	readyProbeStatusNotReady = "notReady"
+	readyProbeStatusSkipped  = "skipped"
+)
+
+// readyDetailResp response of /ready?verbose=true
+type readyDetailResp struct {
+	Ready    bool              `json:"ready" yaml:"ready" example:"false"`
+	Draining bool              `json:"draining" yaml:"draining" example:"false"`
+	Probes   []*readyProbeResp `json:"probes" yaml:"probes"`
+}
+
+// readyProbeResp result of a readiness probe in readyDetailResp
+type readyProbeResp struct {
+	Name      string    `json:"name" yaml:"name" example:"database"`
+	Status    string    `json:"status" yaml:"status" example:"notReady"`
+	Latency   string    `json:"latency" yaml:"latency" example:"1.2ms"`
+	CheckedAt time.Time `json:"checkedAt" yaml:"checkedAt"`
+	Cached    bool      `json:"cached" yaml:"cached" example:"false"`
+	RootCause string    `json:"rootCause,omitempty" yaml:"rootCause,omitempty" example:""`
+	Error     string    `json:"error,omitempty" yaml:"error,omitempty" example:"connection refused"`
+}
+
+// versionResp response of /version
+type versionResp struct {
+	Name    string `json:"name" yaml:"name" example:"rk-app"`
+	Version string `json:"version" yaml:"version" example:"v0.0.1"`
+}
+
+// logLevelReq request of /admin/loglevel/{name}
+type logLevelReq struct {
+	Level string `json:"level" yaml:"level" example:"debug"`
+}
+
+// logLevelResp response of /admin/loglevel/{name}
+type logLevelResp struct {
+	Name  string `json:"name" yaml:"name" example:"my-logger"`
+	Level string `json:"level" yaml:"level" example:"debug"`
+}
+
 // gcResp response of /gc
 // Returns memory stats of GC before and after.
 type gcResp struct {
@@ -31,27 +72,31 @@ type gcResp struct {
 
 // ProcessInfo process information for a running application.
 type ProcessInfo struct {
-	AppName     string          `json:"appName" yaml:"appName" example:"rk-app"`
-	Version     string          `json:"version" yaml:"version" example:"dev"`
-	Description string          `json:"description" yaml:"description" example:"RK application"`
-	Keywords    []string        `json:"keywords" yaml:"keywords" example:""`
-	HomeUrl     string          `json:"homeUrl" yaml:"homeUrl" example:"https://example.com"`
-	DocsUrl     []string        `json:"docsUrl" yaml:"docsUrl" example:""`
-	Maintainers []string        `json:"maintainers" yaml:"maintainers" example:"rk-dev"`
-	UID         string          `json:"uid" yaml:"uid" example:"501"`
-	GID         string          `json:"gid" yaml:"gid" example:"20"`
-	Username    string          `json:"username" yaml:"username" example:"lark"`
-	StartTime   string          `json:"startTime" yaml:"startTime" example:"2022-03-15T20:43:05+08:00"`
-	UpTimeSec   int64           `json:"upTimeSec" yaml:"upTimeSec" example:"13"`
-	Region      string          `json:"region" yaml:"region" example:"us-east-1"`
-	AZ          string          `json:"az" yaml:"az" example:"us-east-1c"`
-	Realm       string          `json:"realm" yaml:"realm" example:"rookie-ninja"`
-	Domain      string          `json:"domain" yaml:"domain" example:"dev"`
-	CpuInfo     *rkos.CpuInfo   `json:"cpuInfo" yaml:"cpuInfo"`
-	MemInfo     *rkos.MemInfo   `json:"memInfo" yaml:"memInfo"`
-	NetInfo     *rkos.NetInfo   `json:"netInfo" yaml:"netInfo"`
-	OsInfo      *rkos.OsInfo    `json:"osInfo" yaml:"osInfo"`
-	GoEnvInfo   *rkos.GoEnvInfo `json:"goEnvInfo" yaml:"goEnvInfo"`
+	AppName      string          `json:"appName" yaml:"appName" example:"rk-app"`
+	Version      string          `json:"version" yaml:"version" example:"dev"`
+	Description  string          `json:"description" yaml:"description" example:"RK application"`
+	Keywords     []string        `json:"keywords" yaml:"keywords" example:""`
+	HomeUrl      string          `json:"homeUrl" yaml:"homeUrl" example:"https://example.com"`
+	DocsUrl      []string        `json:"docsUrl" yaml:"docsUrl" example:""`
+	Maintainers  []string        `json:"maintainers" yaml:"maintainers" example:"rk-dev"`
+	UID          string          `json:"uid" yaml:"uid" example:"501"`
+	GID          string          `json:"gid" yaml:"gid" example:"20"`
+	Username     string          `json:"username" yaml:"username" example:"lark"`
+	StartTime    string          `json:"startTime" yaml:"startTime" example:"2022-03-15T20:43:05+08:00"`
+	UpTimeSec    int64           `json:"upTimeSec" yaml:"upTimeSec" example:"13"`
+	UpTime       string          `json:"upTime" yaml:"upTime" example:"13.5s"`
+	ShutdownTime string          `json:"shutdownTime,omitempty" yaml:"shutdownTime,omitempty" example:"2022-03-15T20:43:18+08:00"`
+	Region       string          `json:"region" yaml:"region" example:"us-east-1"`
+	AZ           string          `json:"az" yaml:"az" example:"us-east-1c"`
+	Realm        string          `json:"realm" yaml:"realm" example:"rookie-ninja"`
+	Domain       string          `json:"domain" yaml:"domain" example:"dev"`
+	CpuInfo      *rkos.CpuInfo   `json:"cpuInfo" yaml:"cpuInfo"`
+	MemInfo      *rkos.MemInfo   `json:"memInfo" yaml:"memInfo"`
+	NetInfo      *rkos.NetInfo   `json:"netInfo" yaml:"netInfo"`
+	OsInfo       *rkos.OsInfo    `json:"osInfo" yaml:"osInfo"`
+	GoEnvInfo    *rkos.GoEnvInfo `json:"goEnvInfo" yaml:"goEnvInfo"`
+	// ConfigChecksums are checksums of ConfigEntry with name as key
+	ConfigChecksums map[string]string `json:"configChecksums" yaml:"configChecksums"`
 }
 
 // NewProcessInfo creates a new ProcessInfo instance
@@ -66,7 +111,7 @@ func NewProcessInfo() *ProcessInfo {
 		}
 	}
 
-	return &ProcessInfo{
+	res := &ProcessInfo{
 		AppName:     GlobalAppCtx.GetAppInfoEntry().AppName,
 		Version:     GlobalAppCtx.GetAppInfoEntry().Version,
 		Description: GlobalAppCtx.GetAppInfoEntry().GetDescription(),
@@ -79,14 +124,28 @@ func NewProcessInfo() *ProcessInfo {
 		GID:         u.Gid,
 		StartTime:   GlobalAppCtx.GetStartTime().Format(time.RFC3339),
 		UpTimeSec:   int64(GlobalAppCtx.GetUpTime().Seconds()),
+		UpTime:      GlobalAppCtx.GetUpTime().String(),
 		Realm:       getDefaultIfEmptyString(os.Getenv("REALM"), ""),
 		Region:      getDefaultIfEmptyString(os.Getenv("REGION"), ""),
 		AZ:          getDefaultIfEmptyString(os.Getenv("AZ"), ""),
-		Domain:      getDefaultIfEmptyString(os.Getenv("DOMAIN"), ""),
+		Domain:      GetActiveDomain(),
 		CpuInfo:     rkos.NewCpuInfo(),
 		MemInfo:     rkos.NewMemInfo(),
 		NetInfo:     rkos.NewNetInfo(),
 		OsInfo:      rkos.NewOsInfo(),
 		GoEnvInfo:   rkos.NewGoEnvInfo(),
 	}
+
+	res.ConfigChecksums = make(map[string]string)
+	for name, entry := range GlobalAppCtx.ListEntriesByType(ConfigEntryType) {
+		if v, ok := entry.(*ConfigEntry); ok {
+			res.ConfigChecksums[name] = v.Checksum()
+		}
+	}
+
+	if shutdownTime := GlobalAppCtx.GetShutdownTime(); !shutdownTime.IsZero() {
+		res.ShutdownTime = shutdownTime.Format(time.RFC3339)
+	}
+
+	return res
 }
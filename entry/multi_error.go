@@ -0,0 +1,83 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"errors"
+	"strings"
+)
+
+// MultiError aggregates errors of lifecycle operations like BootstrapAll and InterruptAll,
+// so that all failures could be inspected at once.
+//
+// errors.Is and errors.As match any of contained errors.
+type MultiError struct {
+	msg  string
+	errs []error
+}
+
+// NewMultiError creates MultiError with message and errors, nil errors are ignored.
+// Nil is returned if there is no error.
+func NewMultiError(msg string, errs ...error) error {
+	res := &MultiError{
+		msg:  msg,
+		errs: make([]error, 0, len(errs)),
+	}
+
+	for i := range errs {
+		if errs[i] != nil {
+			res.errs = append(res.errs, errs[i])
+		}
+	}
+
+	if len(res.errs) < 1 {
+		return nil
+	}
+
+	return res
+}
+
+// Error returns message followed by messages of contained errors
+func (e *MultiError) Error() string {
+	msgs := make([]string, 0, len(e.errs))
+	for i := range e.errs {
+		msgs = append(msgs, e.errs[i].Error())
+	}
+
+	res := "[" + strings.Join(msgs, "; ") + "]"
+	if len(e.msg) > 0 {
+		res = e.msg + ", " + res
+	}
+
+	return res
+}
+
+// Errors returns copy of contained errors
+func (e *MultiError) Errors() []error {
+	return append([]error{}, e.errs...)
+}
+
+// Is returns true if any of contained errors matches target
+func (e *MultiError) Is(target error) bool {
+	for i := range e.errs {
+		if errors.Is(e.errs[i], target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first contained error that matches target and sets target to it
+func (e *MultiError) As(target interface{}) bool {
+	for i := range e.errs {
+		if errors.As(e.errs[i], target) {
+			return true
+		}
+	}
+
+	return false
+}
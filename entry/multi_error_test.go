@@ -0,0 +1,58 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestNewMultiError(t *testing.T) {
+	// without errors
+	assert.Nil(t, NewMultiError("ut-msg"))
+	assert.Nil(t, NewMultiError("ut-msg", nil, nil))
+
+	first := errors.New("ut-first")
+	second := fmt.Errorf("ut-second, %w", os.ErrNotExist)
+	err := NewMultiError("ut-msg", first, nil, second)
+	assert.Equal(t, "ut-msg, [ut-first; ut-second, file does not exist]", err.Error())
+
+	multi := &MultiError{}
+	assert.True(t, errors.As(err, &multi))
+	assert.Equal(t, []error{first, second}, multi.Errors())
+
+	// match any contained error
+	assert.True(t, errors.Is(err, first))
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+	assert.False(t, errors.Is(err, os.ErrExist))
+
+	pathErr := &os.PathError{Op: "open", Path: "ut-path", Err: os.ErrNotExist}
+	var target *os.PathError
+	assert.True(t, errors.As(NewMultiError("", first, fmt.Errorf("wrapped, %w", pathErr)), &target))
+	assert.Equal(t, "ut-path", target.Path)
+	assert.False(t, errors.As(err, &target))
+
+	// without message
+	assert.Equal(t, "[ut-first]", NewMultiError("", first).Error())
+}
+
+func TestAppContext_BootstrapAll_WithMultiError(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+	GlobalAppCtx.clearEntries()
+
+	trace := make([]string, 0)
+	GlobalAppCtx.AddEntry(&lifecycleEntryMock{EntryMock: EntryMock{Name: "ut-multi-a"}, panicBoot: true, trace: &trace})
+
+	err := GlobalAppCtx.BootstrapAll(context.TODO())
+	multi := &MultiError{}
+	assert.True(t, errors.As(err, &multi))
+	assert.Len(t, multi.Errors(), 1)
+	assert.Contains(t, multi.Errors()[0].Error(), "ut-panic")
+}
@@ -0,0 +1,220 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"net/http"
+	"path"
+	"strings"
+	"unicode"
+)
+
+// OpenAPIVersion is version of OpenAPI spec generated by CommonServiceEntry
+const OpenAPIVersion = "3.0.3"
+
+// openAPIRouteDoc describes a builtin route of CommonServiceEntry in OpenAPI spec
+type openAPIRouteDoc struct {
+	methods   []string
+	summary   string
+	admin     bool
+	responses map[string]string
+}
+
+// openAPIBuiltinRoutes documents builtin routes with name of route in builtinPaths
+var openAPIBuiltinRoutes = map[string]openAPIRouteDoc{
+	"ready": {
+		methods:   []string{http.MethodGet},
+		summary:   "Get application readiness status",
+		responses: map[string]string{"200": "Application is ready", "503": "Application is not ready or draining"},
+	},
+	"alive": {
+		methods:   []string{http.MethodGet},
+		summary:   "Get application liveness status",
+		responses: map[string]string{"200": "Application is alive", "503": "Application is not alive"},
+	},
+	"gc": {
+		methods:   []string{http.MethodGet},
+		summary:   "Trigger Gc",
+		responses: map[string]string{"200": "Memory stats before and after Gc"},
+	},
+	"info": {
+		methods:   []string{http.MethodGet},
+		summary:   "Get application and process info",
+		responses: map[string]string{"200": "Application and process info"},
+	},
+	"version": {
+		methods:   []string{http.MethodGet},
+		summary:   "Get application name and version",
+		responses: map[string]string{"200": "Application name and version"},
+	},
+	"adminConfig": {
+		methods:   []string{http.MethodGet},
+		summary:   "Get redacted config of all entries",
+		admin:     true,
+		responses: map[string]string{"200": "Redacted config of entries", "401": "Invalid bearer token"},
+	},
+	"adminLogLevel": {
+		methods:   []string{http.MethodGet},
+		summary:   "Get levels of all LoggerEntry",
+		admin:     true,
+		responses: map[string]string{"200": "Levels with key of LoggerEntry name", "401": "Invalid bearer token"},
+	},
+	"openAPI": {
+		methods:   []string{http.MethodGet},
+		summary:   "Get OpenAPI spec of common service routes and routes of entries",
+		responses: map[string]string{"200": "OpenAPI spec"},
+	},
+}
+
+// OpenAPISpec generates OpenAPI spec of enabled builtin routes and routes of entries returned by ListEntryRoutes.
+//
+// Spec follows paths of entry, so disabled or customized routes are reflected.
+// Routes of entries without Method are described with GET and accept any method.
+func (entry *CommonServiceEntry) OpenAPISpec() (map[string]interface{}, error) {
+	routes, err := entry.ListEntryRoutes()
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make(map[string]interface{})
+	addOperation := func(p, method string, op map[string]interface{}) {
+		item, ok := paths[p].(map[string]interface{})
+		if !ok {
+			item = make(map[string]interface{})
+			paths[p] = item
+		}
+		item[strings.ToLower(method)] = op
+	}
+
+	// builtin routes
+	for p, name := range entry.builtinPaths() {
+		doc, ok := openAPIBuiltinRoutes[name]
+		if !ok {
+			continue
+		}
+
+		for _, method := range doc.methods {
+			addOperation(p, method, newOpenAPIOperation(name, doc.summary, "common", doc.admin, doc.responses))
+		}
+	}
+
+	// LoggerEntry is parsed from path of AdminLogLevel
+	if len(entry.AdminLogLevelPath) > 0 {
+		p := path.Join(entry.AdminLogLevelPath, "{name}")
+		responses := map[string]string{
+			"200": "Name and level of LoggerEntry",
+			"400": "Invalid level",
+			"401": "Invalid bearer token",
+			"404": "LoggerEntry is missing",
+		}
+
+		get := newOpenAPIOperation("adminLogLevelGet", "Get level of LoggerEntry", "common", true, responses)
+		get["parameters"] = []interface{}{openAPINameParam}
+		addOperation(p, http.MethodGet, get)
+
+		put := newOpenAPIOperation("adminLogLevelPut", "Change level of LoggerEntry at runtime", "common", true, responses)
+		put["parameters"] = []interface{}{openAPINameParam}
+		put["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema": map[string]interface{}{
+						"type":       "object",
+						"required":   []interface{}{"level"},
+						"properties": map[string]interface{}{"level": map[string]interface{}{"type": "string", "example": "debug"}},
+					},
+				},
+			},
+		}
+		addOperation(p, http.MethodPut, put)
+	}
+
+	// routes of entries
+	for i := range routes {
+		route := routes[i]
+		method := route.Method
+		if len(method) < 1 {
+			method = http.MethodGet
+		}
+
+		summary := route.Summary
+		if len(summary) < 1 {
+			summary = "Route of entry"
+		}
+
+		op := newOpenAPIOperation(openAPIOperationId(method, route.Path), summary, "entry", false, map[string]string{"200": "OK"})
+		if len(route.Method) < 1 {
+			op["description"] = "Any method is accepted."
+		}
+		addOperation(route.Path, method, op)
+	}
+
+	appInfo := GlobalAppCtx.GetAppInfoEntry()
+
+	return map[string]interface{}{
+		"openapi": OpenAPIVersion,
+		"info": map[string]interface{}{
+			"title":       appInfo.AppName,
+			"version":     appInfo.Version,
+			"description": appInfo.GetDescription(),
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"securitySchemes": map[string]interface{}{
+				"adminToken": map[string]interface{}{
+					"type":   "http",
+					"scheme": "bearer",
+				},
+			},
+		},
+	}, nil
+}
+
+// openAPIOperationId derives unique operation id from method and path, like postRkV1AdminUtPost
+func openAPIOperationId(method, p string) string {
+	builder := &strings.Builder{}
+	builder.WriteString(strings.ToLower(method))
+
+	words := strings.FieldsFunc(p, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		runes := []rune(word)
+		builder.WriteRune(unicode.ToUpper(runes[0]))
+		builder.WriteString(string(runes[1:]))
+	}
+
+	return builder.String()
+}
+
+// openAPINameParam is path parameter of LoggerEntry name
+var openAPINameParam = map[string]interface{}{
+	"name":     "name",
+	"in":       "path",
+	"required": true,
+	"schema":   map[string]interface{}{"type": "string"},
+}
+
+// newOpenAPIOperation creates operation object with responses keyed by status code
+func newOpenAPIOperation(id, summary, tag string, admin bool, responses map[string]string) map[string]interface{} {
+	resp := make(map[string]interface{}, len(responses))
+	for code, desc := range responses {
+		resp[code] = map[string]interface{}{"description": desc}
+	}
+
+	res := map[string]interface{}{
+		"operationId": id,
+		"summary":     summary,
+		"tags":        []interface{}{tag},
+		"responses":   resp,
+	}
+
+	if admin {
+		res["security"] = []interface{}{map[string]interface{}{"adminToken": []interface{}{}}}
+	}
+
+	return res
+}
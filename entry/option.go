@@ -0,0 +1,41 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+// Option is a functional option which modifies T, use it to define options of custom entries.
+//
+//	type MyEntry struct { name string }
+//
+//	func WithName(name string) rkentry.Option[MyEntry] {
+//		return func(entry *MyEntry) { entry.name = name }
+//	}
+type Option[T any] func(*T)
+
+// ApplyOptions applies opts to target in order and returns target, nil options are skipped.
+func ApplyOptions[T any](target *T, opts ...Option[T]) *T {
+	if target == nil {
+		return nil
+	}
+
+	for i := range opts {
+		if opts[i] != nil {
+			opts[i](target)
+		}
+	}
+
+	return target
+}
+
+// OptionOf returns Option which sets value with setter, it saves a closure for options of a single field.
+//
+//	WithName := func(name string) rkentry.Option[MyEntry] {
+//		return rkentry.OptionOf(func(entry *MyEntry, v string) { entry.name = v }, name)
+//	}
+func OptionOf[T any, V any](setter func(*T, V), value V) Option[T] {
+	return func(target *T) {
+		setter(target, value)
+	}
+}
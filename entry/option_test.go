@@ -0,0 +1,34 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestApplyOptions(t *testing.T) {
+	type optionTarget struct {
+		name string
+		port int
+	}
+
+	withName := func(name string) Option[optionTarget] {
+		return func(target *optionTarget) {
+			target.name = name
+		}
+	}
+	withPort := func(port int) Option[optionTarget] {
+		return OptionOf(func(target *optionTarget, v int) { target.port = v }, port)
+	}
+
+	target := ApplyOptions(&optionTarget{}, withName("first"), nil, withPort(8080), withName("second"))
+	assert.Equal(t, "second", target.name)
+	assert.Equal(t, 8080, target.port)
+
+	// nil target
+	assert.Nil(t, ApplyOptions[optionTarget](nil, withName("ut")))
+}
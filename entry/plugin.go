@@ -0,0 +1,71 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"fmt"
+)
+
+// PluginRegFuncSymbol is the name of symbol exported by Go plugin loaded with LoadPlugin
+const PluginRegFuncSymbol = "RegFunc"
+
+// LoadPlugin opens Go plugin (.so) file of path, looks up exported symbol of PluginRegFuncSymbol
+// and registers it with RegisterPluginRegFunc, call it before entries are registered from boot config.
+//
+// Symbol could be either a function or a variable of RegFunc:
+//
+//	package main
+//
+//	func RegFunc(raw []byte) map[string]rkentry.Entry {
+//	    ...
+//	}
+//
+// Go plugins are finicky, plugin must be built with -buildmode=plugin by the same Go version,
+// with the same versions of packages shared with application, including rk-entry.
+// Plugins are supported on linux, darwin and freebsd with cgo enabled, error is returned on others.
+// A plugin could be opened only once per process, loading the same path again returns the same symbol.
+func LoadPlugin(path string) error {
+	sym, err := lookupPluginSymbol(path, PluginRegFuncSymbol)
+	if err != nil {
+		return err
+	}
+
+	regFunc, err := regFuncOfPluginSymbol(sym)
+	if err != nil {
+		return fmt.Errorf("invalid symbol of plugin, path:%s, symbol:%s, %v", path, PluginRegFuncSymbol, err)
+	}
+
+	RegisterPluginRegFunc(regFunc)
+	return nil
+}
+
+// regFuncOfPluginSymbol converts symbol looked up from plugin to RegFunc
+func regFuncOfPluginSymbol(sym interface{}) (RegFunc, error) {
+	var res RegFunc
+
+	switch v := sym.(type) {
+	case func([]byte) map[string]Entry:
+		res = v
+	case RegFunc:
+		res = v
+	case *func([]byte) map[string]Entry:
+		if v != nil {
+			res = *v
+		}
+	case *RegFunc:
+		if v != nil {
+			res = *v
+		}
+	default:
+		return nil, fmt.Errorf("expect func(raw []byte) map[string]rkentry.Entry, got %T", sym)
+	}
+
+	if res == nil {
+		return nil, fmt.Errorf("nil registration function")
+	}
+
+	return res, nil
+}
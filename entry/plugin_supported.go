@@ -0,0 +1,30 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+//go:build (linux || darwin || freebsd) && cgo
+
+package rkentry
+
+import (
+	"fmt"
+	"plugin"
+)
+
+// lookupPluginSymbol opens plugin of path and looks up symbol with name
+func lookupPluginSymbol(path, name string) (interface{}, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		// mismatched versions of Go or shared packages are reported by plugin.Open
+		return nil, fmt.Errorf("failed to open plugin, path:%s, plugin must be built with the same Go version "+
+			"and versions of shared packages as application, %v", path, err)
+	}
+
+	sym, err := p.Lookup(name)
+	if err != nil {
+		return nil, fmt.Errorf("symbol is missing in plugin, path:%s, symbol:%s, %v", path, name, err)
+	}
+
+	return sym, nil
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadPlugin(t *testing.T) {
+	before := len(ListPluginEntryRegFunc())
+
+	// missing file
+	err := LoadPlugin(filepath.Join(t.TempDir(), "missing.so"))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "missing.so")
+	assert.Len(t, ListPluginEntryRegFunc(), before)
+}
+
+func TestRegFuncOfPluginSymbol(t *testing.T) {
+	f := func([]byte) map[string]Entry {
+		return map[string]Entry{}
+	}
+
+	// function
+	res, err := regFuncOfPluginSymbol(f)
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+
+	// variable
+	var regFunc RegFunc = f
+	res, err = regFuncOfPluginSymbol(&regFunc)
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+
+	// nil variable
+	var nilFunc RegFunc
+	_, err = regFuncOfPluginSymbol(&nilFunc)
+	assert.NotNil(t, err)
+
+	// invalid type
+	_, err = regFuncOfPluginSymbol("ut-symbol")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "string")
+}
@@ -0,0 +1,18 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+//go:build !((linux || darwin || freebsd) && cgo)
+
+package rkentry
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// lookupPluginSymbol returns error since Go plugin is not supported
+func lookupPluginSymbol(path, name string) (interface{}, error) {
+	return nil, fmt.Errorf("go plugin is not supported on %s/%s without cgo, path:%s", runtime.GOOS, runtime.GOARCH, path)
+}
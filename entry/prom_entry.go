@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
+	"fmt"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/push"
@@ -22,19 +23,52 @@ func WithRegistryPromEntry(registry *prometheus.Registry) PromEntryOption {
 	}
 }
 
-// RegisterPromEntry Create a prom entry with options and add prom entry to rkentry.GlobalAppCtx
+// WithNamePromEntry provide name of entry, named entry will be added to GlobalAppCtx, refer to RegisterPromEntry
+func WithNamePromEntry(name string) PromEntryOption {
+	return func(entry *PromEntry) {
+		if len(name) > 0 {
+			entry.entryName = name
+		}
+	}
+}
+
+// WithRuntimeMetricsPromEntry provide whether to register runtime/metrics of GC, memory and scheduler
+func WithRuntimeMetricsPromEntry(enabled bool) PromEntryOption {
+	return func(entry *PromEntry) {
+		entry.runtimeMetrics = enabled
+	}
+}
+
+// WithProcessMetricsPromEntry provide whether to register process metrics like CPU, memory and file descriptors
+func WithProcessMetricsPromEntry(enabled bool) PromEntryOption {
+	return func(entry *PromEntry) {
+		entry.processMetrics = enabled
+	}
+}
+
+// RegisterPromEntry Create a prom entry with options.
+//
+// Entry with name provided by boot config or WithNamePromEntry will be added to rkentry.GlobalAppCtx,
+// each of them has its own registry, use GlobalAppCtx.GetPromEntry to resolve it by name.
+// Named entries with the same name or path will cause shutdown.
 func RegisterPromEntry(boot *BootProm, opts ...PromEntryOption) *PromEntry {
 	if !boot.Enabled {
 		return nil
 	}
 
 	entry := &PromEntry{
-		entryName:        "PromEntry",
+		entryName:        promEntryNameDefault,
 		entryType:        PromEntryType,
 		entryDescription: "Internal RK entry which implements prometheus client.",
 		Path:             boot.Path,
 		Registerer:       prometheus.DefaultRegisterer,
 		Gatherer:         prometheus.DefaultGatherer,
+		runtimeMetrics:   boot.Runtime.Enabled,
+		processMetrics:   boot.Runtime.Process,
+	}
+
+	if len(boot.Name) > 0 {
+		entry.entryName = boot.Name
 	}
 
 	for i := range opts {
@@ -44,13 +78,25 @@ func RegisterPromEntry(boot *BootProm, opts ...PromEntryOption) *PromEntry {
 	if entry.Registry == nil {
 		entry.Registry = prometheus.NewRegistry()
 	}
-	entry.Registry.Register(collectors.NewGoCollector())
+	if entry.runtimeMetrics {
+		entry.Registry.Register(collectors.NewGoCollector(
+			collectors.WithGoCollectorRuntimeMetrics(collectors.MetricsGC, collectors.MetricsMemory, collectors.MetricsScheduler)))
+	} else {
+		entry.Registry.Register(collectors.NewGoCollector())
+	}
+
+	if entry.processMetrics {
+		entry.Registry.Register(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	}
 
 	if entry.Registry != nil {
 		entry.Registerer = entry.Registry
 		entry.Gatherer = entry.Registry
 	}
 
+	// lifecycle state of entries, collector is shared by registries of all PromEntry
+	entry.Registerer.Register(entryStateGauge)
+
 	// Trim space by default
 	entry.Path = strings.TrimSpace(entry.Path)
 
@@ -65,14 +111,42 @@ func RegisterPromEntry(boot *BootProm, opts ...PromEntryOption) *PromEntry {
 
 	entry.Pusher = newPushGatewayPusher(boot, entry.Gatherer)
 
+	if entry.entryName != promEntryNameDefault {
+		for _, v := range GlobalAppCtx.ListEntriesByType(PromEntryType) {
+			registered, ok := v.(*PromEntry)
+			if !ok {
+				continue
+			}
+
+			if registered.entryName == entry.entryName {
+				ShutdownWithError(fmt.Errorf("duplicate name of prom entry, name:%s", entry.entryName))
+			}
+
+			if registered.Path == entry.Path {
+				ShutdownWithError(fmt.Errorf("duplicate path of prom entry, name:%s, path:%s, registered by:%s",
+					entry.entryName, entry.Path, registered.entryName))
+			}
+		}
+
+		GlobalAppCtx.AddEntry(entry)
+	}
+
 	return entry
 }
 
+// promEntryNameDefault is name of prom entry which is not added to GlobalAppCtx
+const promEntryNameDefault = "PromEntry"
+
 // BootProm Boot config which is for prom entry.
 type BootProm struct {
 	Enabled bool   `yaml:"enabled" json:"enabled"`
+	Name    string `yaml:"name" json:"name"`
 	Path    string `yaml:"path" json:"path"`
-	Pusher  struct {
+	Runtime struct {
+		Enabled bool `yaml:"enabled" json:"enabled"`
+		Process bool `yaml:"process" json:"process"`
+	} `yaml:"runtime" json:"runtime"`
+	Pusher struct {
 		Enabled       bool   `yaml:"enabled" json:"enabled"`
 		IntervalMs    int64  `yaml:"IntervalMs" json:"IntervalMs"`
 		JobName       string `yaml:"jobName" json:"jobName"`
@@ -94,6 +168,8 @@ type PromEntry struct {
 	entryDescription string             `json:"-" yaml:"-"`
 	Path             string             `json:"-" yaml:"-"`
 	Pusher           *PushGatewayPusher `json:"-" yaml:"-"`
+	runtimeMetrics   bool               `json:"-" yaml:"-"`
+	processMetrics   bool               `json:"-" yaml:"-"`
 }
 
 type PromEntryOption func(entry *PromEntry)
@@ -128,6 +204,21 @@ func (entry *PromEntry) GetDescription() string {
 	return entry.entryDescription
 }
 
+// GetRefs Return names of CertEntry and LoggerEntry used by pusher
+func (entry *PromEntry) GetRefs() []string {
+	res := make([]string, 0)
+	if entry.Pusher != nil {
+		if entry.Pusher.certEntry != nil {
+			res = append(res, entry.Pusher.certEntry.GetName())
+		}
+		if entry.Pusher.loggerEntry != nil {
+			res = append(res, entry.Pusher.loggerEntry.GetName())
+		}
+	}
+
+	return res
+}
+
 // String Stringfy prom entry
 func (entry *PromEntry) String() string {
 	bytes, _ := json.Marshal(entry)
@@ -141,6 +232,8 @@ func (entry *PromEntry) MarshalJSON() ([]byte, error) {
 		"type":              entry.entryType,
 		"description":       entry.entryDescription,
 		"pushGateWayPusher": entry.Pusher,
+		"runtimeMetrics":    entry.runtimeMetrics,
+		"processMetrics":    entry.processMetrics,
 	}
 
 	return json.Marshal(&m)
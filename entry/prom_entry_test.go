@@ -48,6 +48,64 @@ func TestRegisterPromEntry(t *testing.T) {
 	assert.Nil(t, entry.Pusher)
 }
 
+func TestRegisterPromEntry_WithName(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntryByType(PromEntryType)
+
+	// unnamed entry is not added
+	RegisterPromEntry(&BootProm{Enabled: true})
+	assert.Empty(t, GlobalAppCtx.ListEntriesByType(PromEntryType))
+
+	left := RegisterPromEntry(&BootProm{Enabled: true, Name: "ut-prom-left", Path: "/left"})
+	right := RegisterPromEntry(&BootProm{Enabled: true, Path: "/right"}, WithNamePromEntry("ut-prom-right"))
+	assert.Equal(t, left, GlobalAppCtx.GetPromEntry("ut-prom-left"))
+	assert.Equal(t, right, GlobalAppCtx.GetPromEntry("ut-prom-right"))
+	assert.Nil(t, GlobalAppCtx.GetPromEntry("ut-prom-missing"))
+
+	// registries are isolated
+	GlobalAppCtx.GetPromEntry("ut-prom-left").RegisterCollectors(prometheus.NewCounter(prometheus.CounterOpts{Name: "ut_counter"}))
+	assert.Nil(t, right.Register(prometheus.NewCounter(prometheus.CounterOpts{Name: "ut_counter"})))
+
+	// duplicate path
+	func() {
+		defer assertPanic(t)
+		RegisterPromEntry(&BootProm{Enabled: true, Name: "ut-prom-dup", Path: "left"})
+	}()
+
+	// duplicate name
+	func() {
+		defer assertPanic(t)
+		RegisterPromEntry(&BootProm{Enabled: true, Name: "ut-prom-left", Path: "/other"})
+	}()
+}
+
+func TestRegisterPromEntry_WithRuntimeMetrics(t *testing.T) {
+	boot := &BootProm{
+		Enabled: true,
+	}
+	boot.Runtime.Enabled = true
+	boot.Runtime.Process = true
+	entry := RegisterPromEntry(boot)
+
+	families, err := entry.Gather()
+	assert.Nil(t, err)
+
+	names := make(map[string]bool)
+	for i := range families {
+		names[families[i].GetName()] = true
+	}
+	assert.True(t, names["go_goroutines"])
+	assert.True(t, names["go_gc_heap_allocs_bytes_total"])
+	assert.True(t, names["process_start_time_seconds"])
+
+	// disabled by option
+	entry = RegisterPromEntry(boot, WithProcessMetricsPromEntry(false))
+	families, err = entry.Gather()
+	assert.Nil(t, err)
+	for i := range families {
+		assert.NotEqual(t, "process_start_time_seconds", families[i].GetName())
+	}
+}
+
 func TestPromEntry_Bootstrap(t *testing.T) {
 	defer assertNotPanic(t)
 
@@ -91,3 +149,41 @@ func TestPromEntry_Bootstrap(t *testing.T) {
 
 	entry.Interrupt(context.TODO())
 }
+
+func TestRegisterPromEntry_WithEntryState(t *testing.T) {
+	defer GlobalAppCtx.RemoveEntry(&EntryMock{Name: "ut-state"})
+
+	entry := RegisterPromEntry(&BootProm{
+		Enabled: true,
+	})
+
+	mock := &EntryMock{Name: "ut-state"}
+	GlobalAppCtx.AddEntry(mock)
+	assert.Nil(t, GlobalAppCtx.transitEntry(mock, context.TODO(), true))
+
+	getState := func() float64 {
+		families, err := entry.Registry.Gather()
+		assert.Nil(t, err)
+		for _, family := range families {
+			if family.GetName() != "rk_entry_state" {
+				continue
+			}
+			for _, metric := range family.GetMetric() {
+				labels := make(map[string]string)
+				for _, label := range metric.GetLabel() {
+					labels[label.GetName()] = label.GetValue()
+				}
+				if labels["name"] == "ut-state" && labels["type"] == "mock" {
+					return metric.GetGauge().GetValue()
+				}
+			}
+		}
+		return -1
+	}
+
+	assert.Equal(t, EntryStateRunning.Value(), getState())
+
+	assert.Nil(t, GlobalAppCtx.transitEntry(mock, context.TODO(), false))
+	assert.Equal(t, EntryStateStopped.Value(), getState())
+	assert.Equal(t, float64(-1), EntryState("unknown").Value())
+}
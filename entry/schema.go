@@ -0,0 +1,595 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+	"math"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// schemaFetchTimeout is the max duration to fetch a remote schema
+const schemaFetchTimeout = 10 * time.Second
+
+// schemaCache keeps fetched schemas with key of URL
+type schemaCache struct {
+	lock       sync.Mutex
+	schemas    map[string]map[string]interface{}
+	failClosed bool
+	client     *http.Client
+}
+
+var remoteSchemaCache = &schemaCache{
+	schemas:    make(map[string]map[string]interface{}),
+	failClosed: true,
+	client:     &http.Client{Timeout: schemaFetchTimeout},
+}
+
+// SetSchemaFailClosed decides behavior of ValidateAgainstSchema while schema server is unreachable.
+//
+// Enabled by default, an error will be returned. Otherwise, a warning will be logged with default LoggerEntry
+// and config is treated as valid.
+func SetSchemaFailClosed(enabled bool) {
+	remoteSchemaCache.lock.Lock()
+	defer remoteSchemaCache.lock.Unlock()
+
+	remoteSchemaCache.failClosed = enabled
+}
+
+// ClearSchemaCache removes all schemas fetched by ValidateAgainstSchema.
+func ClearSchemaCache() {
+	remoteSchemaCache.lock.Lock()
+	defer remoteSchemaCache.lock.Unlock()
+
+	remoteSchemaCache.schemas = make(map[string]map[string]interface{})
+}
+
+// ValidateAgainstSchema validates boot config file against JSON Schema hosted at schemaURL, returns all violations.
+//
+// Imports of config file are resolved before validation, refer to ReadBootConfigFile.
+// Schema is fetched once and cached, refer to ClearSchemaCache. An error will be returned
+// if schema server is unreachable, unless SetSchemaFailClosed is disabled.
+//
+// Supported keywords: type, enum, const, properties, required, additionalProperties, patternProperties,
+// items, minItems, maxItems, uniqueItems, minLength, maxLength, pattern, minimum, maximum,
+// exclusiveMinimum, exclusiveMaximum, multipleOf, allOf, anyOf, oneOf, not and local $ref.
+// Annotations like $schema, $id, title, description, default, examples, format, definitions and $defs
+// are ignored. An error will be returned if schema contains any other keyword, or a $ref refers to
+// itself without consuming any value.
+// Namespace of FieldError is path of value with yaml names, like logger.0.name.
+func ValidateAgainstSchema(configPath, schemaURL string) ([]FieldError, error) {
+	bootM, err := readBootConfigWithImports(configPath, map[string]bool{}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := remoteSchemaCache.get(schemaURL)
+	if err != nil {
+		remoteSchemaCache.lock.Lock()
+		failClosed := remoteSchemaCache.failClosed
+		remoteSchemaCache.lock.Unlock()
+
+		if failClosed {
+			return nil, err
+		}
+
+		GlobalAppCtx.GetLoggerEntryDefault().Warn("Skip validating boot config against schema",
+			zap.String("schemaURL", schemaURL), zap.Error(err))
+		return make([]FieldError, 0), nil
+	}
+
+	if unsupported := unsupportedSchemaKeywords(schema, "#"); len(unsupported) > 0 {
+		return nil, fmt.Errorf("unsupported keywords in schema:%s, %s", schemaURL, strings.Join(unsupported, ", "))
+	}
+
+	v := &schemaValidator{
+		root: schema,
+		errs: make([]FieldError, 0),
+		refs: make(map[string]bool),
+	}
+	v.validate(schema, yamlToJSONValue(bootM), "")
+
+	if v.circular != "" {
+		return nil, fmt.Errorf("circular reference in schema:%s, %s", schemaURL, v.circular)
+	}
+
+	return v.errs, nil
+}
+
+// supportedSchemaKeywords are keywords validated by schemaValidator
+var supportedSchemaKeywords = map[string]bool{
+	"type": true, "enum": true, "const": true, "properties": true, "required": true,
+	"additionalProperties": true, "patternProperties": true, "items": true, "minItems": true,
+	"maxItems": true, "uniqueItems": true, "minLength": true, "maxLength": true, "pattern": true,
+	"minimum": true, "maximum": true, "exclusiveMinimum": true, "exclusiveMaximum": true,
+	"multipleOf": true, "allOf": true, "anyOf": true, "oneOf": true, "not": true, "$ref": true,
+}
+
+// annotationSchemaKeywords are keywords which do not affect validation and are ignored
+var annotationSchemaKeywords = map[string]bool{
+	"$schema": true, "$id": true, "id": true, "$comment": true, "title": true, "description": true,
+	"default": true, "examples": true, "format": true, "definitions": true, "$defs": true,
+}
+
+// unsupportedSchemaKeywords walks schema and all sub schemas, returns sorted pointers of unsupported keywords
+func unsupportedSchemaKeywords(schemaRaw interface{}, pointer string) []string {
+	res := make([]string, 0)
+
+	schema, ok := schemaRaw.(map[string]interface{})
+	if !ok {
+		return res
+	}
+
+	for key, val := range schema {
+		child := pointer + "/" + escapeSchemaToken(key)
+
+		switch key {
+		case "properties", "patternProperties", "definitions", "$defs":
+			if m, ok := val.(map[string]interface{}); ok {
+				for name := range m {
+					res = append(res, unsupportedSchemaKeywords(m[name], child+"/"+escapeSchemaToken(name))...)
+				}
+			}
+		case "allOf", "anyOf", "oneOf":
+			if list, ok := val.([]interface{}); ok {
+				for i := range list {
+					res = append(res, unsupportedSchemaKeywords(list[i], child+"/"+strconv.Itoa(i))...)
+				}
+			}
+		case "items":
+			// tuple validation of items is not supported
+			if _, ok := val.([]interface{}); ok {
+				res = append(res, child)
+				continue
+			}
+			res = append(res, unsupportedSchemaKeywords(val, child)...)
+		case "additionalProperties", "not":
+			res = append(res, unsupportedSchemaKeywords(val, child)...)
+		default:
+			if !supportedSchemaKeywords[key] && !annotationSchemaKeywords[key] {
+				res = append(res, child)
+			}
+		}
+	}
+
+	sort.Strings(res)
+	return res
+}
+
+// get returns cached schema or fetches it
+func (c *schemaCache) get(schemaURL string) (map[string]interface{}, error) {
+	c.lock.Lock()
+	schema, ok := c.schemas[schemaURL]
+	client := c.client
+	c.lock.Unlock()
+
+	if ok {
+		return schema, nil
+	}
+
+	resp, err := client.Get(schemaURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch schema:%s, %v", schemaURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch schema:%s, status:%d", schemaURL, resp.StatusCode)
+	}
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch schema:%s, %v", schemaURL, err)
+	}
+
+	schema = make(map[string]interface{})
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal schema:%s, %v", schemaURL, err)
+	}
+
+	c.lock.Lock()
+	c.schemas[schemaURL] = schema
+	c.lock.Unlock()
+
+	return schema, nil
+}
+
+// yamlToJSONValue converts value produced by yaml.Unmarshal into value produced by encoding/json,
+// so that it could be validated against JSON Schema.
+func yamlToJSONValue(in interface{}) interface{} {
+	switch v := in.(type) {
+	case map[interface{}]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for k := range v {
+			res[fmt.Sprintf("%v", k)] = yamlToJSONValue(v[k])
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, 0, len(v))
+		for i := range v {
+			res = append(res, yamlToJSONValue(v[i]))
+		}
+		return res
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	default:
+		return in
+	}
+}
+
+// schemaValidator validates value against a subset of JSON Schema and collects violations
+type schemaValidator struct {
+	root map[string]interface{}
+	errs []FieldError
+	// refs being resolved with key of ref@path, shared with sub validators
+	refs map[string]bool
+	// circular is the first ref found referring to itself
+	circular string
+}
+
+// addErr records a violation of keyword at path
+func (v *schemaValidator) addErr(path, keyword string, param, value interface{}) {
+	field := path
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		field = path[i+1:]
+	}
+
+	paramStr := ""
+	if param != nil {
+		paramStr = fmt.Sprintf("%v", param)
+	}
+
+	v.errs = append(v.errs, FieldError{
+		Namespace: path,
+		Field:     field,
+		Tag:       keyword,
+		Param:     paramStr,
+		Value:     value,
+	})
+}
+
+// matches returns true if value is valid against schema without recording violations
+func (v *schemaValidator) matches(schema interface{}, value interface{}, path string) bool {
+	sub := &schemaValidator{
+		root: v.root,
+		errs: make([]FieldError, 0),
+		refs: v.refs,
+	}
+	sub.validate(schema, value, path)
+
+	if v.circular == "" {
+		v.circular = sub.circular
+	}
+
+	return len(sub.errs) < 1
+}
+
+// validate validates value at path against schema
+func (v *schemaValidator) validate(schemaRaw interface{}, value interface{}, path string) {
+	// boolean schema
+	if b, ok := schemaRaw.(bool); ok {
+		if !b {
+			v.addErr(path, "false", nil, value)
+		}
+		return
+	}
+
+	schema, ok := schemaRaw.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		target, err := v.resolveRef(ref)
+		if err != nil {
+			v.addErr(path, "$ref", ref, err.Error())
+			return
+		}
+
+		// the same ref is resolved again at the same path, it would never end
+		key := ref + "@" + path
+		if v.refs[key] {
+			if v.circular == "" {
+				v.circular = ref
+			}
+			return
+		}
+
+		v.refs[key] = true
+		v.validate(target, value, path)
+		delete(v.refs, key)
+
+		if v.circular != "" {
+			return
+		}
+	}
+
+	if t, ok := schema["type"]; ok && !matchSchemaType(t, value) {
+		v.addErr(path, "type", t, value)
+		return
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for i := range enum {
+			found = found || reflect.DeepEqual(enum[i], value)
+		}
+		if !found {
+			v.addErr(path, "enum", enum, value)
+		}
+	}
+
+	if c, ok := schema["const"]; ok && !reflect.DeepEqual(c, value) {
+		v.addErr(path, "const", c, value)
+	}
+
+	switch val := value.(type) {
+	case map[string]interface{}:
+		v.validateObject(schema, val, path)
+	case []interface{}:
+		v.validateArray(schema, val, path)
+	case string:
+		v.validateString(schema, val, path)
+	case float64:
+		v.validateNumber(schema, val, path)
+	}
+
+	if all, ok := schema["allOf"].([]interface{}); ok {
+		for i := range all {
+			v.validate(all[i], value, path)
+		}
+	}
+
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		matched := false
+		for i := range anyOf {
+			matched = matched || v.matches(anyOf[i], value, path)
+		}
+		if !matched {
+			v.addErr(path, "anyOf", nil, value)
+		}
+	}
+
+	if one, ok := schema["oneOf"].([]interface{}); ok {
+		matched := 0
+		for i := range one {
+			if v.matches(one[i], value, path) {
+				matched++
+			}
+		}
+		if matched != 1 {
+			v.addErr(path, "oneOf", nil, value)
+		}
+	}
+
+	if not, ok := schema["not"]; ok && v.matches(not, value, path) {
+		v.addErr(path, "not", nil, value)
+	}
+}
+
+// validateObject validates keywords of object
+func (v *schemaValidator) validateObject(schema map[string]interface{}, value map[string]interface{}, path string) {
+	if required, ok := schema["required"].([]interface{}); ok {
+		for i := range required {
+			key := fmt.Sprintf("%v", required[i])
+			if _, ok := value[key]; !ok {
+				v.addErr(joinSchemaPath(path, key), "required", nil, nil)
+			}
+		}
+	}
+
+	props, _ := schema["properties"].(map[string]interface{})
+	patterns, _ := schema["patternProperties"].(map[string]interface{})
+	additional, hasAdditional := schema["additionalProperties"]
+
+	keys := make([]string, 0, len(value))
+	for k := range value {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		childPath := joinSchemaPath(path, key)
+		matched := false
+
+		if prop, ok := props[key]; ok {
+			matched = true
+			v.validate(prop, value[key], childPath)
+		}
+
+		for pattern, prop := range patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				v.addErr(childPath, "patternProperties", pattern, err.Error())
+				continue
+			}
+			if re.MatchString(key) {
+				matched = true
+				v.validate(prop, value[key], childPath)
+			}
+		}
+
+		if !matched && hasAdditional {
+			if b, ok := additional.(bool); ok && !b {
+				v.addErr(childPath, "additionalProperties", false, value[key])
+			} else {
+				v.validate(additional, value[key], childPath)
+			}
+		}
+	}
+}
+
+// validateArray validates keywords of array
+func (v *schemaValidator) validateArray(schema map[string]interface{}, value []interface{}, path string) {
+	if min, ok := schema["minItems"].(float64); ok && float64(len(value)) < min {
+		v.addErr(path, "minItems", min, len(value))
+	}
+
+	if max, ok := schema["maxItems"].(float64); ok && float64(len(value)) > max {
+		v.addErr(path, "maxItems", max, len(value))
+	}
+
+	if unique, ok := schema["uniqueItems"].(bool); ok && unique {
+		for i := range value {
+			for j := i + 1; j < len(value); j++ {
+				if reflect.DeepEqual(value[i], value[j]) {
+					v.addErr(joinSchemaPath(path, strconv.Itoa(j)), "uniqueItems", nil, value[j])
+				}
+			}
+		}
+	}
+
+	if items, ok := schema["items"]; ok {
+		for i := range value {
+			v.validate(items, value[i], joinSchemaPath(path, strconv.Itoa(i)))
+		}
+	}
+}
+
+// validateString validates keywords of string
+func (v *schemaValidator) validateString(schema map[string]interface{}, value string, path string) {
+	length := float64(utf8.RuneCountInString(value))
+
+	if min, ok := schema["minLength"].(float64); ok && length < min {
+		v.addErr(path, "minLength", min, value)
+	}
+
+	if max, ok := schema["maxLength"].(float64); ok && length > max {
+		v.addErr(path, "maxLength", max, value)
+	}
+
+	if pattern, ok := schema["pattern"].(string); ok {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			v.addErr(path, "pattern", pattern, err.Error())
+		} else if !re.MatchString(value) {
+			v.addErr(path, "pattern", pattern, value)
+		}
+	}
+}
+
+// validateNumber validates keywords of number
+func (v *schemaValidator) validateNumber(schema map[string]interface{}, value float64, path string) {
+	if min, ok := schema["minimum"].(float64); ok && value < min {
+		v.addErr(path, "minimum", min, value)
+	}
+
+	if max, ok := schema["maximum"].(float64); ok && value > max {
+		v.addErr(path, "maximum", max, value)
+	}
+
+	if min, ok := schema["exclusiveMinimum"].(float64); ok && value <= min {
+		v.addErr(path, "exclusiveMinimum", min, value)
+	}
+
+	if max, ok := schema["exclusiveMaximum"].(float64); ok && value >= max {
+		v.addErr(path, "exclusiveMaximum", max, value)
+	}
+
+	if m, ok := schema["multipleOf"].(float64); ok && m > 0 {
+		if q := value / m; math.Abs(q-math.Round(q)) > 1e-9 {
+			v.addErr(path, "multipleOf", m, value)
+		}
+	}
+}
+
+// resolveRef resolves local reference like #/definitions/logger in root schema
+func (v *schemaValidator) resolveRef(ref string) (interface{}, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("only local reference is supported")
+	}
+
+	var res interface{} = v.root
+	for _, token := range strings.Split(strings.TrimPrefix(strings.TrimPrefix(ref, "#"), "/"), "/") {
+		if len(token) < 1 {
+			continue
+		}
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+
+		m, ok := res.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("reference not found")
+		}
+		if res, ok = m[token]; !ok {
+			return nil, fmt.Errorf("reference not found")
+		}
+	}
+
+	return res, nil
+}
+
+// matchSchemaType returns true if value matches type keyword which is a string or list of strings
+func matchSchemaType(t interface{}, value interface{}) bool {
+	types := make([]string, 0)
+	switch tv := t.(type) {
+	case string:
+		types = append(types, tv)
+	case []interface{}:
+		for i := range tv {
+			types = append(types, fmt.Sprintf("%v", tv[i]))
+		}
+	}
+
+	for _, name := range types {
+		switch val := value.(type) {
+		case nil:
+			if name == "null" {
+				return true
+			}
+		case bool:
+			if name == "boolean" {
+				return true
+			}
+		case string:
+			if name == "string" {
+				return true
+			}
+		case float64:
+			if name == "number" || (name == "integer" && val == math.Trunc(val)) {
+				return true
+			}
+		case []interface{}:
+			if name == "array" {
+				return true
+			}
+		case map[string]interface{}:
+			if name == "object" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// escapeSchemaToken escapes token of JSON pointer
+func escapeSchemaToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// joinSchemaPath appends key to path with dot
+func joinSchemaPath(path, key string) string {
+	if len(path) < 1 {
+		return key
+	}
+
+	return path + "." + key
+}
@@ -0,0 +1,235 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+const utBootSchema = `{
+  "type": "object",
+  "required": ["app"],
+  "properties": {
+    "app": {
+      "type": "object",
+      "required": ["name"],
+      "properties": {
+        "name": {"type": "string", "pattern": "^[a-z-]+$"},
+        "version": {"$ref": "#/definitions/version"}
+      }
+    },
+    "logger": {
+      "type": "array",
+      "maxItems": 1,
+      "items": {
+        "type": "object",
+        "additionalProperties": false,
+        "properties": {
+          "name": {"type": "string", "minLength": 3},
+          "level": {"enum": ["debug", "info"]},
+          "maxSize": {"type": "integer", "minimum": 1}
+        }
+      }
+    }
+  },
+  "definitions": {
+    "version": {"type": "string"}
+  }
+}`
+
+func TestValidateAgainstSchema(t *testing.T) {
+	defer ClearSchemaCache()
+	defer SetSchemaFailClosed(true)
+	ClearSchemaCache()
+
+	fetched := int32(0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetched, 1)
+		w.Write([]byte(utBootSchema))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	validPath := filepath.Join(dir, "valid.yaml")
+	assert.Nil(t, os.WriteFile(validPath, []byte(`
+app:
+  name: ut-app
+  version: v1
+logger:
+  - name: ut-logger
+    level: info
+    maxSize: 10
+`), 0644))
+
+	invalidPath := filepath.Join(dir, "invalid.yaml")
+	assert.Nil(t, os.WriteFile(invalidPath, []byte(`
+app:
+  version: 1
+logger:
+  - name: ut
+    level: warn
+    maxSize: 1.5
+    unknown: true
+  - name: ut-logger
+`), 0644))
+
+	// valid config
+	errs, err := ValidateAgainstSchema(validPath, server.URL)
+	assert.Nil(t, err)
+	assert.Empty(t, errs)
+
+	// invalid config with cached schema
+	errs, err = ValidateAgainstSchema(invalidPath, server.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetched))
+
+	namespaces := make(map[string]string)
+	for i := range errs {
+		namespaces[errs[i].Namespace] = errs[i].Tag
+	}
+	assert.Equal(t, map[string]string{
+		"app.name":         "required",
+		"app.version":      "type",
+		"logger":           "maxItems",
+		"logger.0.name":    "minLength",
+		"logger.0.level":   "enum",
+		"logger.0.maxSize": "type",
+		"logger.0.unknown": "additionalProperties",
+	}, namespaces)
+
+	// missing config
+	_, err = ValidateAgainstSchema(filepath.Join(dir, "missing.yaml"), server.URL)
+	assert.NotNil(t, err)
+}
+
+func TestValidateAgainstSchema_Unreachable(t *testing.T) {
+	defer ClearSchemaCache()
+	defer SetSchemaFailClosed(true)
+	ClearSchemaCache()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	configPath := filepath.Join(t.TempDir(), "boot.yaml")
+	assert.Nil(t, os.WriteFile(configPath, []byte("app:\n  name: ut-app\n"), 0644))
+
+	// fail closed
+	errs, err := ValidateAgainstSchema(configPath, server.URL)
+	assert.NotNil(t, err)
+	assert.Nil(t, errs)
+
+	// fail open
+	SetSchemaFailClosed(false)
+	errs, err = ValidateAgainstSchema(configPath, server.URL)
+	assert.Nil(t, err)
+	assert.Empty(t, errs)
+}
+
+func TestValidateAgainstSchema_WithRecursiveRef(t *testing.T) {
+	defer ClearSchemaCache()
+	ClearSchemaCache()
+
+	schemas := map[string]string{
+		// recursive schema which consumes value on each level
+		"/tree": `{
+  "$ref": "#/definitions/node",
+  "definitions": {
+    "node": {
+      "type": "object",
+      "properties": {
+        "name": {"type": "string"},
+        "children": {"type": "array", "items": {"$ref": "#/definitions/node"}}
+      }
+    }
+  }
+}`,
+		// schema refers to itself directly
+		"/self": `{"$ref": "#"}`,
+		// schemas refer to each other
+		"/loop": `{
+  "anyOf": [{"$ref": "#/definitions/a"}],
+  "definitions": {
+    "a": {"$ref": "#/definitions/b"},
+    "b": {"allOf": [{"$ref": "#/definitions/a"}]}
+  }
+}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(schemas[r.URL.Path]))
+	}))
+	defer server.Close()
+
+	configPath := filepath.Join(t.TempDir(), "boot.yaml")
+	assert.Nil(t, os.WriteFile(configPath, []byte(`
+name: root
+children:
+  - name: child
+    children:
+      - name: 1
+`), 0644))
+
+	errs, err := ValidateAgainstSchema(configPath, server.URL+"/tree")
+	assert.Nil(t, err)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "children.0.children.0.name", errs[0].Namespace)
+	assert.Equal(t, "type", errs[0].Tag)
+
+	errs, err = ValidateAgainstSchema(configPath, server.URL+"/self")
+	assert.NotNil(t, err)
+	assert.Nil(t, errs)
+
+	errs, err = ValidateAgainstSchema(configPath, server.URL+"/loop")
+	assert.NotNil(t, err)
+	assert.Nil(t, errs)
+}
+
+func TestValidateAgainstSchema_WithUnsupportedKeyword(t *testing.T) {
+	defer ClearSchemaCache()
+	ClearSchemaCache()
+
+	schemas := map[string]string{
+		"/annotation": `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "title": "boot",
+  "description": "boot config",
+  "properties": {"app": {"type": "object", "default": {}, "format": "any"}}
+}`,
+		"/unsupported": `{
+  "minProperties": 1,
+  "properties": {
+    "app": {"type": "object", "propertyNames": {"pattern": "^[a-z]+$"}},
+    "logger": {"items": [{"type": "object"}]}
+  }
+}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(schemas[r.URL.Path]))
+	}))
+	defer server.Close()
+
+	configPath := filepath.Join(t.TempDir(), "boot.yaml")
+	assert.Nil(t, os.WriteFile(configPath, []byte("app:\n  name: ut-app\n"), 0644))
+
+	// annotations are ignored
+	errs, err := ValidateAgainstSchema(configPath, server.URL+"/annotation")
+	assert.Nil(t, err)
+	assert.Empty(t, errs)
+
+	errs, err = ValidateAgainstSchema(configPath, server.URL+"/unsupported")
+	assert.Nil(t, errs)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "#/minProperties")
+	assert.Contains(t, err.Error(), "#/properties/app/propertyNames")
+	assert.Contains(t, err.Error(), "#/properties/logger/items")
+}
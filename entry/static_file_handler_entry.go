@@ -22,6 +22,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -64,15 +65,27 @@ var exToIcon = map[string]string{
 	"unknown": "file.png",
 }
 
+const (
+	// StaticFileModeBrowse lists directories with web UI and makes browser download files
+	StaticFileModeBrowse = "browse"
+	// StaticFileModeServe serves files as they are, like OpenAPI specs and docs UI
+	StaticFileModeServe = "serve"
+)
+
 // BootStaticFileHandler bootstrap config of StaticHandler.
+//
+// Index is file served for missing paths in serve mode, like index.html for SPA routing.
 type BootStaticFileHandler struct {
 	Enabled    bool   `yaml:"enabled" json:"enabled"`
 	Path       string `yaml:"path" json:"path"`
 	SourceType string `yaml:"sourceType" json:"sourceType"`
 	SourcePath string `yaml:"sourcePath" json:"sourcePath"`
+	Mode       string `yaml:"mode" json:"mode"`
+	Index      string `yaml:"index" json:"index"`
 }
 
-// StaticFileHandlerEntry Static file handler entry supports web UI for downloading static files.
+// StaticFileHandlerEntry Static file handler entry supports web UI for downloading static files,
+// or serving static files like OpenAPI specs and docs UI.
 type StaticFileHandlerEntry struct {
 	entryName        string             `yaml:"-" json:"-"`
 	entryType        string             `yaml:"-" json:"-"`
@@ -80,6 +93,11 @@ type StaticFileHandlerEntry struct {
 	Path             string             `yaml:"-" json:"-"`
 	Template         *template.Template `json:"-" yaml:"-"`
 	httpFS           http.FileSystem    `yaml:"-" json:"-"`
+	mode             string             `yaml:"-" json:"-"`
+	index            string             `yaml:"-" json:"-"`
+	closeLock        sync.Mutex         `yaml:"-" json:"-"`
+	closed           bool               `yaml:"-" json:"-"`
+	inflight         sync.WaitGroup     `yaml:"-" json:"-"`
 }
 
 // StaticFileHandlerEntryOption options for StaticFileHandlerEntry
@@ -105,6 +123,8 @@ func RegisterStaticFileHandlerEntry(boot *BootStaticFileHandler, opts ...StaticF
 		Template:         template.New("rk-static"),
 		Path:             boot.Path,
 		httpFS:           http.Dir(""),
+		mode:             boot.Mode,
+		index:            boot.Index,
 	}
 
 	for i := range opts {
@@ -124,6 +144,14 @@ func RegisterStaticFileHandlerEntry(boot *BootStaticFileHandler, opts ...StaticF
 		entry.httpFS = http.Dir(boot.SourcePath)
 	}
 
+	switch entry.mode {
+	case "":
+		entry.mode = StaticFileModeBrowse
+	case StaticFileModeBrowse, StaticFileModeServe:
+	default:
+		ShutdownWithError(fmt.Errorf("invalid mode of static file handler, mode:%s", entry.mode))
+	}
+
 	if len(entry.Path) < 1 {
 		entry.Path = "/static"
 	}
@@ -147,11 +175,31 @@ func (entry *StaticFileHandlerEntry) Bootstrap(context.Context) {
 	if _, err := entry.Template.Parse(string(readFile("assets/static/index.tmpl", &rkembed.AssetsFS, true))); err != nil {
 		ShutdownWithError(err)
 	}
+
+	entry.closeLock.Lock()
+	entry.closed = false
+	entry.closeLock.Unlock()
 }
 
 // Interrupt entry.
-func (entry *StaticFileHandlerEntry) Interrupt(context.Context) {
-	// Noop
+//
+// Requests received afterwards will be rejected with 503, in-flight requests will be waited until ctx is done.
+func (entry *StaticFileHandlerEntry) Interrupt(ctx context.Context) {
+	// no request could be added to inflight after closed is set
+	entry.closeLock.Lock()
+	entry.closed = true
+	entry.closeLock.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		entry.inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
 }
 
 // GetName Get name of entry.
@@ -182,6 +230,8 @@ func (entry *StaticFileHandlerEntry) MarshalJSON() ([]byte, error) {
 		"type":        entry.GetType(),
 		"description": entry.GetDescription(),
 		"path":        entry.Path,
+		"mode":        entry.mode,
+		"index":       entry.index,
 	}
 
 	return json.Marshal(m)
@@ -192,80 +242,165 @@ func (entry *StaticFileHandlerEntry) UnmarshalJSON([]byte) error {
 	return nil
 }
 
+// Mount registers file handler to mux with Path.
+func (entry *StaticFileHandlerEntry) Mount(mux *http.ServeMux) {
+	mux.Handle(entry.Path, entry.GetFileHandler())
+}
+
 // GetFileHandler handles requests sent from user.
 func (entry *StaticFileHandlerEntry) GetFileHandler() http.HandlerFunc {
 	return func(writer http.ResponseWriter, request *http.Request) {
-		if !strings.HasSuffix(request.URL.Path, "/") {
-			request.URL.Path = request.URL.Path + "/"
+		entry.closeLock.Lock()
+		if entry.closed {
+			entry.closeLock.Unlock()
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			bytes, _ := json.Marshal(rkmid.GetErrorBuilder().New(http.StatusServiceUnavailable, "Static file handler is closed"))
+			writer.Write(bytes)
+			return
 		}
 
-		// Trim prefix with path user defined in order to get file path
-		p := strings.TrimSuffix(strings.TrimPrefix(request.URL.Path, entry.Path), "/")
+		entry.inflight.Add(1)
+		entry.closeLock.Unlock()
+		defer entry.inflight.Done()
 
-		if len(p) < 1 {
-			p = "/"
+		if entry.mode == StaticFileModeServe {
+			entry.serveFile(writer, request)
+		} else {
+			entry.browseFile(writer, request)
 		}
-		p = path.Join("/", p)
+	}
+}
 
-		var file http.File
-		var err error
-		// open file
-		if file, err = entry.httpFS.Open(p); err != nil {
-			writer.WriteHeader(http.StatusInternalServerError)
-			bytes, _ := json.Marshal(rkmid.GetErrorBuilder().New(http.StatusInternalServerError, "Failed to open file", err))
-			writer.Write(bytes)
-			return
+// serveFile serves file as it is, index.html will be served for directory,
+// and index will be served if file is missing.
+func (entry *StaticFileHandlerEntry) serveFile(writer http.ResponseWriter, request *http.Request) {
+	p := path.Join("/", strings.TrimPrefix(request.URL.Path, entry.Path))
+
+	file, fileInfo, err := entry.openFile(p)
+	if err != nil && len(entry.index) > 0 {
+		file, fileInfo, err = entry.openFile(path.Join("/", entry.index))
+	}
+
+	if err != nil {
+		writer.WriteHeader(http.StatusNotFound)
+		bytes, _ := json.Marshal(rkmid.GetErrorBuilder().New(http.StatusNotFound, "File not found", err))
+		writer.Write(bytes)
+		return
+	}
+	defer file.Close()
+
+	http.ServeContent(writer, request, fileInfo.Name(), fileInfo.ModTime(), file)
+}
+
+// openFile opens file with path, index.html will be opened if path is directory
+func (entry *StaticFileHandlerEntry) openFile(p string) (http.File, fs.FileInfo, error) {
+	file, err := entry.httpFS.Open(p)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+
+	if fileInfo.IsDir() {
+		file.Close()
+		return entry.openIndexOfDir(p)
+	}
+
+	return file, fileInfo, nil
+}
+
+// openIndexOfDir opens index.html in directory
+func (entry *StaticFileHandlerEntry) openIndexOfDir(p string) (http.File, fs.FileInfo, error) {
+	file, err := entry.httpFS.Open(path.Join(p, "index.html"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fileInfo, err := file.Stat()
+	if err != nil || fileInfo.IsDir() {
+		file.Close()
+		return nil, nil, fmt.Errorf("index.html is missing in directory, path:%s", p)
+	}
+
+	return file, fileInfo, nil
+}
+
+// browseFile lists files if path is directory, otherwise makes browser download file.
+func (entry *StaticFileHandlerEntry) browseFile(writer http.ResponseWriter, request *http.Request) {
+	if !strings.HasSuffix(request.URL.Path, "/") {
+		request.URL.Path = request.URL.Path + "/"
+	}
+
+	// Trim prefix with path user defined in order to get file path
+	p := strings.TrimSuffix(strings.TrimPrefix(request.URL.Path, entry.Path), "/")
+
+	if len(p) < 1 {
+		p = "/"
+	}
+	p = path.Join("/", p)
+
+	var file http.File
+	var err error
+	// open file
+	if file, err = entry.httpFS.Open(p); err != nil {
+		writer.WriteHeader(http.StatusInternalServerError)
+		bytes, _ := json.Marshal(rkmid.GetErrorBuilder().New(http.StatusInternalServerError, "Failed to open file", err))
+		writer.Write(bytes)
+		return
+	}
+
+	// get file info
+	fileInfo, err := file.Stat()
+	if err != nil {
+		writer.WriteHeader(http.StatusInternalServerError)
+		bytes, _ := json.Marshal(rkmid.GetErrorBuilder().New(http.StatusInternalServerError, "Failed to stat file", err))
+		writer.Write(bytes)
+		return
+	}
+
+	// list files if file is directory
+	if fileInfo.IsDir() {
+		infos, _ := file.Readdir(math.MaxInt32)
+		files := make([]*fileResp, 0)
+
+		for _, v := range infos {
+			files = append(files, &fileResp{
+				isDir:    v.IsDir(),
+				Icon:     base64.StdEncoding.EncodeToString(readFile(filepath.Join("assets/static/icons", entry.getIconPath(v)), &rkembed.AssetsFS, false)),
+				FileUrl:  path.Join(entry.Path, p, v.Name()),
+				FileName: v.Name(),
+				Size:     v.Size(),
+				ModTime:  v.ModTime(),
+			})
+		}
+
+		entry.sortFiles(files)
+		resp := &resp{
+			PrevPath: path.Join(entry.Path, filepath.Dir(p)),
+			PrevIcon: base64.StdEncoding.EncodeToString(readFile(filepath.Join("assets/static/icons/folder.png"), &rkembed.AssetsFS, false)),
+			Path:     p,
+			Files:    files,
 		}
 
-		// get file info
-		fileInfo, err := file.Stat()
-		if err != nil {
+		buf := new(bytes.Buffer)
+		if err := entry.Template.ExecuteTemplate(buf, "index", resp); err != nil {
 			writer.WriteHeader(http.StatusInternalServerError)
-			bytes, _ := json.Marshal(rkmid.GetErrorBuilder().New(http.StatusInternalServerError, "Failed to stat file", err))
+			bytes, _ := json.Marshal(rkmid.GetErrorBuilder().New(http.StatusInternalServerError, "Failed to execute go template", err))
 			writer.Write(bytes)
 			return
 		}
 
-		// list files if file is directory
-		if fileInfo.IsDir() {
-			infos, _ := file.Readdir(math.MaxInt32)
-			files := make([]*fileResp, 0)
-
-			for _, v := range infos {
-				files = append(files, &fileResp{
-					isDir:    v.IsDir(),
-					Icon:     base64.StdEncoding.EncodeToString(readFile(filepath.Join("assets/static/icons", entry.getIconPath(v)), &rkembed.AssetsFS, false)),
-					FileUrl:  path.Join(entry.Path, p, v.Name()),
-					FileName: v.Name(),
-					Size:     v.Size(),
-					ModTime:  v.ModTime(),
-				})
-			}
-
-			entry.sortFiles(files)
-			resp := &resp{
-				PrevPath: path.Join(entry.Path, filepath.Dir(p)),
-				PrevIcon: base64.StdEncoding.EncodeToString(readFile(filepath.Join("assets/static/icons/folder.png"), &rkembed.AssetsFS, false)),
-				Path:     p,
-				Files:    files,
-			}
-
-			buf := new(bytes.Buffer)
-			if err := entry.Template.ExecuteTemplate(buf, "index", resp); err != nil {
-				writer.WriteHeader(http.StatusInternalServerError)
-				bytes, _ := json.Marshal(rkmid.GetErrorBuilder().New(http.StatusInternalServerError, "Failed to execute go template", err))
-				writer.Write(bytes)
-				return
-			}
-
-			writer.WriteHeader(http.StatusOK)
-			writer.Write(buf.Bytes())
-		} else {
-			// make browser download file
-			writer.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", fileInfo.Name()))
-			writer.Header().Set("Content-Type", "application/octet-stream")
-			http.ServeContent(writer, request, filepath.Base(p), time.Now(), file)
-		}
+		writer.WriteHeader(http.StatusOK)
+		writer.Write(buf.Bytes())
+	} else {
+		// make browser download file
+		writer.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", fileInfo.Name()))
+		writer.Header().Set("Content-Type", "application/octet-stream")
+		http.ServeContent(writer, request, filepath.Base(p), time.Now(), file)
 	}
 }
 
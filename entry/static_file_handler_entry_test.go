@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 )
 
@@ -88,3 +89,103 @@ func TestStaticFileHandlerEntry_GetFileHandler(t *testing.T) {
 	assert.NotEmpty(t, writer.Header().Get("Content-Type"))
 	assert.Contains(t, writer.Body.String(), "ut content")
 }
+
+func TestStaticFileHandlerEntry_GetFileHandler_WithServeMode(t *testing.T) {
+	currDir := t.TempDir()
+	os.MkdirAll(filepath.Join(currDir, "ut-dir"), os.ModePerm)
+	os.WriteFile(filepath.Join(currDir, "index.html"), []byte("ut index"), os.ModePerm)
+	os.WriteFile(filepath.Join(currDir, "openapi.json"), []byte(`{"openapi":"3.0.0"}`), os.ModePerm)
+
+	entry := RegisterStaticFileHandlerEntry(&BootStaticFileHandler{
+		Enabled: true,
+		Path:    "/docs",
+		Mode:    StaticFileModeServe,
+		Index:   "index.html",
+	})
+	entry.httpFS = http.Dir(currDir)
+	entry.Bootstrap(context.TODO())
+
+	mux := http.NewServeMux()
+	entry.Mount(mux)
+
+	// expect to get file as it is
+	writer := httptest.NewRecorder()
+	mux.ServeHTTP(writer, httptest.NewRequest(http.MethodGet, "/docs/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Empty(t, writer.Header().Get("Content-Disposition"))
+	assert.Equal(t, `{"openapi":"3.0.0"}`, writer.Body.String())
+
+	// expect to get index of root
+	writer = httptest.NewRecorder()
+	mux.ServeHTTP(writer, httptest.NewRequest(http.MethodGet, "/docs/", nil))
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, "ut index", writer.Body.String())
+
+	// expect to fallback to index for missing file and directory without index.html
+	writer = httptest.NewRecorder()
+	mux.ServeHTTP(writer, httptest.NewRequest(http.MethodGet, "/docs/ut-route", nil))
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, "ut index", writer.Body.String())
+
+	writer = httptest.NewRecorder()
+	mux.ServeHTTP(writer, httptest.NewRequest(http.MethodGet, "/docs/ut-dir/", nil))
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, "ut index", writer.Body.String())
+
+	// expect to be rejected after interrupt
+	entry.Interrupt(context.TODO())
+	writer = httptest.NewRecorder()
+	mux.ServeHTTP(writer, httptest.NewRequest(http.MethodGet, "/docs/openapi.json", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, writer.Code)
+
+	// without index
+	entry = RegisterStaticFileHandlerEntry(&BootStaticFileHandler{
+		Enabled: true,
+		Mode:    StaticFileModeServe,
+	})
+	entry.httpFS = http.Dir(currDir)
+	writer = httptest.NewRecorder()
+	entry.GetFileHandler()(writer, httptest.NewRequest(http.MethodGet, "/static/ut-missing", nil))
+	assert.Equal(t, http.StatusNotFound, writer.Code)
+}
+
+func TestStaticFileHandlerEntry_Interrupt_WithConcurrentRequests(t *testing.T) {
+	currDir := t.TempDir()
+	os.WriteFile(filepath.Join(currDir, "openapi.json"), []byte(`{"openapi":"3.0.0"}`), os.ModePerm)
+
+	entry := RegisterStaticFileHandlerEntry(&BootStaticFileHandler{
+		Enabled: true,
+		Mode:    StaticFileModeServe,
+	})
+	entry.httpFS = http.Dir(currDir)
+	entry.Bootstrap(context.TODO())
+	handler := entry.GetFileHandler()
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			writer := httptest.NewRecorder()
+			handler(writer, httptest.NewRequest(http.MethodGet, "/static/openapi.json", nil))
+			assert.Contains(t, []int{http.StatusOK, http.StatusServiceUnavailable}, writer.Code)
+		}()
+	}
+
+	entry.Interrupt(context.TODO())
+	wg.Wait()
+
+	// expect to be rejected after interrupt
+	writer := httptest.NewRecorder()
+	handler(writer, httptest.NewRequest(http.MethodGet, "/static/openapi.json", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, writer.Code)
+}
+
+func TestRegisterStaticFileHandlerEntry_WithInvalidMode(t *testing.T) {
+	defer assertPanic(t)
+
+	RegisterStaticFileHandlerEntry(&BootStaticFileHandler{
+		Enabled: true,
+		Mode:    "invalid",
+	})
+}
@@ -3,9 +3,12 @@ package rkentry
 import (
 	"bytes"
 	"embed"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/go-playground/validator/v10"
 	"github.com/mitchellh/mapstructure"
+	"github.com/rookie-ninja/rk-entry/v2/middleware"
 	"github.com/spf13/pflag"
 	"go.uber.org/zap"
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -13,6 +16,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
@@ -21,17 +25,138 @@ import (
 var (
 	envLogOnce  sync.Once
 	flagLogOnce sync.Once
+
+	bootValidator     *validator.Validate
+	bootValidatorOnce sync.Once
+
+	domainEnvKey     = "DOMAIN"
+	domainOverride   = ""
+	activeDomainLock sync.RWMutex
+
+	domainSectionMode   = false
+	domainSectionWarned sync.Map
+
+	entryNameVars     = make(map[string]string)
+	entryNameVarsLock sync.RWMutex
+	entryNameVarRegex = regexp.MustCompile(`\{([a-zA-Z0-9_]+)\}`)
 )
 
+// DefaultDomain is name of section in boot config which sections of other domains are merged over
+const DefaultDomain = "default"
+
+// FieldError describes a boot config field which violates validation rule.
+type FieldError struct {
+	// Namespace is path of field with yaml names, like event.loki.maxBatchSize
+	Namespace string `json:"namespace" yaml:"namespace"`
+	// Field is yaml name of field
+	Field string `json:"field" yaml:"field"`
+	// Tag is the violated rule, like required, min, max or oneof
+	Tag string `json:"tag" yaml:"tag"`
+	// Param is parameter of rule
+	Param string `json:"param" yaml:"param"`
+	// Value is the actual value of field
+	Value interface{} `json:"value" yaml:"value"`
+}
+
+// Error returns message of FieldError
+func (e FieldError) Error() string {
+	if len(e.Param) > 0 {
+		return fmt.Sprintf("field:%s violates %s=%s, value:%v", e.Namespace, e.Tag, e.Param, e.Value)
+	}
+
+	return fmt.Sprintf("field:%s violates %s, value:%v", e.Namespace, e.Tag, e.Value)
+}
+
+// ValidateStruct validates struct with go-playground/validator tags, returns all violations.
+//
+// Field names in FieldError are taken from yaml tag.
+// Besides builtin rules, oneofci is supported which works like oneof but ignores case.
+func ValidateStruct(v interface{}) []FieldError {
+	res := make([]FieldError, 0)
+
+	err := getBootValidator().Struct(v)
+	if err == nil {
+		return res
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		res = append(res, FieldError{
+			Tag:   "invalid",
+			Value: err.Error(),
+		})
+		return res
+	}
+
+	for i := range validationErrs {
+		fe := validationErrs[i]
+		res = append(res, FieldError{
+			Namespace: fe.Namespace(),
+			Field:     fe.Field(),
+			Tag:       fe.Tag(),
+			Param:     fe.Param(),
+			Value:     fe.Value(),
+		})
+	}
+
+	return res
+}
+
+// validateBootConfig validates boot config and shutdown if there is any violation
+func validateBootConfig(v interface{}) {
+	errs := ValidateStruct(v)
+	if len(errs) < 1 {
+		return
+	}
+
+	msgs := make([]string, 0, len(errs))
+	for i := range errs {
+		msgs = append(msgs, errs[i].Error())
+	}
+
+	ShutdownWithError(fmt.Errorf("invalid boot config, [%s]", strings.Join(msgs, "; ")))
+}
+
+// getBootValidator returns validator which uses yaml tag as field name
+func getBootValidator() *validator.Validate {
+	bootValidatorOnce.Do(func() {
+		bootValidator = validator.New()
+
+		bootValidator.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("yaml"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			if len(name) < 1 {
+				return field.Name
+			}
+			return name
+		})
+
+		bootValidator.RegisterValidation("oneofci", func(fl validator.FieldLevel) bool {
+			for _, e := range strings.Fields(fl.Param()) {
+				if strings.EqualFold(e, fl.Field().String()) {
+					return true
+				}
+			}
+			return false
+		})
+	})
+
+	return bootValidator
+}
+
 // UnmarshalBootYAML this function will parse boot config file with ENV and pflag overrides.
 //
 // User who want to implement his/her own entry, may use this function to parse YAML config into struct.
 // This function would also parse --rkset flags.
 //
 // This function would do the following:
-// 1: Read config file and unmarshal content into a map.
+// 1: Read config file and unmarshal content into a map, flatten domain sections if enabled by SetDomainSectionMode.
 // 2: Read --rkset flags and override values in map unmarshalled at above step.
-// 3: Unmarshal map into user provided struct.
+// 3: Resolve placeholders like {shard} in entry names, refer to ResolveEntryName.
+// 4: Evaluate enabledIf conditions, elements of list are removed and sections of map are disabled if false.
+// 5: Unmarshal map into user provided struct.
 //
 // [Flag]: Override boot config value with flag of rkset:
 //
@@ -79,16 +204,64 @@ var (
 // Important! Please make sure the type of value keeps the same, otherwise, it won't override.
 // For example, os.Setenv("RK_GIN_0_PORT", "invalid-port") won't success, but keep original value.
 func UnmarshalBootYAML(raw []byte, config interface{}) {
+	originalBootM, err := parseBootYAML(raw)
+	if err != nil {
+		ShutdownWithError(err)
+	}
+
+	// 7: unmarshal to struct
+	if err := mapstructure.Decode(originalBootM, config); err != nil {
+		ShutdownWithError(err)
+	}
+}
+
+// UnmarshalBootConfigStrict unmarshal raw YAML or JSON boot config into config like UnmarshalBootYAML,
+// returns error instead of panic.
+//
+// Keys which don't exist in config struct are rejected, the error contains offending keys with
+// path of their parent fields like 'Logger[0]' has invalid keys: levle. Keys are lower-cased in error message.
+func UnmarshalBootConfigStrict(raw []byte, config interface{}) error {
+	originalBootM, err := parseBootYAML(raw)
+	if err != nil {
+		return err
+	}
+
+	// enabledIf is consumed already
+	removeEnabledIfKeys(originalBootM)
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		ErrorUnused: true,
+		Result:      config,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := decoder.Decode(originalBootM); err != nil {
+		return fmt.Errorf("failed to unmarshal boot config strictly, %v", err)
+	}
+
+	return nil
+}
+
+// parseBootYAML unmarshal raw boot config into map, and apply domain sections, overrides,
+// entry name placeholders and enabledIf.
+func parseBootYAML(raw []byte) (map[interface{}]interface{}, error) {
 	// 1: unmarshal original
 	originalBootM := map[interface{}]interface{}{}
 	// unmarshal with yaml
 	if err := yaml.Unmarshal(raw, &originalBootM); err != nil {
-		ShutdownWithError(err)
+		return nil, err
 	}
 
 	// lower key
 	originalBootM = lowerKeyMap(originalBootM)
 
+	// flatten sections of domain
+	if domainSectionMode {
+		originalBootM = flattenDomainSections(originalBootM)
+	}
+
 	// 2: get ENV overrides
 	// ignoring error, output to stdout already
 	envOverridesBootM, _ := parseEnvOverrides("RK")
@@ -103,11 +276,17 @@ func UnmarshalBootYAML(raw []byte, config interface{}) {
 	overrideMap(originalBootM, envOverridesBootM)
 	overrideMap(originalBootM, flagOverridesBootM)
 
-	// 5: unmarshal to struct
-	if err := mapstructure.Decode(originalBootM, config); err != nil {
-		ShutdownWithError(err)
+	// 5: resolve placeholders in entry names
+	if err := resolveEntryNamesInMap(originalBootM); err != nil {
+		return nil, err
 	}
 
+	// 6: evaluate enabledIf conditions
+	if _, err := applyEnabledIf(originalBootM); err != nil {
+		return nil, err
+	}
+
+	return originalBootM, nil
 }
 
 // ShutdownWithError shuts down and panic.
@@ -118,21 +297,270 @@ func ShutdownWithError(err error) {
 	panic(err)
 }
 
+// GetActiveDomain returns domain of current process.
+//
+// Domain is read from environment variable DOMAIN unless overridden by SetActiveDomain,
+// empty string will be returned if neither is set.
+// Use SetDomainEnvKey to read from another environment variable.
+func GetActiveDomain() string {
+	activeDomainLock.RLock()
+	defer activeDomainLock.RUnlock()
+
+	if len(domainOverride) > 0 {
+		return domainOverride
+	}
+
+	return os.Getenv(domainEnvKey)
+}
+
+// SetActiveDomain override domain, mainly used in tests.
+// Pass empty string to clear override, domain will be read from environment variable again.
+//
+// Domain attached by middlewares is resolved once at startup, it is not changed by SetActiveDomain.
+func SetActiveDomain(domain string) {
+	activeDomainLock.Lock()
+	defer activeDomainLock.Unlock()
+
+	domainOverride = domain
+}
+
+// SetDomainEnvKey set environment variable name of domain.
+func SetDomainEnvKey(key string) {
+	if len(key) < 1 {
+		return
+	}
+
+	activeDomainLock.Lock()
+	defer activeDomainLock.Unlock()
+
+	domainEnvKey = key
+}
+
+// syncMiddlewareDomain set domain field attached by middlewares to GetActiveDomain(),
+// it is called in init() only, before any middleware reads it.
+func syncMiddlewareDomain() {
+	rkmid.Domain = zap.String(rkmid.Domain.Key, getDefaultIfEmptyString(GetActiveDomain(), "*"))
+}
+
 // IsValidDomain mainly used in entry config.
 func IsValidDomain(domain string) bool {
 	if len(domain) < 1 {
 		domain = "*"
 	}
 
-	domainFromEnv := getDefaultIfEmptyString(os.Getenv("DOMAIN"), "")
-
-	if domain != "*" && domain != domainFromEnv {
+	if domain != "*" && domain != GetActiveDomain() {
 		return false
 	}
 
 	return true
 }
 
+// SetDomainSectionMode enable or disable domain section mode of boot config.
+//
+// In domain section mode, top-level keys of boot config are domains, section matching GetActiveDomain()
+// is merged over section of default. Maps are merged recursively, other values including lists are replaced.
+//
+//	---
+//	default:
+//	  logger:
+//	    - name: my-logger
+//	prod:
+//	  logger:
+//	    - name: my-logger
+//	      zap:
+//	        level: warn
+//
+// Section of default will be used with a warning if section of active domain is missing.
+func SetDomainSectionMode(enabled bool) {
+	domainSectionMode = enabled
+}
+
+// flattenDomainSections merge section of active domain over section of default.
+// Keys are expected to be lower-cased already.
+func flattenDomainSections(in map[interface{}]interface{}) map[interface{}]interface{} {
+	res, _ := in[DefaultDomain].(map[interface{}]interface{})
+	if res == nil {
+		res = map[interface{}]interface{}{}
+	}
+
+	domain := strings.ToLower(GetActiveDomain())
+	if len(domain) < 1 || domain == DefaultDomain {
+		return res
+	}
+
+	section, ok := in[domain].(map[interface{}]interface{})
+	if !ok {
+		if _, warned := domainSectionWarned.LoadOrStore(domain, true); !warned {
+			LoggerEntryStdout.Warn("Section of domain is missing in boot config, fallback to default",
+				zap.String("domain", domain))
+		}
+		return res
+	}
+
+	mergeDomainSection(res, section)
+	return res
+}
+
+// mergeDomainSection merge src into dst, maps are merged recursively and other values are replaced
+func mergeDomainSection(dst, src map[interface{}]interface{}) {
+	for k, v := range src {
+		srcM, srcOk := v.(map[interface{}]interface{})
+		dstM, dstOk := dst[k].(map[interface{}]interface{})
+		if srcOk && dstOk {
+			mergeDomainSection(dstM, srcM)
+			continue
+		}
+
+		dst[k] = v
+	}
+}
+
+// SetEntryNameVar set value of placeholder used in entry name template, like {shard}.
+func SetEntryNameVar(key, value string) {
+	entryNameVarsLock.Lock()
+	defer entryNameVarsLock.Unlock()
+
+	entryNameVars[key] = value
+}
+
+// ResolveEntryName replace placeholders like {shard} in entry name template.
+//
+// Value of placeholder is read from SetEntryNameVar first, then environment variable with upper-cased key,
+// SHARD for {shard} as example. An error will be returned if any placeholder can not be resolved.
+func ResolveEntryName(name string) (string, error) {
+	var missing []string
+
+	res := entryNameVarRegex.ReplaceAllStringFunc(name, func(s string) string {
+		key := s[1 : len(s)-1]
+
+		entryNameVarsLock.RLock()
+		value, ok := entryNameVars[key]
+		entryNameVarsLock.RUnlock()
+		if ok {
+			return value
+		}
+
+		if value, ok := os.LookupEnv(strings.ToUpper(key)); ok {
+			return value
+		}
+
+		missing = append(missing, key)
+		return s
+	})
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("failed to resolve entry name, name:%s, missing:%v", name, missing)
+	}
+
+	return res, nil
+}
+
+// resolveEntryNamesInMap resolve entry name templates in boot config recursively.
+// Values with key of name or keys ends with entry, like loggerEntry, will be resolved.
+// Keys are expected to be lower-cased already.
+func resolveEntryNamesInMap(in interface{}) error {
+	switch v := in.(type) {
+	case map[interface{}]interface{}:
+		for k, e := range v {
+			key, _ := k.(string)
+			if str, ok := e.(string); ok && (key == "name" || strings.HasSuffix(key, "entry")) {
+				resolved, err := ResolveEntryName(str)
+				if err != nil {
+					return err
+				}
+				v[k] = resolved
+				continue
+			}
+
+			if err := resolveEntryNamesInMap(e); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			if err := resolveEntryNamesInMap(v[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// applyEnabledIf evaluates enabledIf in boot config recursively and returns filtered config.
+// Keys are expected to be lower-cased already.
+func applyEnabledIf(in interface{}) (interface{}, error) {
+	switch v := in.(type) {
+	case map[interface{}]interface{}:
+		for k, e := range v {
+			enabled, err := evalEnabledIfOfElement(e)
+			if err != nil {
+				return nil, err
+			}
+
+			if !enabled {
+				e.(map[interface{}]interface{})["enabled"] = false
+				continue
+			}
+
+			if v[k], err = applyEnabledIf(e); err != nil {
+				return nil, err
+			}
+		}
+	case []interface{}:
+		res := make([]interface{}, 0, len(v))
+		for i := range v {
+			enabled, err := evalEnabledIfOfElement(v[i])
+			if err != nil {
+				return nil, err
+			}
+
+			if !enabled {
+				continue
+			}
+
+			e, err := applyEnabledIf(v[i])
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, e)
+		}
+		return res, nil
+	}
+
+	return in, nil
+}
+
+// removeEnabledIfKeys removes enabledIf keys in boot config recursively
+func removeEnabledIfKeys(in interface{}) {
+	switch v := in.(type) {
+	case map[interface{}]interface{}:
+		delete(v, "enabledif")
+		for _, e := range v {
+			removeEnabledIfKeys(e)
+		}
+	case []interface{}:
+		for i := range v {
+			removeEnabledIfKeys(v[i])
+		}
+	}
+}
+
+// evalEnabledIfOfElement returns false if element is a map with enabledIf evaluated to false
+func evalEnabledIfOfElement(in interface{}) (bool, error) {
+	m, ok := in.(map[interface{}]interface{})
+	if !ok {
+		return true, nil
+	}
+
+	expr, ok := m["enabledif"].(string)
+	if !ok || len(strings.TrimSpace(expr)) < 1 {
+		return true, nil
+	}
+
+	return EvalEnabledIf(expr)
+}
+
 // readFile wil read try to read file with bellow sequence.
 //
 // 1: Read from embed.FS if not nil
@@ -313,6 +741,11 @@ func parseEnvOverrides(prefix string) (map[interface{}]interface{}, error) {
 			continue
 		}
 
+		// levels of LoggerEntry share namespace of RK, they are not part of boot config
+		if strings.HasPrefix(val, LoggerLevelEnvPrefix) {
+			continue
+		}
+
 		tokens := strings.SplitN(val, "=", 2)
 		if len(tokens) != 2 {
 			continue
@@ -533,6 +966,14 @@ func fileExists(filePath string) bool {
 	return true
 }
 
+// dirExists checks directory existence, symlink of directory is followed.
+func dirExists(dirPath string) bool {
+	if file, err := os.Stat(dirPath); err == nil {
+		return file.IsDir()
+	}
+	return false
+}
+
 // slashPath add prefix and suffix with / if missing
 func slashPath(in string) string {
 	if !strings.HasPrefix(in, "/") {
@@ -545,3 +986,226 @@ func slashPath(in string) string {
 
 	return in
 }
+
+// readBootConfigYAML reads YAML file into map.
+func readBootConfigYAML(filePath string) (map[interface{}]interface{}, error) {
+	raw, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	res := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal(raw, &res); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal boot config, path:%s, %v", filePath, err)
+	}
+
+	return res, nil
+}
+
+// readBootConfigWithImports reads YAML or JSON boot config file and resolves imports.
+//
+// Files listed in top level imports are resolved relative to the importing file and merged
+// before the importing file, each file is imported at most once.
+// An error will be returned if there is an import cycle.
+func readBootConfigWithImports(filePath string, visited map[string]bool, stack []string) (map[interface{}]interface{}, error) {
+	filePath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range stack {
+		if stack[i] == filePath {
+			return nil, fmt.Errorf("import cycle detected, %s", strings.Join(append(stack[i:], filePath), " -> "))
+		}
+	}
+
+	var bootM map[interface{}]interface{}
+	if strings.ToLower(filepath.Ext(filePath)) == ".json" {
+		bootM, err = readBootConfigJSON(filePath)
+	} else {
+		bootM, err = readBootConfigYAML(filePath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	visited[filePath] = true
+	stack = append(stack, filePath)
+
+	imports, _ := bootM["imports"].([]interface{})
+	delete(bootM, "imports")
+
+	merged := map[interface{}]interface{}{}
+	owners := map[string]string{}
+	for i := range imports {
+		importPath, ok := imports[i].(string)
+		if !ok || len(importPath) < 1 {
+			return nil, fmt.Errorf("invalid import:%v in %s", imports[i], filePath)
+		}
+
+		if !filepath.IsAbs(importPath) {
+			importPath = filepath.Join(filepath.Dir(filePath), importPath)
+		}
+
+		// cycle should be detected before skipping visited files
+		inStack := false
+		for j := range stack {
+			inStack = inStack || stack[j] == importPath
+		}
+		if visited[importPath] && !inStack {
+			continue
+		}
+
+		importM, err := readBootConfigWithImports(importPath, visited, stack)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := mergeBootConfig(merged, importM, importPath, owners); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mergeBootConfig(merged, bootM, filePath, owners); err != nil {
+		return nil, err
+	}
+
+	return merged, nil
+}
+
+// ReadBootConfigFile reads YAML or JSON boot config file, resolves imports and returns YAML content.
+//
+// Example:
+//
+//	---
+//	imports:
+//	  - shared/logger.yaml   # relative to this file
+//	logger:
+//	  - name: my-logger
+func ReadBootConfigFile(filePath string) ([]byte, error) {
+	bootM, err := readBootConfigWithImports(filePath, map[string]bool{}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(bootM)
+}
+
+// readBootConfigJSON reads JSON file into map with the same key types as yaml.Unmarshal.
+func readBootConfigJSON(filePath string) (map[interface{}]interface{}, error) {
+	raw, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal boot config, path:%s, %v", filePath, err)
+	}
+
+	return jsonToYAMLValue(m).(map[interface{}]interface{}), nil
+}
+
+// jsonToYAMLValue converts map[string]interface{} produced by encoding/json into map[interface{}]interface{}.
+func jsonToYAMLValue(in interface{}) interface{} {
+	switch v := in.(type) {
+	case map[string]interface{}:
+		res := map[interface{}]interface{}{}
+		for k := range v {
+			res[k] = jsonToYAMLValue(v[k])
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, 0, len(v))
+		for i := range v {
+			res = append(res, jsonToYAMLValue(v[i]))
+		}
+		return res
+	default:
+		return in
+	}
+}
+
+// mergeBootConfig merges src boot config into dst.
+//
+// Lists of entries are appended, an error will be returned if an entry with the same name and domain
+// was already defined in another file. owners records the file which defined each entry.
+// Other values are overridden by src.
+func mergeBootConfig(dst, src map[interface{}]interface{}, filePath string, owners map[string]string) error {
+	for k, srcItem := range src {
+		srcList, ok := srcItem.([]interface{})
+		if !ok {
+			dstMap, dstOk := dst[k].(map[interface{}]interface{})
+			srcMap, srcOk := srcItem.(map[interface{}]interface{})
+			if dstOk && srcOk {
+				overrideMap(dstMap, srcMap)
+			} else {
+				dst[k] = srcItem
+			}
+			continue
+		}
+
+		for i := range srcList {
+			element, ok := srcList[i].(map[interface{}]interface{})
+			if !ok {
+				continue
+			}
+
+			name, _ := element["name"].(string)
+			if len(name) < 1 {
+				continue
+			}
+			domain, _ := element["domain"].(string)
+
+			key := fmt.Sprintf("%v/%s/%s", k, name, getDefaultIfEmptyString(domain, "*"))
+			if owner, ok := owners[key]; ok && owner != filePath {
+				return fmt.Errorf("duplicate entry name:%s in section:%v, defined in %s and %s", name, k, owner, filePath)
+			}
+			owners[key] = filePath
+		}
+
+		dstList, _ := dst[k].([]interface{})
+		dst[k] = append(dstList, srcList...)
+	}
+
+	return nil
+}
+
+// sensitive key words, value of map key contains any of them will be redacted
+var redactKeywords = []string{"password", "secret", "token", "credential", "privatekey", "apikey"}
+
+// redactSecrets replace value of sensitive keys with ****** recursively
+func redactSecrets(in interface{}) interface{} {
+	switch v := in.(type) {
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			if isSensitiveKey(k) {
+				res[k] = "******"
+				continue
+			}
+			res[k] = redactSecrets(e)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i := range v {
+			res[i] = redactSecrets(v[i])
+		}
+		return res
+	default:
+		return in
+	}
+}
+
+// isSensitiveKey checks whether key may contain secrets
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for i := range redactKeywords {
+		if strings.Contains(key, redactKeywords[i]) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -6,6 +6,7 @@ import (
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"gopkg.in/natefinch/lumberjack.v2"
+	"gopkg.in/yaml.v2"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -492,6 +493,196 @@ func TestIsValidDomain(t *testing.T) {
 	assert.Nil(t, os.Setenv("DOMAIN", ""))
 }
 
+func TestGetActiveDomain(t *testing.T) {
+	defer SetActiveDomain("")
+	defer SetDomainEnvKey("DOMAIN")
+
+	// without env
+	SetActiveDomain("")
+	t.Setenv("DOMAIN", "")
+	assert.Empty(t, GetActiveDomain())
+
+	// with env
+	t.Setenv("DOMAIN", "ut-domain")
+	assert.Equal(t, "ut-domain", GetActiveDomain())
+
+	// override
+	SetActiveDomain("ut-override")
+	assert.Equal(t, "ut-override", GetActiveDomain())
+	assert.True(t, IsValidDomain("ut-override"))
+	assert.False(t, IsValidDomain("ut-domain"))
+
+	// clear override
+	SetActiveDomain("")
+	assert.Equal(t, "ut-domain", GetActiveDomain())
+
+	// with custom env key
+	t.Setenv("UT_DOMAIN", "ut-custom")
+	SetDomainEnvKey("UT_DOMAIN")
+	assert.Equal(t, "ut-custom", GetActiveDomain())
+}
+
+func TestResolveEntryName(t *testing.T) {
+	defer delete(entryNameVars, "utShard")
+
+	// without placeholder
+	name, err := ResolveEntryName("ut-name")
+	assert.Nil(t, err)
+	assert.Equal(t, "ut-name", name)
+
+	// missing
+	_, err = ResolveEntryName("ut-name-{utShard}")
+	assert.NotNil(t, err)
+
+	// from env
+	t.Setenv("UTSHARD", "env")
+	name, err = ResolveEntryName("ut-name-{utShard}")
+	assert.Nil(t, err)
+	assert.Equal(t, "ut-name-env", name)
+
+	// from var
+	SetEntryNameVar("utShard", "1")
+	name, err = ResolveEntryName("ut-name-{utShard}")
+	assert.Nil(t, err)
+	assert.Equal(t, "ut-name-1", name)
+}
+
+func TestUnmarshalBootYAML_WithEntryNameTemplate(t *testing.T) {
+	defer delete(entryNameVars, "utShard")
+	SetEntryNameVar("utShard", "1")
+
+	bootStr := `
+logger:
+  - name: ut-logger-{utShard}
+    description: ut-{utShard}
+`
+	bootLogger := &BootLogger{}
+	UnmarshalBootYAML([]byte(bootStr), bootLogger)
+	assert.Equal(t, "ut-logger-1", bootLogger.Logger[0].Name)
+	// only names are resolved
+	assert.Equal(t, "ut-{utShard}", bootLogger.Logger[0].Description)
+
+	// unresolved placeholder
+	defer assertPanic(t)
+	UnmarshalBootYAML([]byte("logger:\n  - name: ut-logger-{utMissing}"), bootLogger)
+}
+
+func TestUnmarshalBootYAML_WithEnabledIf(t *testing.T) {
+	defer SetActiveDomain("")
+	SetActiveDomain("prod")
+
+	bootStr := `
+logger:
+  - name: ut-logger-prod
+    enabledIf: ${DOMAIN} == prod
+  - name: ut-logger-test
+    enabledIf: ${DOMAIN} == test
+  - name: ut-logger
+`
+	bootLogger := &BootLogger{}
+	UnmarshalBootYAML([]byte(bootStr), bootLogger)
+	assert.Len(t, bootLogger.Logger, 2)
+	assert.Equal(t, "ut-logger-prod", bootLogger.Logger[0].Name)
+	assert.Equal(t, "ut-logger", bootLogger.Logger[1].Name)
+
+	// section of map
+	bootStr = `
+prom:
+  enabled: true
+  enabledIf: ${DOMAIN} == test
+`
+	bootProm := &struct {
+		Prom BootProm `yaml:"prom"`
+	}{}
+	UnmarshalBootYAML([]byte(bootStr), bootProm)
+	assert.False(t, bootProm.Prom.Enabled)
+
+	// invalid expression
+	defer assertPanic(t)
+	UnmarshalBootYAML([]byte("logger:\n  - name: ut-logger\n    enabledIf: (${DOMAIN} == prod"), bootLogger)
+}
+
+func TestUnmarshalBootConfigStrict(t *testing.T) {
+	// happy case
+	bootStr := `
+logger:
+  - name: ut-logger
+    description: ut-description
+    enabledIf: "true"
+`
+	bootLogger := &BootLogger{}
+	assert.Nil(t, UnmarshalBootConfigStrict([]byte(bootStr), bootLogger))
+	assert.Equal(t, "ut-logger", bootLogger.Logger[0].Name)
+
+	// json
+	bootLogger = &BootLogger{}
+	assert.Nil(t, UnmarshalBootConfigStrict([]byte(`{"logger": [{"name": "ut-logger"}]}`), bootLogger))
+	assert.Equal(t, "ut-logger", bootLogger.Logger[0].Name)
+
+	// unknown key
+	bootStr = `
+logger:
+  - name: ut-logger
+    descripton: ut-description
+`
+	err := UnmarshalBootConfigStrict([]byte(bootStr), &BootLogger{})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Logger[0]")
+	assert.Contains(t, err.Error(), "descripton")
+
+	// invalid yaml
+	assert.NotNil(t, UnmarshalBootConfigStrict([]byte("logger: ["), &BootLogger{}))
+}
+
+func TestUnmarshalBootYAML_WithDomainSection(t *testing.T) {
+	defer SetActiveDomain("")
+	defer SetDomainSectionMode(false)
+	SetDomainSectionMode(true)
+
+	bootStr := `
+default:
+  prom:
+    enabled: true
+    path: /default
+    pusher:
+      jobName: default
+  logger:
+    - name: ut-logger
+prod:
+  prom:
+    path: /prod
+  logger:
+    - name: ut-logger-prod
+`
+	type bootT struct {
+		Prom   BootProm       `yaml:"prom"`
+		Logger []*BootLoggerE `yaml:"logger"`
+	}
+
+	// default
+	SetActiveDomain(DefaultDomain)
+	boot := &bootT{}
+	UnmarshalBootYAML([]byte(bootStr), boot)
+	assert.Equal(t, "/default", boot.Prom.Path)
+	assert.Equal(t, "ut-logger", boot.Logger[0].Name)
+
+	// prod merged over default
+	SetActiveDomain("prod")
+	boot = &bootT{}
+	UnmarshalBootYAML([]byte(bootStr), boot)
+	assert.True(t, boot.Prom.Enabled)
+	assert.Equal(t, "/prod", boot.Prom.Path)
+	assert.Equal(t, "default", boot.Prom.Pusher.JobName)
+	assert.Len(t, boot.Logger, 1)
+	assert.Equal(t, "ut-logger-prod", boot.Logger[0].Name)
+
+	// unknown domain
+	SetActiveDomain("ut-unknown")
+	boot = &bootT{}
+	UnmarshalBootYAML([]byte(bootStr), boot)
+	assert.Equal(t, "/default", boot.Prom.Path)
+}
+
 func TestShutdownWithError_WithNilError(t *testing.T) {
 	defer assertPanic(t)
 	ShutdownWithError(nil)
@@ -574,3 +765,113 @@ func isLower(s string) bool {
 	}
 	return true
 }
+
+func TestValidateStruct(t *testing.T) {
+	// happy case
+	assert.Empty(t, ValidateStruct(&BootEventE{
+		Name:     "ut-event",
+		Encoding: "JSON",
+	}))
+
+	// with violations
+	errs := ValidateStruct(&BootEventE{
+		Name:     "ut-event",
+		Encoding: "xml",
+		Loki: BootLoki{
+			MaxBatchSize: -1,
+		},
+	})
+	assert.Len(t, errs, 2)
+	assert.Equal(t, "BootEventE.encoding", errs[0].Namespace)
+	assert.Equal(t, "oneofci", errs[0].Tag)
+	assert.Equal(t, "xml", errs[0].Value)
+	assert.Equal(t, "BootEventE.loki.maxBatchSize", errs[1].Namespace)
+	assert.Equal(t, "min", errs[1].Tag)
+	assert.Equal(t, "0", errs[1].Param)
+	assert.Contains(t, errs[1].Error(), "maxBatchSize violates min=0")
+
+	// with non struct
+	errs = ValidateStruct("ut-string")
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "invalid", errs[0].Tag)
+}
+
+func TestRedactSecrets(t *testing.T) {
+	in := map[string]interface{}{
+		"name": "ut-name",
+		"loki": map[string]interface{}{
+			"password": "ut-password",
+			"apiKey":   "ut-key",
+		},
+		"list": []interface{}{
+			map[string]interface{}{
+				"clientSecret": "ut-secret",
+			},
+		},
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"name": "ut-name",
+		"loki": map[string]interface{}{
+			"password": "******",
+			"apiKey":   "******",
+		},
+		"list": []interface{}{
+			map[string]interface{}{
+				"clientSecret": "******",
+			},
+		},
+	}, redactSecrets(in))
+}
+
+func TestReadBootConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "shared"), os.ModePerm))
+
+	// shared fragments, common.yaml is imported twice
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "shared", "common.yaml"), []byte(`
+app:
+  name: ut-app
+`), os.ModePerm))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "shared", "logger.yaml"), []byte(`
+imports:
+  - common.yaml
+logger:
+  - name: ut-shared-logger
+`), os.ModePerm))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "shared", "event.json"), []byte(`{
+  "imports": ["common.yaml"],
+  "event": [{"name": "ut-shared-event"}]
+}`), os.ModePerm))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "boot.yaml"), []byte(`
+imports:
+  - shared/logger.yaml
+  - shared/event.json
+app:
+  version: v1
+logger:
+  - name: ut-logger
+`), os.ModePerm))
+
+	raw, err := ReadBootConfigFile(filepath.Join(dir, "boot.yaml"))
+	assert.Nil(t, err)
+
+	boot := map[string]interface{}{}
+	assert.Nil(t, yaml.Unmarshal(raw, &boot))
+	assert.NotContains(t, boot, "imports")
+	assert.Equal(t, map[interface{}]interface{}{"name": "ut-app", "version": "v1"}, boot["app"])
+	assert.Len(t, boot["logger"], 2)
+	assert.Len(t, boot["event"], 1)
+
+	// with cycle
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "a.yaml"), []byte("imports: [b.yaml]"), os.ModePerm))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "b.yaml"), []byte("imports: [a.yaml]"), os.ModePerm))
+	_, err = ReadBootConfigFile(filepath.Join(dir, "a.yaml"))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "import cycle detected")
+
+	// with missing import
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "c.yaml"), []byte("imports: [missing.yaml]"), os.ModePerm))
+	_, err = ReadBootConfigFile(filepath.Join(dir, "c.yaml"))
+	assert.NotNil(t, err)
+}
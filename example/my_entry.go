@@ -8,7 +8,6 @@ package main
 import (
 	"context"
 	_ "embed"
-	"encoding/json"
 	"github.com/rookie-ninja/rk-entry/v2/entry"
 	_ "github.com/rookie-ninja/rk-query"
 	"os"
@@ -24,9 +23,10 @@ func main() {
 	RegisterMyEntryFromConfig(boot)
 
 	// 2: retrieve entry from global context and convert it into MyEntry
-	raw := rkentry.GlobalAppCtx.GetEntry("MyEntry", "MyEntry")
-
-	entry, _ := raw.(*MyEntry)
+	entry, err := rkentry.GetEntryAs[*MyEntry]("MyEntry")
+	if err != nil {
+		rkentry.ShutdownWithError(err)
+	}
 
 	// 3: bootstrap entry
 	entry.Bootstrap(context.Background())
@@ -71,9 +71,8 @@ func RegisterMyEntryFromConfig(raw []byte) map[string]rkentry.Entry {
 // RegisterMyEntry register entry based on code
 func RegisterMyEntry(opts ...MyEntryOption) *MyEntry {
 	entry := &MyEntry{
-		EntryName:        "MyEntry",
-		EntryType:        "MyEntry",
-		EntryDescription: "Please contact maintainers to add description of this entry.",
+		BaseEntry: rkentry.NewBaseEntry("MyEntry", "MyEntry",
+			"Please contact maintainers to add description of this entry."),
 	}
 
 	for i := range opts {
@@ -88,6 +87,13 @@ func RegisterMyEntry(opts ...MyEntryOption) *MyEntry {
 		entry.EntryDescription = "Please contact maintainers to add description of this entry."
 	}
 
+	// custom fields marshalled with name, type and description
+	entry.SetMarshalFields(func() map[string]interface{} {
+		return map[string]interface{}{
+			"key": entry.Key,
+		}
+	})
+
 	rkentry.GlobalAppCtx.AddEntry(entry)
 
 	return entry
@@ -117,55 +123,14 @@ func WithKey(key string) MyEntryOption {
 	}
 }
 
-// MyEntry is a implementation of Entry
+// MyEntry is a implementation of Entry, which embeds rkentry.BaseEntry for standard
+// methods and overrides Bootstrap only.
 type MyEntry struct {
-	EntryName        string `json:"-" yaml:"-"`
-	EntryType        string `json:"-" yaml:"-"`
-	EntryDescription string `json:"-" yaml:"-"`
-	Key              string `json:"-" yaml:"-"`
+	rkentry.BaseEntry
+	Key string `json:"-" yaml:"-"`
 }
 
 // Bootstrap init required fields in MyEntry
-func (entry *MyEntry) Bootstrap(context.Context) {}
-
-// Interrupt noop
-func (entry *MyEntry) Interrupt(context.Context) {}
-
-// GetName returns name of entry
-func (entry *MyEntry) GetName() string {
-	return entry.EntryName
-}
-
-// GetType returns type of entry
-func (entry *MyEntry) GetType() string {
-	return entry.EntryType
-}
-
-// String returns string value of entry
-func (entry *MyEntry) String() string {
-	bytes, _ := json.Marshal(entry)
-
-	return string(bytes)
-}
-
-// MarshalJSON marshal entry
-func (entry *MyEntry) MarshalJSON() ([]byte, error) {
-	m := map[string]interface{}{
-		"name":        entry.EntryName,
-		"type":        entry.EntryType,
-		"description": entry.EntryDescription,
-		"key":         entry.Key,
-	}
-
-	return json.Marshal(&m)
-}
-
-// UnmarshalJSON unmarshal entry
-func (entry *MyEntry) UnmarshalJSON([]byte) error {
-	return nil
-}
-
-// GetDescription returns description of entry
-func (entry *MyEntry) GetDescription() string {
-	return entry.EntryDescription
+func (entry *MyEntry) Bootstrap(context.Context) {
+	rkentry.GlobalAppCtx.GetLoggerEntryDefault().Info("Bootstrap MyEntry, " + entry.String())
 }
@@ -55,7 +55,7 @@ var (
 	JwtTokenKey       = &jwtTokenKey{}
 	CsrfTokenKey      = &csrfTokenKey{}
 
-	// Domain environment variable
+	// Domain of current process, set to rkentry.GetActiveDomain() at init of rkentry
 	Domain = zap.String("domain", getEnvValueOrDefault("DOMAIN", "*"))
 	// LocalIp read local IP from localhost
 	LocalIp = zap.String("localIp", getLocalIP())
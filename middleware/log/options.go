@@ -227,7 +227,7 @@ func (set *optionSet) createEvent(urlPath string, threadSafe bool) rkquery.Event
 
 	var event rkquery.Event
 	if threadSafe {
-		event = set.eventEntry.EventFactory.CreateEventThreadSafe(
+		event = set.eventEntry.CreateEventThreadSafe(
 			rkquery.WithZapLogger(set.eventLoggerOverride),
 			rkquery.WithEncoding(set.eventLoggerEncoding),
 			rkquery.WithAppName(rkentry.GlobalAppCtx.GetAppInfoEntry().AppName),
@@ -235,7 +235,7 @@ func (set *optionSet) createEvent(urlPath string, threadSafe bool) rkquery.Event
 			rkquery.WithEntryName(set.GetEntryName()),
 			rkquery.WithEntryType(set.GetEntryType()))
 	} else {
-		event = set.eventEntry.EventFactory.CreateEvent(
+		event = set.eventEntry.CreateEvent(
 			rkquery.WithZapLogger(set.eventLoggerOverride),
 			rkquery.WithEncoding(set.eventLoggerEncoding),
 			rkquery.WithAppName(rkentry.GlobalAppCtx.GetAppInfoEntry().AppName),